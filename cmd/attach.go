@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"AgentEngine/pkg/engine/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var attachFromSeqFlag int64
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <session-id>",
+	Short: "Watch a session's running turn from another terminal",
+	Long: `Watch the current turn of a session, e.g. one running in "sea chat" in another
+terminal. The turn's events so far are replayed from the event log (after --from-seq),
+then new ones are shown as they happen. Attach ends when the turn finishes or waits
+for an approval, which is given in the session's own terminal. For an idle session the
+last turn is replayed. Press Esc twice to detach; the turn keeps running.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runAttach,
+}
+
+func init() {
+	attachCmd.Flags().Int64Var(&attachFromSeqFlag, "from-seq", 0, "Replay only events after this sequence number")
+	rootCmd.AddCommand(attachCmd)
+}
+
+func runAttach(cmd *cobra.Command, args []string) {
+	workspaceRoot, err := resolveWorkspaceRoot()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	eng, err := newAPIEngine(workspaceRoot)
+	if err != nil {
+		fmt.Printf("Error initializing engine: %v\n", err)
+		return
+	}
+	rt, ok := eng.(*runtime.Engine)
+	if !ok {
+		fmt.Println("Error: attach requires the runtime engine")
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stream, err := rt.Attach(ctx, args[0], attachFromSeqFlag)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	defer stream.Close()
+
+	fmt.Printf("👀 Attached to %s\n", args[0])
+	pending, err := consumeEventStream(ctx, stream, stop, nil)
+	switch {
+	case ctx.Err() != nil:
+		fmt.Println("\nDetached.")
+	case err != nil:
+		fmt.Printf("\n❌ %v\n", err)
+	case pending != nil:
+		fmt.Printf("\n⏸️  Waiting for approval of %s in the session's terminal.\n", pending.ToolCall.ToolName)
+	}
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -31,26 +33,37 @@ func init() {
 	chatCmd.Flags().StringVar(&activeSkillFlag, "skill", "", "Set initial active skill for a new session")
 	chatCmd.Flags().StringVar(&approvalModeFlag, "approval-mode", "", "suggest | auto | full-auto (default: auto)")
 	chatCmd.Flags().BoolVar(&emitThinkingFlag, "thinking", false, "Emit thinking events (UI/debug)")
+	chatCmd.Flags().BoolVar(&showReasoning, "reasoning", false, "Show model reasoning in full while it streams")
+	addHeadlessFlags(chatCmd)
 	rootCmd.AddCommand(chatCmd)
 }
 
 func runChat(cmd *cobra.Command, args []string) {
+	headless, err := headlessMode()
+	if err == nil && headless && approvalsFromFlag == "-" {
+		err = fmt.Errorf("chat reads messages from stdin; pass approvals with --approvals-from <file> or fd:N")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
 	workspaceRoot, err := resolveWorkspaceRoot()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		commandError(headless, "Error: %v", err)
 		return
 	}
 
 	eng, err := newAPIEngine(workspaceRoot)
 	if err != nil {
-		fmt.Printf("Error initializing engine: %v\n", err)
+		commandError(headless, "Error initializing engine: %v", err)
 		return
 	}
 
 	ctx := context.Background()
 
 	if listSessionsFlag {
-		listSessions(ctx, eng)
+		listSessions(ctx, eng, "Resume with: agent chat <session-id>")
 		return
 	}
 
@@ -58,6 +71,9 @@ func runChat(cmd *cobra.Command, args []string) {
 	if len(args) > 0 {
 		sessionID = args[0]
 		if _, err := eng.GetSession(ctx, sessionID); err != nil {
+			if headless {
+				commandError(headless, "Error: session '%s' not found", sessionID)
+			}
 			fmt.Printf("Session '%s' not found, creating a new session...\n", sessionID)
 			sessionID = ""
 		}
@@ -71,15 +87,20 @@ func runChat(cmd *cobra.Command, args []string) {
 		}
 		id, err := eng.StartSession(ctx, opts)
 		if err != nil {
-			fmt.Printf("Error starting session: %v\n", err)
+			commandError(headless, "Error starting session: %v", err)
 			return
 		}
 		sessionID = id
 	}
 
+	if headless {
+		// Each stdin line is a message.
+		os.Exit(runHeadless(ctx, eng, sessionID, lineMessages(os.Stdin)))
+	}
+
 	printChatBanner(sessionID)
 
-	approver := ui.NewCLIApprover()
+	approver := newApprover(eng)
 	approval := &approvalState{}
 
 	// Initialize history manager
@@ -88,6 +109,9 @@ func runChat(cmd *cobra.Command, args []string) {
 		fmt.Printf("Warning: Failed to initialize history: %v\n", err)
 	}
 
+	// Attachments queued with /attach for the next message
+	var attachments []api.ContentPart
+
 	// Load history
 	var inputHistory []string
 	if historyMgr != nil {
@@ -97,7 +121,8 @@ func runChat(cmd *cobra.Command, args []string) {
 	}
 
 	for {
-		in, err := ui.ReadInputWithHistory("\n💬 You: ", inputHistory)
+		paths := ui.NewPathIndex(workspaceRoot)
+		in, err := ui.ReadInputWithHistory("\n💬 You: ", inputHistory, paths)
 		if err != nil {
 			fmt.Printf("Input error: %v\n", err)
 			return
@@ -122,6 +147,19 @@ func runChat(cmd *cobra.Command, args []string) {
 			}
 		}
 
+		if fields := strings.Fields(text); strings.EqualFold(fields[0], "/memory") {
+			runMemorySlash(ctx, eng, workspaceRoot, sessionID, approver, fields[1:])
+			continue
+		}
+		if fields := strings.Fields(text); strings.EqualFold(fields[0], "/attach") {
+			attachments = runAttachSlash(attachments, strings.TrimSpace(text[len(fields[0]):]))
+			continue
+		}
+		if next, ok := runSessionSlash(ctx, eng, workspaceRoot, sessionID, approval, text); ok {
+			sessionID = next
+			continue
+		}
+
 		switch strings.ToLower(text) {
 		case "/quit", "/exit", "/q":
 			fmt.Println("\nGoodbye.")
@@ -130,9 +168,30 @@ func runChat(cmd *cobra.Command, args []string) {
 			fmt.Println("\nCommands:")
 			fmt.Println("  /init      Create persona templates for this project/workspace")
 			fmt.Println("  /compress  Compress conversation history (keep last 3 turns)")
+			fmt.Println("  /memory    List, search, remove or review memories (/memory help)")
+			fmt.Println("  /attach    Attach an image or file to the next message (/attach clear)")
+			fmt.Println("  /reasoning Show the model's reasoning for the last reply")
+			fmt.Println("  /skill     Show or switch the skill (/skill <name|off|auto>)")
+			fmt.Println("  /mode      Show or switch the approval mode (suggest, auto, full-auto)")
+			fmt.Println("  /model     Show or switch the model (a role, a configured model or main)")
+			fmt.Println("  /plan      Show the session's plan")
+			fmt.Println("  /usage     Show the session's token usage and cost")
+			fmt.Println("  /diff      Show the files changed this session")
+			fmt.Println("  /export    Save the transcript (/export [file.md|file.json])")
+			fmt.Println("  /sessions  List sessions")
+			fmt.Println("  /resume    Switch to another session (/resume <id>)")
+			fmt.Println("  /clear     Start a fresh session with the same settings")
+			fmt.Println("  @path      Attach a workspace file or directory listing (Tab completes)")
 			fmt.Println("  /help      Show help")
 			fmt.Println("  /quit      Exit")
 			continue
+		case "/reasoning":
+			if lastReasoning == "" {
+				fmt.Println("No reasoning for the last reply.")
+			} else {
+				fmt.Printf("\n\033[2m💭 %s\033[0m\n", strings.TrimSpace(lastReasoning))
+			}
+			continue
 		case "/init":
 			fmt.Println("\n🧩 Initializing persona templates...")
 			res, err := InitPersonaFiles(workspaceRoot, agentFlag)
@@ -171,10 +230,67 @@ func runChat(cmd *cobra.Command, args []string) {
 			continue
 		}
 
-		if err := runTurnWithApprovals(ctx, eng, sessionID, text, approver, approval); err != nil {
+		// Files mentioned with @path go along as attachments
+		if _, ok := eng.(*runtime.Engine); ok {
+			attachments = append(attachments, mentionParts(paths, text)...)
+		}
+
+		err = runTurnWithApprovals(ctx, eng, sessionID, text, approver, approval, attachments...)
+		if err != nil {
 			fmt.Printf("\n❌ Error: %v\n", err)
 		}
+		attachments = nil
+	}
+}
+
+// maxAttachmentBytes limits images attached with /attach.
+const maxAttachmentBytes = 5 * 1024 * 1024
+
+// runAttachSlash handles /attach: with a path it queues the file for the next message
+// (images inline, other files as references the agent can read); "clear" drops the
+// queue; without arguments it lists it.
+func runAttachSlash(attachments []api.ContentPart, arg string) []api.ContentPart {
+	switch strings.ToLower(arg) {
+	case "":
+		if len(attachments) == 0 {
+			fmt.Println("No attachments. Usage: /attach <path> | /attach clear")
+		}
+		for _, a := range attachments {
+			fmt.Printf("  📎 %s\n", a.Path)
+		}
+		return attachments
+	case "clear":
+		fmt.Println("Attachments cleared.")
+		return nil
+	}
+
+	path := strings.Trim(arg, `"'`)
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return attachments
+	}
+	if info.IsDir() {
+		fmt.Printf("❌ %s is a directory\n", path)
+		return attachments
+	}
+	abs, _ := filepath.Abs(path)
+
+	part := api.ContentPart{Type: api.PartFile, Path: abs}
+	if mimeType := api.ImageMIMEType(path); mimeType != "" {
+		if info.Size() > maxAttachmentBytes {
+			fmt.Printf("❌ Image is too large (%d KB, limit %d KB)\n", info.Size()/1024, maxAttachmentBytes/1024)
+			return attachments
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return attachments
+		}
+		part = api.ImagePart(mimeType, data, abs)
 	}
+	fmt.Printf("📎 Attached %s (sent with your next message)\n", path)
+	return append(attachments, part)
 }
 
 func resolveApprovalMode() api.ApprovalMode {
@@ -193,7 +309,7 @@ func resolveApprovalMode() api.ApprovalMode {
 	}
 }
 
-func listSessions(ctx context.Context, eng api.Engine) {
+func listSessions(ctx context.Context, eng api.Engine, hint string) {
 	sessions, err := eng.ListSessions(ctx)
 	if err != nil {
 		fmt.Printf("Error listing sessions: %v\n", err)
@@ -214,7 +330,7 @@ func listSessions(ctx context.Context, eng api.Engine) {
 		}
 		fmt.Printf("  %s - %d messages%s - %s\n", s.SessionID, s.MessageCount, skillInfo, s.UpdatedAt.Format("2006-01-02 15:04"))
 	}
-	fmt.Println("\nResume with: agent chat <session-id>")
+	fmt.Println("\n" + hint)
 }
 
 func printChatBanner(sessionID string) {
@@ -228,10 +344,13 @@ func printChatBanner(sessionID string) {
 	fmt.Println("║    /help      Show all commands                               ║")
 	fmt.Println("║    /compress  Compress history when context is too long       ║")
 	fmt.Println("║    /init      Create project-specific persona templates       ║")
+	fmt.Println("║    /memory    List, search or review stored memories          ║")
+	fmt.Println("║    /attach    Attach an image or file to the next message     ║")
 	fmt.Println("║    /quit      Exit session                                    ║")
 	fmt.Println("╠═══════════════════════════════════════════════════════════════╣")
 	fmt.Println("║  Tips:                                                        ║")
 	fmt.Println("║    • Ctrl+J to insert newline, Enter to send                  ║")
+	fmt.Println("║    • While it works: type + Enter to steer, Esc Esc to stop   ║")
 	fmt.Println("║    • Create persona.md for project-specific AI behavior       ║")
 	fmt.Println("║    • Use /compress if responses slow down (context too long)  ║")
 	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
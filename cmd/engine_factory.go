@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/embed"
 	"AgentEngine/pkg/engine/memory"
 	mw "AgentEngine/pkg/engine/middleware"
 	"AgentEngine/pkg/engine/policy"
@@ -14,6 +17,7 @@ import (
 	"AgentEngine/pkg/engine/store"
 	"AgentEngine/pkg/engine/systool"
 	"AgentEngine/pkg/engine/tools"
+	"AgentEngine/pkg/logger"
 )
 
 func resolveWorkspaceRoot() (string, error) {
@@ -83,6 +87,10 @@ func newAPIEngine(workspaceRoot string) (api.Engine, error) {
 	}
 
 	mem := memory.NewStructuredManager(workspaceRoot)
+	// Optional semantic memory retrieval (MEMORY_EMBEDDINGS=openai|hash); BM25 is always on.
+	if p := newEmbeddingProvider(os.Getenv("MEMORY_EMBEDDINGS")); p != nil {
+		mem.SetRetriever(&memory.Retriever{Provider: p, Cache: embed.NewCache(filepath.Join(workspaceRoot, "cache", "embeddings"))})
+	}
 
 	reg := tools.NewRegistry()
 	reg.MustRegister(&systool.ListSkillsTool{SkillIndex: skillIndex})
@@ -94,23 +102,18 @@ func newAPIEngine(workspaceRoot string) (api.Engine, error) {
 	reg.MustRegister(&systool.UpdateMemoryTool{Manager: mem})
 	reg.MustRegister(&systool.UnderstandIntentTool{})
 
+	// run_skill_script needs skill index for path resolution; it also runs skill hooks.
+	scriptTool := tools.NewRunSkillScriptTool(workspaceRoot, skillIndex)
 	if enableToolsFlag {
 		for _, t := range tools.DefaultRegistry(workspaceRoot).All() {
 			reg.MustRegister(t)
 		}
-		// run_skill_script needs skill index for path resolution.
-		reg.MustRegister(tools.NewRunSkillScriptTool(workspaceRoot, skillIndex))
+		reg.MustRegister(scriptTool)
 	}
 
-	var llm runtime.LLM = &runtime.MockLLM{}
-	if apiKey := os.Getenv("LLM_API_KEY"); apiKey != "" {
-		baseURL := os.Getenv("LLM_BASE_URL")
-		model := os.Getenv("LLM_MODEL")
-		if modelFlag != "" {
-			model = modelFlag
-		}
-		openai := runtime.NewOpenAILLM(baseURL, apiKey, model)
-		llm = openai
+	llm, model, models, err := newModels()
+	if err != nil {
+		return nil, err
 	}
 
 	// Read compression settings from environment
@@ -133,11 +136,42 @@ func newAPIEngine(workspaceRoot string) (api.Engine, error) {
 		filterHistoryTools = false
 	}
 
+	// Keep model reasoning in saved sessions (default: only during the turn).
+	keepReasoning := os.Getenv("LLM_KEEP_REASONING") == "true" || os.Getenv("LLM_KEEP_REASONING") == "1"
+
+	// Optional semantic skill routing (SKILL_ROUTER_EMBEDDINGS=openai|hash).
+	var semanticRouter *runtime.SemanticSkillRouter
+	if p := newEmbeddingProvider(os.Getenv("SKILL_ROUTER_EMBEDDINGS")); p != nil {
+		semanticRouter = runtime.NewSemanticSkillRouter(p, filepath.Join(workspaceRoot, "cache", "embeddings"))
+	}
+
+	memoryMW := mw.NewMemoryMiddleware(mem)
+	if v := os.Getenv("MEMORY_TOKEN_BUDGET"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			memoryMW.TokenBudget = n
+		}
+	}
+
+	middlewares := []runtime.Middleware{mw.NewPersonaMiddleware(workspaceRoot, filepath.Dir(workspaceRoot), agentFlag), mw.NewBasePromptMiddleware(workspaceRoot), mw.NewSkillsMiddleware(skillIndex), memoryMW, mw.NewPlanningMiddleware(planStore)}
+
+	// Propose memories after each turn (opt-in with MEMORY_EXTRACTION=true: it costs an
+	// extra LLM call per turn; needs a real LLM).
+	if _, isMock := llm.(*runtime.MockLLM); !isMock {
+		switch os.Getenv("MEMORY_EXTRACTION") {
+		case "true", "1", "on":
+			extractor := llm
+			if m, ok := models.Role(runtime.RoleMemoryExtractor); ok {
+				extractor = m.LLM
+			}
+			middlewares = append(middlewares, mw.NewMemoryExtractionMiddleware(runtime.NewLLMMemoryExtractor(extractor), mem))
+		}
+	}
+
 	engine, err := runtime.NewEngine(runtime.EngineConfig{
 		LLM:                   llm,
 		Tools:                 reg,
 		Policy:                policy.NewDefaultPolicy(),
-		Middlewares:           []runtime.Middleware{mw.NewPersonaMiddleware(workspaceRoot, filepath.Dir(workspaceRoot), agentFlag), mw.NewBasePromptMiddleware(workspaceRoot), mw.NewSkillsMiddleware(skillIndex), mw.NewMemoryMiddleware(mem), mw.NewPlanningMiddleware(planStore)},
+		Middlewares:           middlewares,
 		WorkspaceRoot:         workspaceRoot,
 		SkillIndex:            skillIndex,
 		SessionStore:          sessionStore,
@@ -146,9 +180,229 @@ func newAPIEngine(workspaceRoot string) (api.Engine, error) {
 		AutoCompressThreshold: autoCompressThreshold,
 		CompressKeepTurns:     compressKeepTurns,
 		FilterHistoryTools:    filterHistoryTools,
+		KeepReasoning:         keepReasoning,
+		HookRunner:            scriptTool,
+		SemanticRouter:        semanticRouter,
+		Models:                models,
+		MemoryWriter:          mem,
+		Model:                 model,
+		Prices:                loadPriceTable(),
+		Limits:                limitsFromEnv("TURN", runtime.Limits{MaxLLMCalls: 50}),
+		SessionLimits:         limitsFromEnv("SESSION", runtime.Limits{}),
+		LoopThreshold:         envInt("TOOL_LOOP_THRESHOLD", 3),
 	})
 	if err != nil {
 		return nil, err
 	}
 	return engine, nil
 }
+
+// loadPriceTable merges the JSON file named by PRICE_TABLE over the built-in prices.
+func loadPriceTable() runtime.PriceTable {
+	path := os.Getenv("PRICE_TABLE")
+	if path == "" {
+		return runtime.DefaultPriceTable()
+	}
+	prices, err := runtime.LoadPriceTable(path)
+	if err != nil {
+		logger.Warn("Engine", "Failed to load price table, using defaults", map[string]interface{}{
+			"path":  path,
+			"error": err.Error(),
+		})
+		return runtime.DefaultPriceTable()
+	}
+	return prices
+}
+
+// newModels builds the main LLM and the per-role models. MODELS_CONFIG points to a
+// models config file (see runtime.ModelsConfig); roles it leaves out use the main model.
+// Without a config file the main model comes from LLM_* and, without LLM_API_KEY (or a
+// local LLM_PROVIDER), is the mock.
+func newModels() (runtime.LLM, string, *runtime.ModelSet, error) {
+	var cfg runtime.ModelsConfig
+	if path := os.Getenv("MODELS_CONFIG"); path != "" {
+		loaded, err := runtime.LoadModelsConfig(path)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		cfg = *loaded
+	} else if p := os.Getenv("LLM_PROVIDER"); os.Getenv("LLM_API_KEY") == "" && p != runtime.ProviderOllama && p != runtime.ProviderLlamaCpp {
+		return &runtime.MockLLM{}, "", nil, nil
+	}
+
+	main, ok := cfg.Roles[runtime.RoleMain]
+	if !ok {
+		main = runtime.ModelSpec{Model: os.Getenv("LLM_MODEL")}
+		if t, err := strconv.ParseFloat(os.Getenv("LLM_TEMPERATURE"), 64); err == nil {
+			main.Temperature = &t
+		}
+	}
+	if modelFlag != "" {
+		main.Model = modelFlag
+	}
+	llm, model := buildModel(&cfg, main, true)
+
+	roles := make(map[string]runtime.RoleModel)
+	for role, spec := range cfg.Roles {
+		if role == runtime.RoleMain {
+			continue
+		}
+		roleLLM, roleModel := buildModel(&cfg, spec, false)
+		roles[role] = runtime.RoleModel{LLM: roleLLM, Model: roleModel}
+	}
+	// Skills may ask for a model by name; it shares the main model's provider and sampling.
+	build := func(name string) runtime.LLM {
+		spec := main
+		spec.Model = name
+		skillLLM, _ := buildModel(&cfg, spec, false)
+		return skillLLM
+	}
+	return llm, model, runtime.NewModelSet(roles, build), nil
+}
+
+// buildModel creates a client for spec wrapped with retries; the main model also gets
+// the LLM_FALLBACK_* providers. It returns the client and its model name.
+func buildModel(cfg *runtime.ModelsConfig, spec runtime.ModelSpec, withFallbacks bool) (runtime.LLM, string) {
+	provider := runtime.ProviderSpec{
+		Type:        os.Getenv("LLM_PROVIDER"),
+		BaseURL:     os.Getenv("LLM_BASE_URL"),
+		PromptTools: os.Getenv("LLM_PROMPT_TOOLS") == "true" || os.Getenv("LLM_PROMPT_TOOLS") == "1",
+	}
+	apiKey := os.Getenv("LLM_API_KEY")
+	if p, ok := cfg.Providers[spec.Provider]; ok {
+		provider = p
+		if p.APIKeyEnv != "" {
+			apiKey = os.Getenv(p.APIKeyEnv)
+		}
+	}
+	baseURL := provider.BaseURL
+	client := newLLMClient(provider.Type, baseURL, apiKey, spec.Model)
+	if spec.Temperature != nil {
+		client.SetTemperature(*spec.Temperature)
+	}
+	if spec.TopP != nil {
+		client.SetTopP(*spec.TopP)
+	}
+	client.SetMaxTokens(spec.MaxTokens)
+
+	var llm runtime.LLM = client
+	if provider.PromptTools {
+		llm = runtime.NewPromptToolLLM(client)
+	}
+	providers := []runtime.LLMProvider{{Name: client.Model(), LLM: llm}}
+	if withFallbacks {
+		providers = append(providers, fallbacksFromEnv(baseURL, apiKey)...)
+	}
+	return runtime.NewResilientLLM(retryPolicyFromEnv(), providers...), client.Model()
+}
+
+// samplingLLM is an LLM client with configurable sampling.
+type samplingLLM interface {
+	runtime.LLM
+	Model() string
+	SetTemperature(t float64)
+	SetTopP(p float64)
+	SetMaxTokens(n int)
+}
+
+// newLLMClient creates a client for a provider type (openai, ollama or llamacpp).
+func newLLMClient(kind, baseURL, apiKey, model string) samplingLLM {
+	switch kind {
+	case runtime.ProviderOllama:
+		return runtime.NewOllamaLLM(baseURL, model)
+	case runtime.ProviderLlamaCpp:
+		return runtime.NewLlamaCppLLM(baseURL, apiKey, model)
+	}
+	client := runtime.NewOpenAILLM(baseURL, apiKey, model)
+	if v := os.Getenv("LLM_STREAM_USAGE"); v == "false" || v == "0" {
+		client.SetStreamUsage(false)
+	}
+	return client
+}
+
+// retryPolicyFromEnv reads LLM_MAX_ATTEMPTS, LLM_RETRY_BASE_DELAY and LLM_RETRY_MAX_DELAY.
+func retryPolicyFromEnv() runtime.RetryPolicy {
+	policy := runtime.RetryPolicy{MaxAttempts: envInt("LLM_MAX_ATTEMPTS", 0)}
+	if d, err := time.ParseDuration(os.Getenv("LLM_RETRY_BASE_DELAY")); err == nil {
+		policy.BaseDelay = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("LLM_RETRY_MAX_DELAY")); err == nil {
+		policy.MaxDelay = d
+	}
+	return policy
+}
+
+// fallbacksFromEnv reads LLM_FALLBACK_<n>_MODEL / _BASE_URL / _API_KEY / _PROVIDER, numbered
+// from 1; URL and key default to the primary's, the provider type to openai.
+func fallbacksFromEnv(baseURL, apiKey string) []runtime.LLMProvider {
+	var providers []runtime.LLMProvider
+	for i := 1; ; i++ {
+		prefix := fmt.Sprintf("LLM_FALLBACK_%d_", i)
+		fbModel := os.Getenv(prefix + "MODEL")
+		if fbModel == "" {
+			return providers
+		}
+		fbURL, fbKey := os.Getenv(prefix+"BASE_URL"), os.Getenv(prefix+"API_KEY")
+		if fbURL == "" {
+			fbURL = baseURL
+		}
+		if fbKey == "" {
+			fbKey = apiKey
+		}
+		providers = append(providers, runtime.LLMProvider{Name: fbModel, LLM: newLLMClient(os.Getenv(prefix+"PROVIDER"), fbURL, fbKey, fbModel)})
+	}
+}
+
+// limitsFromEnv reads <PREFIX>_MAX_LLM_CALLS, _MAX_TOOL_CALLS, _MAX_TOKENS, _MAX_COST
+// and _MAX_DURATION over the given defaults. 0 disables a guard.
+func limitsFromEnv(prefix string, def runtime.Limits) runtime.Limits {
+	l := def
+	l.MaxLLMCalls = envInt(prefix+"_MAX_LLM_CALLS", l.MaxLLMCalls)
+	l.MaxToolCalls = envInt(prefix+"_MAX_TOOL_CALLS", l.MaxToolCalls)
+	l.MaxTokens = envInt(prefix+"_MAX_TOKENS", l.MaxTokens)
+	if v := os.Getenv(prefix + "_MAX_COST"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			l.MaxCost = f
+		}
+	}
+	if v := os.Getenv(prefix + "_MAX_DURATION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			l.MaxDuration = d
+		}
+	}
+	return l
+}
+
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return def
+}
+
+// newEmbeddingProvider builds an embedding provider for the given mode:
+// "openai" uses EMBEDDING_BASE_URL/EMBEDDING_API_KEY/EMBEDDING_MODEL (falling back
+// to the LLM_* settings), "hash" uses the offline hash provider, anything else disables it.
+func newEmbeddingProvider(mode string) embed.Provider {
+	switch mode {
+	case "openai", "1", "true", "on":
+		apiKey := os.Getenv("EMBEDDING_API_KEY")
+		if apiKey == "" {
+			apiKey = os.Getenv("LLM_API_KEY")
+		}
+		if apiKey == "" {
+			return nil
+		}
+		baseURL := os.Getenv("EMBEDDING_BASE_URL")
+		if baseURL == "" {
+			baseURL = os.Getenv("LLM_BASE_URL")
+		}
+		return embed.NewOpenAIProvider(baseURL, apiKey, os.Getenv("EMBEDDING_MODEL"))
+	case "hash":
+		return embed.NewHashProvider(0)
+	default:
+		return nil
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"strings"
 	"time"
 
-	"AgentEngine/cmd/ui"
 	"AgentEngine/pkg/engine/api"
 
 	"github.com/spf13/cobra"
@@ -124,8 +123,8 @@ func runEval(cmd *cobra.Command, args []string) {
 	}
 	before := snapshotFiles(workspaceRoot, targetFiles)
 
-	approver := ui.NewCLIApprover()
-	approval := &approvalState{}
+	approver := newApprover(eng)
+	approval := &approvalState{skipMemoryReview: true}
 
 	prompt := fmt.Sprintf(
 		"Project: %s\nAspect: all\n\nBased on the outline at %s, build or refine the world setting. Read any existing world files first, ensure internal consistency, and update world files under workspace/novel/%s/world/.\n",
@@ -0,0 +1,491 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+
+	"github.com/spf13/cobra"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Headless Mode
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// With --output ndjson, run and chat print every event as one JSON line on stdout and
+// never prompt. Approvals are answered from --approvals-from, and the exit code tells
+// how the turn ended.
+
+var (
+	outputFlag        string
+	approvalsFromFlag string
+)
+
+const (
+	outputText   = "text"
+	outputNDJSON = "ndjson"
+)
+
+// Exit codes of headless runs.
+const (
+	exitCompleted        = 0
+	exitError            = 1   // an error event, a failed turn or a failed command
+	exitUsage            = 2   // bad flags or an unreadable approvals source
+	exitRejected         = 3   // a tool call was rejected and the turn stopped
+	exitApprovalRequired = 4   // an approval was left unanswered; the session keeps it pending
+	exitLimit            = 5   // a turn or autopilot limit ended the run
+	exitIncomplete       = 6   // autopilot stopped on a blocked, errored or stalled plan
+	exitCanceled         = 130 // interrupted
+)
+
+func addHeadlessFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFlag, "output", outputText, "Output format: text | ndjson (one JSON event per line, no prompts)")
+	cmd.Flags().StringVar(&approvalsFromFlag, "approvals-from", "", "With --output ndjson: answer approvals from a decision stream or policy file (path, fd:N or - for stdin)")
+}
+
+// headlessMode validates --output and --approvals-from and reports whether ndjson was chosen.
+func headlessMode() (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(outputFlag)) {
+	case "", outputText:
+		if approvalsFromFlag != "" {
+			return false, fmt.Errorf("--approvals-from requires --output ndjson")
+		}
+		return false, nil
+	case outputNDJSON:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown output format %q (want text or ndjson)", outputFlag)
+	}
+}
+
+// headlessOutcome records how a headless turn ended.
+type headlessOutcome struct {
+	Done    *api.DonePayload
+	Error   *api.ErrorPayload
+	Report  *api.ProgressReportPayload
+	Pending *api.ApprovalPayload // approval nobody answered
+	Err     error                // the turn could not be started, resumed or read
+}
+
+// exitCode maps the outcome to the process exit code.
+func (o headlessOutcome) exitCode() int {
+	switch {
+	case o.Pending != nil:
+		return exitApprovalRequired
+	case o.Err != nil:
+		return exitError
+	}
+
+	reason := ""
+	if o.Done != nil {
+		reason = o.Done.Reason
+	}
+	switch {
+	case reason == "completed":
+		if o.Report != nil {
+			switch o.Report.Reason {
+			case api.StopMaxTurns, api.StopMaxDuration, api.StopTokenBudget:
+				return exitLimit
+			case api.StopPlanBlocked, api.StopPlanErrored, api.StopPlanStalled, api.StopNoProgress:
+				return exitIncomplete
+			}
+		}
+		return exitCompleted
+	case reason == "rejected":
+		return exitRejected
+	case reason == "canceled":
+		return exitCanceled
+	case api.IsLimitCode(reason):
+		return exitLimit
+	case o.Error != nil && api.IsLimitCode(o.Error.Code):
+		return exitLimit
+	}
+	return exitError
+}
+
+// runHeadlessTurn sends message and writes the turn's events to w as NDJSON, answering
+// approvals from approvals (nil leaves them pending). Interrupting the process cancels
+// the turn.
+func runHeadlessTurn(ctx context.Context, eng api.Engine, sessionID, message string, w io.Writer, approvals approvalSource, autoApprove bool) headlessOutcome {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := eng.Send(ctx, sessionID, message)
+	if err != nil {
+		return headlessOutcome{Err: err}
+	}
+
+	stop, _ := turnControls(eng, sessionID, cancel)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			stop()
+		case <-ctx.Done():
+		}
+	}()
+
+	enc := json.NewEncoder(w)
+	var out headlessOutcome
+	for {
+		pending, err := writeNDJSONEvents(ctx, stream, enc, &out)
+		stream.Close()
+		if err != nil {
+			if ctx.Err() != nil && out.Done == nil {
+				out.Done = &api.DonePayload{Reason: "canceled"}
+				return out
+			}
+			out.Err = err
+			return out
+		}
+		if pending == nil {
+			return out
+		}
+
+		var decision api.Decision
+		answered := false
+		switch {
+		case autoApprove:
+			decision = api.Decision{Kind: api.DecisionApprove, RequestID: pending.RequestID, ToolCallID: pending.ToolCallID}
+			answered = true
+		case approvals != nil:
+			decision, answered, err = approvals.Decide(*pending)
+			if err != nil {
+				out.Err = err
+				return out
+			}
+		}
+		if !answered {
+			out.Pending = pending
+			return out
+		}
+
+		stream, err = eng.Resume(ctx, sessionID, decision)
+		if err != nil {
+			out.Err = err
+			return out
+		}
+	}
+}
+
+// writeNDJSONEvents encodes events until the stream ends, recording the outcome. It
+// returns the approval the turn suspended on, if any.
+func writeNDJSONEvents(ctx context.Context, stream api.EventStream, enc *json.Encoder, out *headlessOutcome) (*api.ApprovalPayload, error) {
+	for {
+		e, err := stream.Recv(ctx)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(e); err != nil {
+			return nil, fmt.Errorf("failed to write event: %w", err)
+		}
+
+		switch e.Type {
+		case api.EventError:
+			out.Error = e.Error
+		case api.EventProgressReport:
+			out.Report = e.ProgressReport
+		case api.EventDone:
+			out.Done = e.Done
+			return nil, nil
+		case api.EventApproval:
+			if e.Approval == nil {
+				return nil, fmt.Errorf("approval event missing payload")
+			}
+			return e.Approval, nil
+		}
+	}
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Approval Sources
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// approvalSource answers approvals without a terminal. It reports false when it has no
+// answer, which leaves the approval pending.
+type approvalSource interface {
+	Decide(p api.ApprovalPayload) (api.Decision, bool, error)
+}
+
+// openApprovalSource opens --approvals-from: "-" is stdin, "fd:N" an inherited file
+// descriptor, anything else a file. A file holding a single policy object is a policy;
+// otherwise the input is read as a decision stream, one decision per approval.
+func openApprovalSource(spec string) (approvalSource, func(), error) {
+	noop := func() {}
+	switch {
+	case spec == "":
+		return nil, noop, nil
+	case spec == "-":
+		return newDecisionStream(os.Stdin), noop, nil
+	case strings.HasPrefix(spec, "fd:"):
+		fd, err := strconv.Atoi(strings.TrimPrefix(spec, "fd:"))
+		if err != nil || fd < 0 {
+			return nil, noop, fmt.Errorf("invalid file descriptor %q", spec)
+		}
+		f := os.NewFile(uintptr(fd), spec)
+		if f == nil {
+			return nil, noop, fmt.Errorf("invalid file descriptor %q", spec)
+		}
+		return newDecisionStream(f), func() { f.Close() }, nil
+	}
+
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return nil, noop, err
+	}
+	policy, err := parseApprovalPolicy(data)
+	if err != nil {
+		return nil, noop, fmt.Errorf("%s: %v", spec, err)
+	}
+	if policy != nil {
+		return policy, noop, nil
+	}
+	return newDecisionStream(bytes.NewReader(data)), noop, nil
+}
+
+// decisionLine is one decision of a decision stream. RequestID and ToolCallID default
+// to the approval being answered.
+type decisionLine struct {
+	RequestID    string           `json:"request_id,omitempty"`
+	Kind         api.DecisionKind `json:"kind"`
+	ToolCallID   string           `json:"tool_call_id,omitempty"`
+	ModifiedArgs api.Args         `json:"modified_args,omitempty"`
+	Note         string           `json:"note,omitempty"`
+	Items        []struct {
+		ToolCallID   string           `json:"tool_call_id"`
+		Kind         api.DecisionKind `json:"kind"`
+		ModifiedArgs api.Args         `json:"modified_args,omitempty"`
+		Note         string           `json:"note,omitempty"`
+	} `json:"items,omitempty"`
+}
+
+// decisionStream answers each approval with the next line of its input; blank lines
+// are skipped. It has no answer once the input ends.
+type decisionStream struct {
+	r    *bufio.Reader
+	line int
+}
+
+func newDecisionStream(r io.Reader) *decisionStream {
+	return &decisionStream{r: bufio.NewReader(r)}
+}
+
+func (s *decisionStream) Decide(p api.ApprovalPayload) (api.Decision, bool, error) {
+	for {
+		text, err := s.r.ReadString('\n')
+		if text = strings.TrimSpace(text); text != "" {
+			s.line++
+			return parseDecisionLine(text, p, s.line)
+		}
+		if err == io.EOF {
+			return api.Decision{}, false, nil
+		}
+		if err != nil {
+			return api.Decision{}, false, fmt.Errorf("failed to read decision: %w", err)
+		}
+	}
+}
+
+func parseDecisionLine(text string, p api.ApprovalPayload, n int) (api.Decision, bool, error) {
+	var line decisionLine
+	if err := json.Unmarshal([]byte(text), &line); err != nil {
+		return api.Decision{}, false, fmt.Errorf("decision %d: %v", n, err)
+	}
+	if !validDecisionKind(line.Kind) {
+		return api.Decision{}, false, fmt.Errorf("decision %d: unknown kind %q", n, line.Kind)
+	}
+	decision := api.Decision{
+		Kind:         line.Kind,
+		RequestID:    line.RequestID,
+		ToolCallID:   line.ToolCallID,
+		ModifiedArgs: line.ModifiedArgs,
+		Note:         line.Note,
+	}
+	if decision.RequestID == "" {
+		decision.RequestID = p.RequestID
+	}
+	if decision.ToolCallID == "" {
+		decision.ToolCallID = p.ToolCallID
+	}
+	for _, item := range line.Items {
+		if !validDecisionKind(item.Kind) {
+			return api.Decision{}, false, fmt.Errorf("decision %d: unknown kind %q for %s", n, item.Kind, item.ToolCallID)
+		}
+		decision.Items = append(decision.Items, api.ItemDecision{ToolCallID: item.ToolCallID, Kind: item.Kind, ModifiedArgs: item.ModifiedArgs, Note: item.Note})
+	}
+	return decision, true, nil
+}
+
+func validDecisionKind(kind api.DecisionKind) bool {
+	switch kind {
+	case api.DecisionApprove, api.DecisionReject, api.DecisionModify:
+		return true
+	}
+	return false
+}
+
+// approvalPolicy decides approvals by tool name, e.g.
+//
+//	{"default": "reject", "tools": {"read_file": "approve", "write_file": "approve"}}
+//
+// Tools not listed get Default, which is "reject" when empty.
+type approvalPolicy struct {
+	Default api.DecisionKind            `json:"default"`
+	Tools   map[string]api.DecisionKind `json:"tools"`
+}
+
+// parseApprovalPolicy returns the policy data holds, or nil when data is not a single
+// policy object.
+func parseApprovalPolicy(data []byte) (*approvalPolicy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var policy approvalPolicy
+	if err := dec.Decode(&policy); err != nil {
+		return nil, nil
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, nil
+	}
+	if policy.Default == "" && policy.Tools == nil {
+		return nil, nil
+	}
+	if policy.Default == "" {
+		policy.Default = api.DecisionReject
+	}
+	if !policyKind(policy.Default) {
+		return nil, fmt.Errorf("policy default must be approve or reject, got %q", policy.Default)
+	}
+	for tool, kind := range policy.Tools {
+		if !policyKind(kind) {
+			return nil, fmt.Errorf("policy for %s must be approve or reject, got %q", tool, kind)
+		}
+	}
+	return &policy, nil
+}
+
+func policyKind(kind api.DecisionKind) bool {
+	return kind == api.DecisionApprove || kind == api.DecisionReject
+}
+
+func (p *approvalPolicy) kindFor(tool string) api.DecisionKind {
+	if kind, ok := p.Tools[tool]; ok {
+		return kind
+	}
+	return p.Default
+}
+
+func (p *approvalPolicy) Decide(req api.ApprovalPayload) (api.Decision, bool, error) {
+	decision := api.Decision{Kind: p.kindFor(req.ToolCall.ToolName), RequestID: req.RequestID, ToolCallID: req.ToolCallID}
+	if len(req.Items) == 0 {
+		return decision, true, nil
+	}
+	decision.Kind = api.DecisionReject
+	for _, call := range req.Items {
+		kind := p.kindFor(call.ToolName)
+		if kind == api.DecisionApprove {
+			decision.Kind = api.DecisionApprove
+		}
+		decision.Items = append(decision.Items, api.ItemDecision{ToolCallID: call.ToolCallID, Kind: kind})
+	}
+	return decision, true, nil
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Commands
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// commandError prints a command failure: on stdout in text mode, and on stderr with
+// exitError in headless mode, where stdout carries only events.
+func commandError(headless bool, format string, args ...interface{}) {
+	if !headless {
+		fmt.Printf(format+"\n", args...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(exitError)
+}
+
+// runHeadless runs the turns of a headless command and returns its exit code. Each
+// message is one turn; the first turn that does not complete ends the run.
+func runHeadless(ctx context.Context, eng api.Engine, sessionID string, messages func() (string, bool, error)) int {
+	approvals, closeApprovals, err := openApprovalSource(approvalsFromFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: approvals: %v\n", err)
+		return exitUsage
+	}
+	defer closeApprovals()
+
+	fmt.Fprintf(os.Stderr, "Session=%s\n", sessionID)
+	for {
+		message, ok, err := messages()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		if !ok {
+			return exitCompleted
+		}
+		outcome := runHeadlessTurn(ctx, eng, sessionID, message, os.Stdout, approvals, autoApproveFlag)
+		if outcome.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", outcome.Err)
+		}
+		if code := outcome.exitCode(); code != exitCompleted {
+			return code
+		}
+	}
+}
+
+// singleMessage yields message once.
+func singleMessage(message string) func() (string, bool, error) {
+	sent := false
+	return func() (string, bool, error) {
+		if sent {
+			return "", false, nil
+		}
+		sent = true
+		return message, true, nil
+	}
+}
+
+// lineMessages yields one message per non-empty input line. A line starting with "{"
+// is a JSON object {"message": "..."}, for messages spanning several lines.
+func lineMessages(r io.Reader) func() (string, bool, error) {
+	br := bufio.NewReader(r)
+	return func() (string, bool, error) {
+		for {
+			line, err := br.ReadString('\n')
+			if text := strings.TrimSpace(line); text != "" {
+				if !strings.HasPrefix(text, "{") {
+					return text, true, nil
+				}
+				var msg struct {
+					Message string `json:"message"`
+				}
+				if err := json.Unmarshal([]byte(text), &msg); err != nil || strings.TrimSpace(msg.Message) == "" {
+					return "", false, fmt.Errorf("invalid message line %q", text)
+				}
+				return msg.Message, true, nil
+			}
+			if err == io.EOF {
+				return "", false, nil
+			}
+			if err != nil {
+				return "", false, err
+			}
+		}
+	}
+}
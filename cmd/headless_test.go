@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/store"
+)
+
+// scriptedEngine replays one event script per Send/Resume call. Session settings
+// and views come from its fields.
+type scriptedEngine struct {
+	scripts   [][]api.Event
+	decisions []api.Decision
+	steers    []string
+
+	info    api.SessionInfo // settings returned for every session
+	updates []api.SessionUpdate
+	plan    *api.PlanPayload
+	changes []api.FileChange
+	usage   []api.UsageRecord
+}
+
+func (e *scriptedEngine) StartSession(ctx context.Context, opts api.StartOptions) (string, error) {
+	return "sess_1", nil
+}
+
+func (e *scriptedEngine) GetSession(ctx context.Context, sessionID string) (api.SessionInfo, error) {
+	info := e.info
+	info.SessionID = sessionID
+	return info, nil
+}
+
+func (e *scriptedEngine) ListSessions(ctx context.Context) ([]api.SessionInfo, error) {
+	return nil, nil
+}
+
+func (e *scriptedEngine) Send(ctx context.Context, sessionID, message string) (api.EventStream, error) {
+	return e.next(), nil
+}
+
+func (e *scriptedEngine) Resume(ctx context.Context, sessionID string, decision api.Decision) (api.EventStream, error) {
+	e.decisions = append(e.decisions, decision)
+	return e.next(), nil
+}
+
+func (e *scriptedEngine) Cancel(sessionID string) error {
+	return fmt.Errorf("%s: %s", api.ErrNoActiveTurn, sessionID)
+}
+
+func (e *scriptedEngine) Steer(sessionID, message string) error {
+	e.steers = append(e.steers, message)
+	return nil
+}
+
+func (e *scriptedEngine) PendingMemoryProposal(ctx context.Context, sessionID string) (*api.MemoryProposalPayload, error) {
+	return nil, nil
+}
+
+func (e *scriptedEngine) ResolveMemoryProposal(ctx context.Context, sessionID, requestID string, reviews []api.MemoryReview) ([]api.MemoryEntry, error) {
+	return nil, nil
+}
+
+func (e *scriptedEngine) UpdateSession(ctx context.Context, sessionID string, u api.SessionUpdate) (api.SessionInfo, error) {
+	e.updates = append(e.updates, u)
+	if u.ApprovalMode != nil {
+		e.info.ApprovalMode = *u.ApprovalMode
+	}
+	if u.ActiveSkill != nil {
+		e.info.ActiveSkill = *u.ActiveSkill
+	}
+	if u.Model != nil {
+		e.info.Model = *u.Model
+	}
+	return e.GetSession(ctx, sessionID)
+}
+
+func (e *scriptedEngine) SessionPlan(ctx context.Context, sessionID string) (*api.PlanPayload, error) {
+	return e.plan, nil
+}
+
+func (e *scriptedEngine) ChangedFiles(ctx context.Context, sessionID string) ([]api.FileChange, error) {
+	return e.changes, nil
+}
+
+func (e *scriptedEngine) ExportSession(ctx context.Context, sessionID, format string, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s as %s", sessionID, format)
+	return err
+}
+
+func (e *scriptedEngine) ModelRoles() map[string]string {
+	return map[string]string{"main": "main-model", "summarizer": "small-model"}
+}
+
+func (e *scriptedEngine) UsageRecords(ctx context.Context, sessionID string) ([]api.UsageRecord, error) {
+	return e.usage, nil
+}
+
+func (e *scriptedEngine) next() api.EventStream {
+	stream := store.NewChannelEventStream(len(e.scripts[0]))
+	for _, ev := range e.scripts[0] {
+		stream.Send(ev)
+	}
+	stream.Close()
+	e.scripts = e.scripts[1:]
+	return stream
+}
+
+func approvalEvent(calls ...api.ToolCallPayload) api.Event {
+	p := &api.ApprovalPayload{RequestID: "req_1", ToolCallID: calls[0].ToolCallID, ToolCall: calls[0]}
+	if len(calls) > 1 {
+		p.Items = calls
+	}
+	return api.Event{Type: api.EventApproval, Approval: p}
+}
+
+func doneEvent(reason string) api.Event {
+	return api.Event{Type: api.EventDone, Done: &api.DonePayload{Reason: reason}}
+}
+
+func TestHeadlessOutcome_ExitCodes(t *testing.T) {
+	cases := []struct {
+		name    string
+		outcome headlessOutcome
+		want    int
+	}{
+		{"completed", headlessOutcome{Done: &api.DonePayload{Reason: "completed"}}, exitCompleted},
+		{"rejected", headlessOutcome{Done: &api.DonePayload{Reason: "rejected"}}, exitRejected},
+		{"canceled", headlessOutcome{Done: &api.DonePayload{Reason: "canceled"}}, exitCanceled},
+		{"limit reason", headlessOutcome{Done: &api.DonePayload{Reason: api.ErrLimitTokens}}, exitLimit},
+		{"limit error", headlessOutcome{Error: &api.ErrorPayload{Code: api.ErrToolLoop}, Done: &api.DonePayload{Reason: "error"}}, exitLimit},
+		{"error", headlessOutcome{Error: &api.ErrorPayload{Code: api.ErrStoreError}, Done: &api.DonePayload{Reason: "error"}}, exitError},
+		{"no done", headlessOutcome{}, exitError},
+		{"pending", headlessOutcome{Pending: &api.ApprovalPayload{}}, exitApprovalRequired},
+		{"autopilot budget", headlessOutcome{Done: &api.DonePayload{Reason: "completed"}, Report: &api.ProgressReportPayload{Reason: api.StopTokenBudget}}, exitLimit},
+		{"autopilot blocked", headlessOutcome{Done: &api.DonePayload{Reason: "completed"}, Report: &api.ProgressReportPayload{Reason: api.StopPlanBlocked}}, exitIncomplete},
+		{"autopilot done", headlessOutcome{Done: &api.DonePayload{Reason: "completed"}, Report: &api.ProgressReportPayload{Reason: api.StopPlanDone}}, exitCompleted},
+	}
+	for _, c := range cases {
+		if got := c.outcome.exitCode(); got != c.want {
+			t.Fatalf("%s: expected exit code %d, got %d", c.name, c.want, got)
+		}
+	}
+}
+
+func TestRunHeadlessTurn_WritesNDJSONAndAnswersFromDecisionStream(t *testing.T) {
+	call := api.ToolCallPayload{ToolCallID: "c1", ToolName: "write_file", NeedApproval: true}
+	eng := &scriptedEngine{scripts: [][]api.Event{
+		{{Type: api.EventDelta, Delta: &api.DeltaPayload{Text: "hi"}}, approvalEvent(call)},
+		{{Type: api.EventToolResult, ToolResult: &api.ToolResultPayload{ToolCallID: "c1"}}, doneEvent("completed")},
+	}}
+	decisions := newDecisionStream(strings.NewReader("\n{\"kind\":\"modify\",\"modified_args\":{\"path\":\"b.txt\"}}\n"))
+
+	var out bytes.Buffer
+	outcome := runHeadlessTurn(context.Background(), eng, "sess_1", "go", &out, decisions, false)
+	if code := outcome.exitCode(); code != exitCompleted {
+		t.Fatalf("expected a completed turn, got exit code %d (%+v)", code, outcome)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 event lines, got %d: %q", len(lines), out.String())
+	}
+	for _, line := range lines {
+		var ev api.Event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil || ev.Type == "" {
+			t.Fatalf("expected an event per line, got %q (%v)", line, err)
+		}
+	}
+
+	if len(eng.decisions) != 1 {
+		t.Fatalf("expected one decision, got %+v", eng.decisions)
+	}
+	d := eng.decisions[0]
+	if d.Kind != api.DecisionModify || d.RequestID != "req_1" || d.ToolCallID != "c1" || d.ModifiedArgs["path"] != "b.txt" {
+		t.Fatalf("expected the decision completed from the approval, got %+v", d)
+	}
+}
+
+func TestRunHeadlessTurn_LeavesApprovalPendingWithoutAnswer(t *testing.T) {
+	call := api.ToolCallPayload{ToolCallID: "c1", ToolName: "shell", NeedApproval: true}
+	eng := &scriptedEngine{scripts: [][]api.Event{{approvalEvent(call)}}}
+
+	var out bytes.Buffer
+	outcome := runHeadlessTurn(context.Background(), eng, "sess_1", "go", &out, newDecisionStream(strings.NewReader("")), false)
+	if code := outcome.exitCode(); code != exitApprovalRequired || len(eng.decisions) != 0 {
+		t.Fatalf("expected the approval left pending, got exit code %d and %+v", code, eng.decisions)
+	}
+}
+
+func TestOpenApprovalSource_PolicyDecidesBatchByTool(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(path, []byte(`{"tools": {"read_file": "approve"}}`), 0644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	src, closeSrc, err := openApprovalSource(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer closeSrc()
+	if _, ok := src.(*approvalPolicy); !ok {
+		t.Fatalf("expected a policy, got %T", src)
+	}
+
+	read := api.ToolCallPayload{ToolCallID: "c1", ToolName: "read_file", NeedApproval: true}
+	shell := api.ToolCallPayload{ToolCallID: "c2", ToolName: "shell", NeedApproval: true}
+	d, ok, err := src.Decide(*approvalEvent(read, shell).Approval)
+	if err != nil || !ok {
+		t.Fatalf("expected an answer, got %v %v", ok, err)
+	}
+	if d.Kind != api.DecisionApprove || len(d.Items) != 2 || d.Items[0].Kind != api.DecisionApprove || d.Items[1].Kind != api.DecisionReject {
+		t.Fatalf("expected read_file approved and shell rejected, got %+v", d)
+	}
+
+	// A file of decision lines is a decision stream.
+	path = filepath.Join(dir, "decisions.ndjson")
+	if err := os.WriteFile(path, []byte("{\"kind\":\"reject\"}\n"), 0644); err != nil {
+		t.Fatalf("write decisions: %v", err)
+	}
+	if src, _, err := openApprovalSource(path); err != nil {
+		t.Fatalf("open: %v", err)
+	} else if _, ok := src.(*decisionStream); !ok {
+		t.Fatalf("expected a decision stream, got %T", src)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"default": "maybe"}`), 0644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	if _, _, err := openApprovalSource(path); err == nil {
+		t.Fatalf("expected an invalid policy refused")
+	}
+}
+
+func TestLineMessages_PlainAndJSONLines(t *testing.T) {
+	next := lineMessages(strings.NewReader("first\n\n{\"message\": \"two\\nlines\"}\n"))
+	var got []string
+	for {
+		msg, ok, err := next()
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		if !ok {
+			break
+		}
+		got = append(got, msg)
+	}
+	if len(got) != 2 || got[0] != "first" || got[1] != "two\nlines" {
+		t.Fatalf("unexpected messages: %q", got)
+	}
+}
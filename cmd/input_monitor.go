@@ -20,8 +20,9 @@ func hitlDebugEnabled() bool {
 
 // monitorCancellation puts the terminal in raw mode and listens for ESC key.
 // It returns a cleanup function that must be called to restore terminal mode.
-// If ESC is pressed twice, it calls cancel().
-func monitorCancellation(ctx context.Context, cancel func()) func() {
+// If ESC is pressed twice, it calls cancel(). With a steer function, other typing is
+// collected into a line that is passed to steer on Enter.
+func monitorCancellation(ctx context.Context, cancel func(), steer func(string)) func() {
 	// check if stdin is a terminal
 	fd := int(os.Stdin.Fd())
 	if !term.IsTerminal(fd) {
@@ -67,6 +68,7 @@ func monitorCancellation(ctx context.Context, cancel func()) func() {
 		buf := make([]byte, 1)
 		escCount := 0
 		lastEscTime := time.Time{}
+		var line []byte // steering message being typed
 
 		for {
 			select {
@@ -122,6 +124,9 @@ func monitorCancellation(ctx context.Context, cancel func()) func() {
 				} else {
 					// Reset on any other key
 					escCount = 0
+					if steer != nil {
+						line = readSteerKey(line, key, steer)
+					}
 				}
 			}
 		}
@@ -129,3 +134,34 @@ func monitorCancellation(ctx context.Context, cancel func()) func() {
 
 	return cleanup
 }
+
+// readSteerKey adds a typed byte to the steering line and echoes it. Enter sends
+// the line to steer; Backspace deletes the last character.
+func readSteerKey(line []byte, key byte, steer func(string)) []byte {
+	switch {
+	case key == '\r' || key == '\n':
+		if len(line) > 0 {
+			fmt.Print("\r\n")
+			steer(string(line))
+		}
+		return nil
+	case key == 127 || key == 8:
+		if len(line) == 0 {
+			return line
+		}
+		fmt.Print("\b \b")
+		// Drop a whole UTF-8 sequence.
+		i := len(line) - 1
+		for i > 0 && line[i]&0xC0 == 0x80 {
+			i--
+		}
+		return line[:i]
+	case key < 32:
+		return line
+	}
+	if len(line) == 0 {
+		fmt.Print("\r\n↪️  ")
+	}
+	_, _ = os.Stdout.Write([]byte{key})
+	return append(line, key)
+}
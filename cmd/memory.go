@@ -0,0 +1,694 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"AgentEngine/cmd/ui"
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/memory"
+
+	"github.com/spf13/cobra"
+)
+
+// memoryFilterFlags are the selection flags shared by list, search, prune and export.
+type memoryFilterFlags struct {
+	types     []string
+	tags      []string
+	source    string
+	olderThan string
+	newerThan string
+}
+
+func (f *memoryFilterFlags) bind(cmd *cobra.Command, defaultOlderThan string) {
+	cmd.Flags().StringSliceVar(&f.types, "type", nil, "Filter by type: fact, preference, decision, lesson (repeatable)")
+	cmd.Flags().StringSliceVar(&f.tags, "tag", nil, "Filter by tag (repeatable; all must match)")
+	cmd.Flags().StringVar(&f.source, "source", "", "Filter by source: user | project")
+	cmd.Flags().StringVar(&f.olderThan, "older-than", defaultOlderThan, "Only entries not used or updated within this age (e.g. 90d, 2w, 36h)")
+	cmd.Flags().StringVar(&f.newerThan, "newer-than", "", "Only entries used or updated within this age")
+}
+
+func (f *memoryFilterFlags) build() (memory.Filter, error) {
+	var out memory.Filter
+	for _, t := range f.types {
+		mt, err := memory.ParseType(t)
+		if err != nil {
+			return out, err
+		}
+		out.Types = append(out.Types, mt)
+	}
+	out.Tags = f.tags
+	if f.source != "" {
+		src, err := memory.ParseSource(f.source)
+		if err != nil {
+			return out, err
+		}
+		out.Source = src
+	}
+	var err error
+	if out.OlderThan, err = memory.ParseAge(f.olderThan); err != nil {
+		return out, err
+	}
+	if out.NewerThan, err = memory.ParseAge(f.newerThan); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+var (
+	memListFilter   memoryFilterFlags
+	memSearchFilter memoryFilterFlags
+	memPruneFilter  memoryFilterFlags
+	memExportFilter memoryFilterFlags
+
+	memJSONFlag     bool
+	memAddType      string
+	memAddSource    string
+	memAddTags      []string
+	memForceFlag    bool
+	memEditContent  string
+	memEditType     string
+	memEditSource   string
+	memEditTags     []string
+	memPruneDups    bool
+	memDryRunFlag   bool
+	memYesFlag      bool
+	memExportOutput string
+	memFormatFlag   string
+	memDupThreshold float64
+	memSearchLimit  int
+	memImportDryRun bool
+	memImportFormat string
+	memImportThresh float64
+)
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Inspect and manage stored memories",
+	Long: `Manage structured memory stored under workspace/memory/ (user.json and project.json).
+
+Without a subcommand, lists all entries.`,
+	Run: runMemoryList,
+}
+
+var memoryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List memory entries (near-duplicates are flagged)",
+	Args:  cobra.NoArgs,
+	Run:   runMemoryList,
+}
+
+var memorySearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Rank memory entries against a query",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runMemorySearch,
+}
+
+var memoryAddCmd = &cobra.Command{
+	Use:   "add <content>",
+	Short: "Add a memory entry",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runMemoryAdd,
+}
+
+var memoryEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a memory entry's content, type, tags or source",
+	Args:  cobra.ExactArgs(1),
+	Run:   runMemoryEdit,
+}
+
+var memoryRmCmd = &cobra.Command{
+	Use:     "rm <id> [id...]",
+	Aliases: []string{"delete"},
+	Short:   "Delete memory entries",
+	Args:    cobra.MinimumNArgs(1),
+	Run:     runMemoryRm,
+}
+
+var memoryPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete stale and/or duplicate memory entries",
+	Long: `Deletes entries that have not been used or updated within --older-than (default 90d).
+With --dups, also deletes near-duplicates, keeping the most recently active entry of each pair.`,
+	Args: cobra.NoArgs,
+	Run:  runMemoryPrune,
+}
+
+var memoryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export memory entries as Markdown or JSONL",
+	Args:  cobra.NoArgs,
+	Run:   runMemoryExport,
+}
+
+var memoryImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import memory entries from Markdown or JSONL (use - for stdin)",
+	Args:  cobra.ExactArgs(1),
+	Run:   runMemoryImport,
+}
+
+func init() {
+	memListFilter.bind(memoryListCmd, "")
+	memoryListCmd.Flags().BoolVar(&memJSONFlag, "json", false, "Print entries as JSON")
+	memoryListCmd.Flags().Float64Var(&memDupThreshold, "dup-threshold", memory.DefaultDuplicateThreshold, "Similarity at which entries are flagged as duplicates")
+
+	memSearchFilter.bind(memorySearchCmd, "")
+	memorySearchCmd.Flags().IntVar(&memSearchLimit, "limit", 10, "Max results")
+
+	memoryAddCmd.Flags().StringVar(&memAddType, "type", string(api.MemoryFact), "fact | preference | decision | lesson")
+	memoryAddCmd.Flags().StringVar(&memAddSource, "source", "", "user | project (default: user for preferences, else project)")
+	memoryAddCmd.Flags().StringSliceVar(&memAddTags, "tags", nil, "Comma-separated tags")
+	memoryAddCmd.Flags().BoolVar(&memForceFlag, "force", false, "Add even if a near-duplicate exists")
+
+	memoryEditCmd.Flags().StringVar(&memEditContent, "content", "", "New content")
+	memoryEditCmd.Flags().StringVar(&memEditType, "type", "", "New type")
+	memoryEditCmd.Flags().StringVar(&memEditSource, "source", "", "Move to source: user | project")
+	memoryEditCmd.Flags().StringSliceVar(&memEditTags, "tags", nil, "Replace tags (comma-separated; empty string clears)")
+
+	memPruneFilter.bind(memoryPruneCmd, "90d")
+	memoryPruneCmd.Flags().BoolVar(&memPruneDups, "dups", false, "Also delete near-duplicates")
+	memoryPruneCmd.Flags().BoolVar(&memDryRunFlag, "dry-run", false, "Show what would be deleted")
+	memoryPruneCmd.Flags().BoolVarP(&memYesFlag, "yes", "y", false, "Do not ask for confirmation")
+
+	memExportFilter.bind(memoryExportCmd, "")
+	memoryExportCmd.Flags().StringVarP(&memExportOutput, "output", "o", "", "Output file (default: stdout)")
+	memoryExportCmd.Flags().StringVar(&memFormatFlag, "format", "", "markdown | jsonl (default: from file extension, else markdown)")
+
+	memoryImportCmd.Flags().StringVar(&memImportFormat, "format", "", "markdown | jsonl (default: from file extension)")
+	memoryImportCmd.Flags().BoolVar(&memImportDryRun, "dry-run", false, "Show what would be imported")
+	memoryImportCmd.Flags().Float64Var(&memImportThresh, "dup-threshold", memory.DefaultDuplicateThreshold, "Similarity at which incoming entries are skipped as duplicates")
+
+	memoryCmd.AddCommand(memoryListCmd, memorySearchCmd, memoryAddCmd, memoryEditCmd, memoryRmCmd, memoryPruneCmd, memoryExportCmd, memoryImportCmd)
+	rootCmd.AddCommand(memoryCmd)
+}
+
+func newMemoryManager() (*memory.StructuredManager, error) {
+	workspaceRoot, err := resolveWorkspaceRoot()
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewStructuredManager(workspaceRoot), nil
+}
+
+func runMemoryList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	filter, err := memListFilter.build()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	all, err := mem.All(ctx)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	entries := memory.FilterEntries(all, filter)
+
+	if memJSONFlag {
+		b, _ := json.MarshalIndent(entries, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	printMemoryEntries(entries, memory.FindDuplicates(entries, memDupThreshold))
+}
+
+func runMemorySearch(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	filter, err := memSearchFilter.build()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	query := strings.Join(args, " ")
+	ranked, err := mem.Retrieve(ctx, memory.Query{Text: query})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\n🔎 Memory matching %q:\n", query)
+	n := 0
+	for _, se := range ranked {
+		if !filter.Match(se.Entry) || se.BM25 == 0 && se.Semantic == 0 {
+			continue
+		}
+		if memSearchLimit > 0 && n >= memSearchLimit {
+			break
+		}
+		n++
+		fmt.Printf("  %.3f  %s\n", se.Score, formatMemoryEntry(se.Entry))
+	}
+	if n == 0 {
+		fmt.Println("  (no matches)")
+	}
+}
+
+func runMemoryAdd(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	typ, err := memory.ParseType(memAddType)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	src := api.MemorySourceProject
+	if typ == api.MemoryPreference {
+		src = api.MemorySourceUser
+	}
+	if memAddSource != "" {
+		if src, err = memory.ParseSource(memAddSource); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+	}
+	entry := api.MemoryEntry{
+		ID:      fmt.Sprintf("mem_%d", time.Now().UnixNano()),
+		Type:    typ,
+		Source:  src,
+		Content: strings.Join(args, " "),
+		Tags:    memAddTags,
+	}
+
+	if !memForceFlag {
+		all, err := mem.All(ctx)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		for _, e := range all {
+			if sim := memory.Similarity(entry.Content, e.Content); sim >= memory.DefaultDuplicateThreshold {
+				fmt.Printf("⚠️  Similar entry exists (%.2f): %s\n", sim, formatMemoryEntry(e))
+				fmt.Println("Use --force to add anyway, or `memory edit` to update it.")
+				return
+			}
+		}
+	}
+
+	if err := mem.Add(ctx, entry); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Added %s\n", entry.ID)
+}
+
+func runMemoryEdit(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	entry, err := mem.Get(ctx, args[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	orig := entry
+
+	changed := false
+	if cmd.Flags().Changed("content") {
+		entry.Content = memEditContent
+		changed = true
+	}
+	if cmd.Flags().Changed("type") {
+		if entry.Type, err = memory.ParseType(memEditType); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		changed = true
+	}
+	if cmd.Flags().Changed("tags") {
+		entry.Tags = nil
+		for _, t := range memEditTags {
+			if t = strings.TrimSpace(t); t != "" {
+				entry.Tags = append(entry.Tags, t)
+			}
+		}
+		changed = true
+	}
+	if cmd.Flags().Changed("source") {
+		if entry.Source, err = memory.ParseSource(memEditSource); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		changed = true
+	}
+	if !changed {
+		fmt.Println("Nothing to change. Use --content, --type, --tags or --source.")
+		return
+	}
+
+	if entry.Source != orig.Source {
+		if err := mem.Move(ctx, entry, orig.Source); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+	} else if err := mem.Update(ctx, entry); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Updated %s\n", entry.ID)
+}
+
+func runMemoryRm(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, id := range args {
+		if err := mem.Delete(ctx, id); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		fmt.Printf("🗑️  Deleted %s\n", id)
+	}
+}
+
+func runMemoryPrune(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	filter, err := memPruneFilter.build()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	// An empty or zero age would select every entry.
+	if filter.OlderThan <= 0 {
+		fmt.Println("❌ --older-than must be > 0")
+		return
+	}
+	all, err := mem.All(ctx)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	reasons := make(map[string]string)
+	var victims []api.MemoryEntry
+	for _, e := range memory.FilterEntries(all, filter) {
+		reasons[e.ID] = "stale"
+		victims = append(victims, e)
+	}
+	if memPruneDups {
+		// Duplicates are judged across all entries matching the non-age filters.
+		dupFilter := filter
+		dupFilter.OlderThan = 0
+		for _, d := range memory.FindDuplicates(memory.FilterEntries(all, dupFilter), 0) {
+			if _, ok := reasons[d.Drop.ID]; ok {
+				continue
+			}
+			reasons[d.Drop.ID] = fmt.Sprintf("duplicate of %s (%.2f)", d.Keep.ID, d.Similarity)
+			victims = append(victims, d.Drop)
+		}
+	}
+
+	if len(victims) == 0 {
+		fmt.Println("Nothing to prune.")
+		return
+	}
+	fmt.Printf("\n🧹 %d entries to prune:\n", len(victims))
+	for _, e := range victims {
+		fmt.Printf("  %s\n      ↳ %s\n", formatMemoryEntry(e), reasons[e.ID])
+	}
+	if memDryRunFlag {
+		fmt.Println("\n(dry run, nothing deleted)")
+		return
+	}
+	if !memYesFlag {
+		ok, _ := ui.Confirm("\nDelete these entries?")
+		if !ok {
+			fmt.Println("Aborted.")
+			return
+		}
+	}
+
+	deleted := 0
+	for _, e := range victims {
+		if err := mem.Delete(ctx, e.ID); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		deleted++
+	}
+	fmt.Printf("✅ Pruned %d entries\n", deleted)
+}
+
+func runMemoryExport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	filter, err := memExportFilter.build()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return
+	}
+	all, err := mem.All(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	entries := memory.FilterEntries(all, filter)
+
+	format := resolveMemoryFormat(memFormatFlag, memExportOutput)
+	var w io.Writer = os.Stdout
+	if memExportOutput != "" && memExportOutput != "-" {
+		f, err := os.Create(memExportOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if format == memory.FormatJSONL {
+		err = memory.WriteJSONL(w, entries)
+	} else {
+		err = memory.WriteMarkdown(w, entries)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	if memExportOutput != "" && memExportOutput != "-" {
+		fmt.Printf("✅ Exported %d entries to %s (%s)\n", len(entries), memExportOutput, format)
+	}
+}
+
+func runMemoryImport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	mem, err := newMemoryManager()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	path := args[0]
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var entries []api.MemoryEntry
+	if resolveMemoryFormat(memImportFormat, path) == memory.FormatJSONL {
+		entries, err = memory.ReadJSONL(r)
+	} else {
+		entries, err = memory.ReadMarkdown(r)
+	}
+	if err != nil {
+		fmt.Printf("❌ Parse failed: %v\n", err)
+		return
+	}
+
+	res, err := mem.Import(ctx, entries, memImportThresh, memImportDryRun)
+	if err != nil {
+		fmt.Printf("❌ Import failed: %v\n", err)
+		return
+	}
+
+	for _, d := range res.Duplicates {
+		fmt.Printf("↩︎ Skipped (duplicate of %s, %.2f): %s\n", d.Keep.ID, d.Similarity, truncateSkillStr(d.Drop.Content, 60))
+	}
+	for _, e := range res.Invalid {
+		fmt.Printf("⚠️  Invalid entry %q (type=%q source=%q)\n", e.ID, e.Type, e.Source)
+	}
+	verb := "Imported"
+	if memImportDryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("✅ %s %d entries (%d duplicates, %d invalid)\n", verb, len(res.Added), len(res.Duplicates), len(res.Invalid))
+}
+
+func resolveMemoryFormat(flag, path string) string {
+	switch strings.ToLower(strings.TrimSpace(flag)) {
+	case "jsonl", "ndjson", "json":
+		return memory.FormatJSONL
+	case "md", "markdown":
+		return memory.FormatMarkdown
+	}
+	if path == "" || path == "-" {
+		return memory.FormatMarkdown
+	}
+	return memory.FormatFromPath(path)
+}
+
+// printMemoryEntries prints entries grouped by source, flagging near-duplicates.
+func printMemoryEntries(entries []api.MemoryEntry, dups []memory.Duplicate) {
+	if len(entries) == 0 {
+		fmt.Println("No memory entries found.")
+		return
+	}
+	dupOf := make(map[string]memory.Duplicate, len(dups))
+	for _, d := range dups {
+		dupOf[d.Drop.ID] = d
+	}
+
+	for _, src := range []api.MemorySource{api.MemorySourceProject, api.MemorySourceUser} {
+		header := false
+		for _, e := range entries {
+			if e.Source != src {
+				continue
+			}
+			if !header {
+				fmt.Printf("\n🧠 %s memory:\n", src)
+				header = true
+			}
+			fmt.Printf("  %s\n", formatMemoryEntry(e))
+			if d, ok := dupOf[e.ID]; ok {
+				fmt.Printf("      ⚠️  near-duplicate of %s (%.2f)\n", d.Keep.ID, d.Similarity)
+			}
+		}
+	}
+	if len(dups) > 0 {
+		fmt.Printf("\n%d near-duplicate(s). Clean up with: memory prune --dups\n", len(dups))
+	}
+}
+
+func formatMemoryEntry(e api.MemoryEntry) string {
+	s := fmt.Sprintf("%s [%s] %s", e.ID, e.Type, truncateSkillStr(strings.ReplaceAll(e.Content, "\n", " "), 80))
+	if len(e.Tags) > 0 {
+		s += " #" + strings.Join(e.Tags, " #")
+	}
+	meta := []string{memoryAge(memory.LastActivity(e))}
+	if e.Hits > 0 {
+		meta = append(meta, fmt.Sprintf("%d hits", e.Hits))
+	}
+	return s + " (" + strings.Join(meta, ", ") + ")"
+}
+
+func memoryAge(t time.Time) string {
+	if t.IsZero() {
+		return "never used"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Hour:
+		return "just now"
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// runMemorySlash handles "/memory [list|search <q>|rm <id>|dups|review]" inside the REPL.
+func runMemorySlash(ctx context.Context, eng api.Engine, workspaceRoot, sessionID string, approver *ui.CLIApprover, args []string) {
+	mem := memory.NewStructuredManager(workspaceRoot)
+	sub := "list"
+	if len(args) > 0 {
+		sub = strings.ToLower(args[0])
+		args = args[1:]
+	}
+
+	switch sub {
+	case "list", "ls":
+		all, err := mem.All(ctx)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		printMemoryEntries(all, memory.FindDuplicates(all, 0))
+	case "search", "find":
+		if len(args) == 0 {
+			fmt.Println("Usage: /memory search <query>")
+			return
+		}
+		ranked, err := mem.Retrieve(ctx, memory.Query{Text: strings.Join(args, " "), Limit: 10})
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		if len(ranked) == 0 {
+			fmt.Println("No matches.")
+			return
+		}
+		for _, se := range ranked {
+			fmt.Printf("  %.3f  %s\n", se.Score, formatMemoryEntry(se.Entry))
+		}
+	case "rm", "delete":
+		for _, id := range args {
+			if err := mem.Delete(ctx, id); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			fmt.Printf("🗑️  Deleted %s\n", id)
+		}
+	case "dups":
+		all, err := mem.All(ctx)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		dups := memory.FindDuplicates(all, 0)
+		if len(dups) == 0 {
+			fmt.Println("No near-duplicates found.")
+			return
+		}
+		for _, d := range dups {
+			fmt.Printf("  %.2f  %s\n        ≈ %s\n", d.Similarity, formatMemoryEntry(d.Drop), formatMemoryEntry(d.Keep))
+		}
+	case "review":
+		if err := reviewMemoryProposal(ctx, eng, sessionID, approver, nil); err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+	default:
+		fmt.Println("Usage: /memory [list | search <query> | rm <id>... | dups | review]")
+	}
+}
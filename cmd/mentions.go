@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"AgentEngine/cmd/ui"
+	"AgentEngine/pkg/engine/api"
+)
+
+// Budgets for files attached with @mentions: each file gets at most
+// mentionFileBytes, all of a message's mentions together mentionTotalBytes.
+const (
+	mentionFileBytes  = 32 * 1024
+	mentionTotalBytes = 96 * 1024
+	mentionDirEntries = 200
+)
+
+// mentionParts attaches the files and directories mentioned with @path in text:
+// text files inline (truncated to the budget), images as images, directories as a
+// listing. Mentions that are not workspace paths (e.g. @someone) are ignored; a
+// trailing punctuation mark is dropped when the path only exists without it.
+func mentionParts(paths *ui.PathIndex, text string) []api.ContentPart {
+	var parts []api.ContentPart
+	budget := mentionTotalBytes
+	for _, mention := range ui.Mentions(text) {
+		rel, info, ok := resolveMention(paths.Root(), mention)
+		if !ok {
+			continue
+		}
+		if info.IsDir() {
+			entries := paths.Children(rel)
+			listing := strings.Join(entries[:min(len(entries), mentionDirEntries)], "\n")
+			if len(entries) > mentionDirEntries {
+				listing += fmt.Sprintf("\n... (%d more entries)", len(entries)-mentionDirEntries)
+			}
+			parts = append(parts, api.ContentPart{Type: api.PartFile, Path: rel + "/", Text: "Directory listing:\n" + listing})
+			fmt.Printf("📎 @%s/ (%d entries)\n", rel, len(entries))
+			continue
+		}
+
+		abs := filepath.Join(paths.Root(), rel)
+		if mimeType := api.ImageMIMEType(rel); mimeType != "" {
+			if info.Size() > maxAttachmentBytes {
+				fmt.Printf("⚠️  @%s is too large to attach (%d KB)\n", rel, info.Size()/1024)
+				continue
+			}
+			data, err := os.ReadFile(abs)
+			if err != nil {
+				fmt.Printf("⚠️  @%s: %v\n", rel, err)
+				continue
+			}
+			parts = append(parts, api.ImagePart(mimeType, data, rel))
+			fmt.Printf("📎 @%s (image)\n", rel)
+			continue
+		}
+
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			fmt.Printf("⚠️  @%s: %v\n", rel, err)
+			continue
+		}
+		if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+			// Binary: only name it, the agent decides what to do with it.
+			parts = append(parts, api.ContentPart{Type: api.PartFile, Path: rel})
+			fmt.Printf("📎 @%s (binary, referenced)\n", rel)
+			continue
+		}
+		content, note := truncateMention(string(data), min(mentionFileBytes, budget))
+		budget -= len(content)
+		parts = append(parts, api.ContentPart{Type: api.PartFile, Path: rel, Text: content + note})
+		if note != "" {
+			fmt.Printf("📎 @%s (truncated to %d of %d lines)\n", rel, strings.Count(content, "\n"), strings.Count(string(data), "\n")+1)
+		} else {
+			fmt.Printf("📎 @%s\n", rel)
+		}
+	}
+	return parts
+}
+
+// resolveMention returns the mentioned path relative to root, if it exists inside it.
+func resolveMention(root, mention string) (string, os.FileInfo, bool) {
+	for _, candidate := range []string{mention, strings.TrimRight(mention, ".,;:!?)\"'")} {
+		if candidate == "" {
+			continue
+		}
+		abs := filepath.Clean(filepath.Join(root, filepath.FromSlash(candidate)))
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if info, err := os.Stat(abs); err == nil {
+			return filepath.ToSlash(rel), info, true
+		}
+	}
+	return "", nil, false
+}
+
+// truncateMention cuts content to at most limit bytes at a line boundary and returns
+// a note telling the model how to read the rest.
+func truncateMention(content string, limit int) (string, string) {
+	if len(content) <= limit {
+		return content, ""
+	}
+	cut := strings.LastIndexByte(content[:max(limit, 0)], '\n') + 1
+	lines := strings.Count(content[:cut], "\n")
+	total := strings.Count(content, "\n") + 1
+	return content[:cut], fmt.Sprintf("[truncated after line %d of %d; read_file with start_line=%d reads the rest]", lines, total, lines+1)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AgentEngine/cmd/ui"
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestMentionParts_AttachesFilesAndDirectoriesWithinBudget(t *testing.T) {
+	root := t.TempDir()
+	big := strings.Repeat("0123456789abcdef\n", mentionFileBytes/17+100)
+	for name, content := range map[string]string{
+		"notes.md":    "# Notes\n",
+		"src/big.txt": big,
+		"src/a.go":    "package a\n",
+	} {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(root), "outside.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parts := mentionParts(ui.NewPathIndex(root), "read @notes.md, then @src/ and @src/big.txt; not @alice or @../outside.txt")
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %+v", parts)
+	}
+	if parts[0].Type != api.PartFile || parts[0].Path != "notes.md" || parts[0].Text != "# Notes\n" {
+		t.Fatalf("expected notes.md inline, got %+v", parts[0])
+	}
+	if parts[1].Path != "src/" || !strings.Contains(parts[1].Text, "src/a.go\nsrc/big.txt") {
+		t.Fatalf("expected a listing of src/, got %+v", parts[1])
+	}
+	if len(parts[2].Text) > mentionFileBytes+200 || !strings.Contains(parts[2].Text, "read_file with start_line=") {
+		t.Fatalf("expected big.txt truncated with a note, got %d bytes", len(parts[2].Text))
+	}
+}
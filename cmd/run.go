@@ -3,9 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
-	"AgentEngine/cmd/ui"
 	"AgentEngine/pkg/engine/api"
 
 	"github.com/spf13/cobra"
@@ -20,41 +20,66 @@ var runCmd = &cobra.Command{
 
 func init() {
 	runCmd.Flags().StringArrayP("arg", "a", []string{}, "Skill arguments (key=value)")
+	runCmd.Flags().Bool("until-plan-done", false, "Keep running continuation turns until the plan is done, blocked or errored")
+	runCmd.Flags().Int("max-turns", 0, "With --until-plan-done: maximum turns (default 20)")
+	runCmd.Flags().Duration("max-duration", 0, "With --until-plan-done: wall time limit, checked between turns (default 30m)")
+	runCmd.Flags().Int("token-budget", 0, "With --until-plan-done: estimated token budget (0 = unlimited)")
+	addHeadlessFlags(runCmd)
 	rootCmd.AddCommand(runCmd)
 }
 
 func runSkill(cmd *cobra.Command, args []string) {
 	skillName := args[0]
 
+	headless, err := headlessMode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
 	workspaceRoot, err := resolveWorkspaceRoot()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		commandError(headless, "Error: %v", err)
 		return
 	}
 
 	eng, err := newAPIEngine(workspaceRoot)
 	if err != nil {
-		fmt.Printf("Error initializing engine: %v\n", err)
+		commandError(headless, "Error initializing engine: %v", err)
 		return
 	}
 
 	argFlags, _ := cmd.Flags().GetStringArray("arg")
 	skillArgs := parseArgs(argFlags)
+	untilPlanDone, _ := cmd.Flags().GetBool("until-plan-done")
+	maxTurns, _ := cmd.Flags().GetInt("max-turns")
+	maxDuration, _ := cmd.Flags().GetDuration("max-duration")
+	tokenBudget, _ := cmd.Flags().GetInt("token-budget")
 
 	ctx := context.Background()
 	sessionID, err := eng.StartSession(ctx, api.StartOptions{
-		ApprovalMode: resolveApprovalMode(),
-		EmitThinking: emitThinkingFlag,
-		ActiveSkill:  skillName,
+		ApprovalMode:  resolveApprovalMode(),
+		EmitThinking:  emitThinkingFlag,
+		ActiveSkill:   skillName,
+		UntilPlanDone: untilPlanDone,
+		Autopilot: api.AutopilotLimits{
+			MaxTurns:    maxTurns,
+			MaxDuration: maxDuration,
+			TokenBudget: tokenBudget,
+		},
 	})
 	if err != nil {
-		fmt.Printf("Error starting session: %v\n", err)
+		commandError(headless, "Error starting session: %v", err)
 		return
 	}
 
-	approver := ui.NewCLIApprover()
-	approval := &approvalState{}
 	userMessage := buildRunInput(skillArgs)
+	if headless {
+		os.Exit(runHeadless(ctx, eng, sessionID, singleMessage(userMessage)))
+	}
+
+	approver := newApprover(eng)
+	approval := &approvalState{}
 
 	fmt.Printf("Session=%s Skill=%s\n", sessionID, skillName)
 	if err := runTurnWithApprovals(ctx, eng, sessionID, userMessage, approver, approval); err != nil {
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/memory"
+	"AgentEngine/pkg/engine/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageByFlag     string
+	usagePricesFlag string
+	usageSinceFlag  string
+	usageJSONFlag   bool
+	doctorFixFlag   bool
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Inspect stored sessions",
+}
+
+var sessionsUsageCmd = &cobra.Command{
+	Use:   "usage [session-id]",
+	Short: "Report token usage and estimated cost per session, skill or day",
+	Long: `Report LLM calls, tokens and estimated cost recorded for each session.
+
+Token counts come from the provider when it reports usage and are estimated otherwise.
+Cost is computed from the built-in price table; --prices (or PRICE_TABLE) points to a
+JSON file that overrides it, e.g. {"my-model": {"input_per_mtok": 1, "output_per_mtok": 2}}.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runSessionsUsage,
+}
+
+var sessionsDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Find and repair damaged sessions",
+	Long: `Check every stored session for unreadable data, tool calls that never got a result
+(left by a crash; chat APIs reject such history) and locks left by dead processes.
+
+With --fix, missing tool results are recorded as interrupted and stale locks are removed.
+Sessions in use by a running process are skipped.`,
+	Args: cobra.NoArgs,
+	Run:  runSessionsDoctor,
+}
+
+func init() {
+	sessionsUsageCmd.Flags().StringVar(&usageByFlag, "by", "session", "Group by: session | skill | day")
+	sessionsUsageCmd.Flags().StringVar(&usagePricesFlag, "prices", "", "JSON price table (default: PRICE_TABLE or built-in prices)")
+	sessionsUsageCmd.Flags().StringVar(&usageSinceFlag, "since", "", "Only days within this age (e.g. 7d, 2w)")
+	sessionsUsageCmd.Flags().BoolVar(&usageJSONFlag, "json", false, "Output JSON")
+	sessionsDoctorCmd.Flags().BoolVar(&doctorFixFlag, "fix", false, "Repair the problems found")
+	sessionsCmd.AddCommand(sessionsUsageCmd)
+	sessionsCmd.AddCommand(sessionsDoctorCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+func runSessionsDoctor(cmd *cobra.Command, args []string) {
+	workspaceRoot, err := resolveWorkspaceRoot()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	eng, err := newAPIEngine(workspaceRoot)
+	if err != nil {
+		fmt.Printf("Error initializing engine: %v\n", err)
+		return
+	}
+	rt, ok := eng.(*runtime.Engine)
+	if !ok {
+		fmt.Println("Error: session checks require the runtime engine")
+		return
+	}
+
+	diags, err := rt.DoctorSessions(context.Background(), doctorFixFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(diags) == 0 {
+		fmt.Println("✅ All sessions are healthy.")
+		return
+	}
+
+	fmt.Printf("\n🩺 %d session(s) with problems:\n", len(diags))
+	unfixed := 0
+	for _, d := range diags {
+		mark := "⚠️ "
+		if d.Fixed {
+			mark = "✅"
+		} else {
+			unfixed++
+		}
+		fmt.Printf("  %s %s\n", mark, d.SessionID)
+		for _, p := range d.Problems {
+			fmt.Printf("       - %s\n", p)
+		}
+	}
+	if !doctorFixFlag {
+		fmt.Println("\nRun with --fix to repair.")
+	} else if unfixed > 0 {
+		fmt.Printf("\n%d session(s) could not be repaired.\n", unfixed)
+	}
+}
+
+// usageRow is one line of the usage report.
+type usageRow struct {
+	Key   string    `json:"key"`
+	Usage api.Usage `json:"usage"`
+}
+
+func runSessionsUsage(cmd *cobra.Command, args []string) {
+	switch usageByFlag {
+	case "session", "skill", "day":
+	default:
+		fmt.Printf("❌ invalid --by %q (want session, skill or day)\n", usageByFlag)
+		return
+	}
+
+	workspaceRoot, err := resolveWorkspaceRoot()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	eng, err := newAPIEngine(workspaceRoot)
+	if err != nil {
+		fmt.Printf("Error initializing engine: %v\n", err)
+		return
+	}
+	prices := loadPriceTable()
+	if usagePricesFlag != "" {
+		if prices, err = runtime.LoadPriceTable(usagePricesFlag); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+	}
+	since := ""
+	if usageSinceFlag != "" {
+		age, err := memory.ParseAge(usageSinceFlag)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		since = time.Now().Add(-age).Format("2006-01-02")
+	}
+
+	sessionID := ""
+	if len(args) == 1 {
+		sessionID = args[0]
+	}
+	records, err := eng.UsageRecords(context.Background(), sessionID)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	rows, total := groupUsage(records, usageByFlag, since, prices)
+	if usageJSONFlag {
+		b, _ := json.MarshalIndent(map[string]any{"by": usageByFlag, "rows": rows, "total": total}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	if len(rows) == 0 {
+		fmt.Println("No usage recorded.")
+		return
+	}
+
+	fmt.Printf("\n📊 Usage by %s:\n", usageByFlag)
+	fmt.Printf("  %-32s %6s %6s %10s %10s %9s\n", usageByFlag, "calls", "tools", "prompt", "output", "cost")
+	for _, r := range rows {
+		printUsageRow(r.Key, r.Usage)
+	}
+	printUsageRow("total", total)
+	if total.EstimatedCalls > 0 {
+		fmt.Printf("\n  ~ %d of %d LLM calls had no provider usage; their tokens are estimated.\n", total.EstimatedCalls, total.LLMCalls)
+	}
+}
+
+func printUsageRow(key string, u api.Usage) {
+	fmt.Printf("  %-32s %6d %6d %10d %10d %9s\n", key, u.LLMCalls, u.ToolCalls, u.PromptTokens, u.CompletionTokens, fmt.Sprintf("$%.4f", u.Cost))
+}
+
+// groupUsage sums records per key, re-pricing each record with prices for its model.
+// Records before since (YYYY-MM-DD) are skipped.
+func groupUsage(records []api.UsageRecord, by, since string, prices runtime.PriceTable) ([]usageRow, api.Usage) {
+	sums := make(map[string]api.Usage)
+	var total api.Usage
+	for _, rec := range records {
+		if since != "" && rec.Day < since {
+			continue
+		}
+		u := rec.Usage
+		u.Cost = prices.Cost(rec.Model, u)
+
+		key := rec.SessionID
+		switch by {
+		case "skill":
+			key = rec.Skill
+			if key == "" {
+				key = "(none)"
+			}
+		case "day":
+			key = rec.Day
+		}
+		sums[key] = sums[key].Add(u)
+		total = total.Add(u)
+	}
+
+	rows := make([]usageRow, 0, len(sums))
+	for k, u := range sums {
+		rows = append(rows, usageRow{Key: k, Usage: u})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if by == "day" {
+			return rows[i].Key < rows[j].Key
+		}
+		return rows[i].Usage.Cost > rows[j].Usage.Cost ||
+			(rows[i].Usage.Cost == rows[j].Usage.Cost && rows[i].Key < rows[j].Key)
+	})
+	return rows, total
+}
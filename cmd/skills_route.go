@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"AgentEngine/pkg/engine/runtime"
+	"AgentEngine/pkg/engine/skill"
+	"AgentEngine/pkg/engine/store"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	routeSessionFlag string
+	routePlanFlag    string
+	routeActiveFlag  string
+	routeLockedFlag  bool
+	routeJSONFlag    bool
+)
+
+var skillsRouteCmd = &cobra.Command{
+	Use:   "route <message>",
+	Short: "Dry-run skill routing for a message and explain the decision",
+	Long: `Runs the skill router offline against the current skill index and prints every
+candidate's score, matched triggers, lock state and the final decision.
+
+Use --session to take the active skill, lock state and plan hint from an existing session.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSkillsRoute,
+}
+
+func init() {
+	skillsRouteCmd.Flags().StringVar(&routeSessionFlag, "session", "", "Use active skill, lock state and plan from this session")
+	skillsRouteCmd.Flags().StringVar(&routePlanFlag, "plan", "", "Plan hint (current plan item text)")
+	skillsRouteCmd.Flags().StringVar(&routeActiveFlag, "active", "", "Currently active skill")
+	skillsRouteCmd.Flags().BoolVar(&routeLockedFlag, "locked", false, "Treat the active skill as locked by the user")
+	skillsRouteCmd.Flags().BoolVar(&routeJSONFlag, "json", false, "Print the explanation as JSON")
+	skillsListCmd.AddCommand(skillsRouteCmd)
+}
+
+func runSkillsRoute(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	workspaceRoot, err := resolveWorkspaceRoot()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	idx, err := skill.NewDirSkillIndex(defaultSkillRoots(workspaceRoot)...)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	skills := idx.List()
+
+	in := runtime.SkillRouteInput{
+		UserMessage: args[0],
+		PlanHint:    routePlanFlag,
+		ActiveSkill: routeActiveFlag,
+		Locked:      routeLockedFlag,
+	}
+
+	if routeSessionFlag != "" {
+		sessionStore, err := store.NewFileSessionStore(workspaceRoot)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		sess, err := sessionStore.Get(ctx, routeSessionFlag)
+		if err != nil {
+			fmt.Printf("❌ Session '%s' not found\n", routeSessionFlag)
+			return
+		}
+		if in.ActiveSkill == "" {
+			in.ActiveSkill = sess.ActiveSkill
+		}
+		if sess.Metadata != nil && strings.EqualFold(sess.Metadata["skill_locked"], "true") {
+			in.Locked = true
+		}
+		if in.PlanHint == "" {
+			if planStore, err := store.NewFilePlanStore(workspaceRoot); err == nil {
+				if plan, err := planStore.Get(ctx, "plan_"+sess.SessionID); err == nil {
+					in.PlanHint = runtime.PlanHint(plan)
+				}
+			}
+		}
+	}
+
+	if p := newEmbeddingProvider(os.Getenv("SKILL_ROUTER_EMBEDDINGS")); p != nil {
+		router := runtime.NewSemanticSkillRouter(p, filepath.Join(workspaceRoot, "cache", "embeddings"))
+		sims, err := router.Similarities(ctx, skills, in.UserMessage, in.PlanHint)
+		if err != nil {
+			fmt.Printf("⚠️  Semantic scoring failed, using keywords only: %v\n", err)
+		} else {
+			in.Semantic = sims
+		}
+	}
+
+	exp := runtime.ExplainSkillRoute(skills, in)
+
+	if routeJSONFlag {
+		b, _ := json.MarshalIndent(exp, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+
+	active := in.ActiveSkill
+	if active == "" {
+		active = "(none)"
+	}
+	lock := "unlocked"
+	if in.Locked {
+		lock = "locked"
+	}
+	if exp.Unlocked {
+		lock += " (message unlocks)"
+	}
+
+	fmt.Printf("\n🧭 Skill Routing\n\n")
+	fmt.Printf("Message:  %s\n", in.UserMessage)
+	if in.PlanHint != "" {
+		fmt.Printf("Plan:     %s\n", in.PlanHint)
+	}
+	fmt.Printf("Active:   %s [%s]\n", active, lock)
+	if in.Semantic != nil {
+		fmt.Println("Semantic: enabled")
+	}
+
+	fmt.Println("\n📋 Candidates:")
+	if len(exp.Candidates) == 0 {
+		fmt.Println("  (no candidates scored)")
+	}
+	for _, c := range exp.Candidates {
+		line := fmt.Sprintf("  %-24s score=%-3d keyword=%d", c.Skill, c.Score, c.Keyword)
+		if in.Semantic != nil {
+			line += fmt.Sprintf(" semantic=%.3f(+%d)", c.Similarity, c.Semantic)
+		}
+		fmt.Println(line)
+		if len(c.Matched) > 0 {
+			fmt.Printf("  %-24s matched: %s\n", "", strings.Join(c.Matched, ", "))
+		}
+	}
+
+	fmt.Printf("\nDecision: %s\n", exp.Summary())
+	if exp.Rationale != "" {
+		fmt.Printf("Rationale: %s\n", exp.Rationale)
+	}
+}
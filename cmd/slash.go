@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"AgentEngine/cmd/ui"
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/runtime"
+	"AgentEngine/pkg/engine/skill"
+)
+
+// runSessionSlash handles the REPL's session commands: /skill, /mode, /model,
+// /sessions, /resume, /plan, /usage, /diff, /export and /clear. It returns the session
+// to continue with (changed by /resume and /clear) and whether text was one of them.
+func runSessionSlash(ctx context.Context, eng api.Engine, workspaceRoot, sessionID string, approval *approvalState, text string) (string, bool) {
+	fields := strings.Fields(text)
+	name, args := strings.ToLower(fields[0]), fields[1:]
+	switch name {
+	case "/sessions":
+		listSessions(ctx, eng, "Switch with: /resume <session-id>")
+		return sessionID, true
+	case "/resume":
+		if len(args) != 1 {
+			fmt.Println("Usage: /resume <session-id>")
+			return sessionID, true
+		}
+		info, err := eng.GetSession(ctx, args[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return sessionID, true
+		}
+		*approval = approvalState{}
+		fmt.Printf("↩︎ Resumed %s (%d messages)\n", info.SessionID, info.MessageCount)
+		return info.SessionID, true
+	case "/skill":
+		runSkillSlash(ctx, eng, workspaceRoot, sessionID, args)
+	case "/mode":
+		runModeSlash(ctx, eng, sessionID, approval, args)
+	case "/model":
+		runModelSlash(ctx, eng, sessionID, args)
+	case "/plan":
+		plan, err := eng.SessionPlan(ctx, sessionID)
+		switch {
+		case err != nil:
+			fmt.Printf("❌ %v\n", err)
+		case plan == nil || len(plan.Items) == 0:
+			fmt.Println("No plan yet.")
+		default:
+			renderPlan(*plan)
+		}
+	case "/usage":
+		runUsageSlash(ctx, eng, sessionID)
+	case "/diff":
+		runDiffSlash(ctx, eng, workspaceRoot, sessionID)
+	case "/export":
+		runExportSlash(ctx, eng, sessionID, args)
+	case "/clear":
+		return runClearSlash(ctx, eng, sessionID, approval), true
+	default:
+		return sessionID, false
+	}
+	return sessionID, true
+}
+
+// runSkillSlash handles "/skill [name|off|auto]".
+func runSkillSlash(ctx context.Context, eng api.Engine, workspaceRoot, sessionID string, args []string) {
+	if len(args) == 0 {
+		info, err := eng.GetSession(ctx, sessionID)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		current := info.ActiveSkill
+		if current == "" {
+			current = "(none)"
+		}
+		fmt.Printf("Active skill: %s\n", current)
+		if idx, err := skill.NewDirSkillIndex(defaultSkillRoots(workspaceRoot)...); err == nil {
+			list := idx.List()
+			sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+			for _, s := range list {
+				fmt.Printf("  - %s: %s\n", s.Name, truncateSkillStr(strings.TrimSpace(s.Description), 70))
+			}
+		}
+		fmt.Println("Usage: /skill <name|off|auto>")
+		return
+	}
+
+	var update api.SessionUpdate
+	switch arg := args[0]; strings.ToLower(arg) {
+	case "off", "none":
+		none, auto := "", false
+		update = api.SessionUpdate{ActiveSkill: &none, AutoSkill: &auto}
+	case "auto":
+		auto := true
+		update = api.SessionUpdate{AutoSkill: &auto}
+	default:
+		update = api.SessionUpdate{ActiveSkill: &arg}
+	}
+	info, err := eng.UpdateSession(ctx, sessionID, update)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	switch {
+	case update.AutoSkill != nil && *update.AutoSkill:
+		fmt.Println("🧭 Skills are picked automatically again")
+	case info.ActiveSkill == "":
+		fmt.Println("🧭 Skills off")
+	default:
+		fmt.Printf("🧭 Skill: %s\n", info.ActiveSkill)
+	}
+}
+
+// runModeSlash handles "/mode [suggest|auto|full-auto]".
+func runModeSlash(ctx context.Context, eng api.Engine, sessionID string, approval *approvalState, args []string) {
+	if len(args) == 0 {
+		info, err := eng.GetSession(ctx, sessionID)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		fmt.Printf("Approval mode: %s\nUsage: /mode <suggest|auto|full-auto>\n", info.ApprovalMode)
+		return
+	}
+	mode := api.ApprovalMode(strings.ToLower(args[0]))
+	if mode == "fullauto" {
+		mode = api.ModeFullAuto
+	}
+	info, err := eng.UpdateSession(ctx, sessionID, api.SessionUpdate{ApprovalMode: &mode})
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	// "Approve all" from an earlier prompt no longer applies.
+	approval.autoApproveAll = false
+	fmt.Printf("🔐 Approval mode: %s\n", info.ApprovalMode)
+}
+
+// runModelSlash handles "/model [role|name|main]".
+func runModelSlash(ctx context.Context, eng api.Engine, sessionID string, args []string) {
+	roles := eng.ModelRoles()
+	if len(args) == 0 {
+		info, err := eng.GetSession(ctx, sessionID)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		current := info.Model
+		if current == "" {
+			current = runtime.RoleMain
+		}
+		fmt.Printf("Model: %s\n", current)
+		names := make([]string, 0, len(roles))
+		for role := range roles {
+			names = append(names, role)
+		}
+		sort.Strings(names)
+		for _, role := range names {
+			fmt.Printf("  - %-17s %s\n", role, roles[role])
+		}
+		fmt.Println("Usage: /model <role|model-name|main>")
+		return
+	}
+	model := args[0]
+	info, err := eng.UpdateSession(ctx, sessionID, api.SessionUpdate{Model: &model})
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if info.Model == "" {
+		fmt.Printf("🧠 Model: main (%s)\n", roles[runtime.RoleMain])
+	} else if name, ok := roles[info.Model]; ok {
+		fmt.Printf("🧠 Model: %s (%s)\n", info.Model, name)
+	} else {
+		fmt.Printf("🧠 Model: %s\n", info.Model)
+	}
+}
+
+// runUsageSlash prints the session's usage by skill and model.
+func runUsageSlash(ctx context.Context, eng api.Engine, sessionID string) {
+	records, err := eng.UsageRecords(ctx, sessionID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	rows, total := groupUsage(records, "skill", "", loadPriceTable())
+	if len(rows) == 0 {
+		fmt.Println("No usage recorded.")
+		return
+	}
+	fmt.Printf("\n📊 Usage of %s:\n", sessionID)
+	fmt.Printf("  %-32s %6s %6s %10s %10s %9s\n", "skill", "calls", "tools", "prompt", "output", "cost")
+	for _, r := range rows {
+		printUsageRow(r.Key, r.Usage)
+	}
+	printUsageRow("total", total)
+}
+
+// runDiffSlash lists the files the session changed, with their git diff when the
+// workspace is a git checkout.
+func runDiffSlash(ctx context.Context, eng api.Engine, workspaceRoot, sessionID string) {
+	changes, err := eng.ChangedFiles(ctx, sessionID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Println("No files changed this session.")
+		return
+	}
+	fmt.Println("\n📝 Files changed this session:")
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		fmt.Printf("  %s (%d edits)\n", c.Path, c.Edits)
+		paths = append(paths, c.Path)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", workspaceRoot, "diff", "--no-color", "--"}, paths...)...).Output()
+	if err != nil {
+		fmt.Println("\n(No git diff: the workspace is not a git checkout.)")
+		return
+	}
+	if len(out) > 0 {
+		fmt.Println()
+		fmt.Print(ui.HighlightDiff(string(out)))
+	}
+}
+
+// runExportSlash handles "/export [path]": markdown, or JSON for a .json path.
+func runExportSlash(ctx context.Context, eng api.Engine, sessionID string, args []string) {
+	path := sessionID + ".md"
+	if len(args) > 0 {
+		path = args[0]
+	}
+	format := "markdown"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	err = eng.ExportSession(ctx, sessionID, format, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	fmt.Printf("💾 Exported to %s\n", path)
+}
+
+// runClearSlash starts a fresh session with the current one's settings; the old one
+// stays available to /resume.
+func runClearSlash(ctx context.Context, eng api.Engine, sessionID string, approval *approvalState) string {
+	info, err := eng.GetSession(ctx, sessionID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return sessionID
+	}
+	id, err := eng.StartSession(ctx, api.StartOptions{
+		ApprovalMode: info.ApprovalMode,
+		EmitThinking: emitThinkingFlag,
+		ActiveSkill:  info.ActiveSkill,
+	})
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return sessionID
+	}
+	if info.Model != "" {
+		if _, err := eng.UpdateSession(ctx, id, api.SessionUpdate{Model: &info.Model}); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+	*approval = approvalState{}
+	fmt.Printf("🧹 New session %s (/resume %s to go back)\n", id, sessionID)
+	return id
+}
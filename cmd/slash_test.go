@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestRunSessionSlash_ResumesAndLeavesOtherInputAlone(t *testing.T) {
+	ctx := context.Background()
+	eng := &scriptedEngine{}
+	approval := &approvalState{autoApproveAll: true}
+
+	next, ok := runSessionSlash(ctx, eng, t.TempDir(), "sess_1", approval, "/resume sess_2")
+	if !ok || next != "sess_2" || approval.autoApproveAll {
+		t.Fatalf("expected a switch to sess_2 with approvals reset, got %q ok=%v %+v", next, ok, approval)
+	}
+
+	for _, text := range []string{"/memory list", "/quit", "fix /skill docs"} {
+		if next, ok := runSessionSlash(ctx, eng, t.TempDir(), "sess_1", approval, text); ok || next != "sess_1" {
+			t.Fatalf("expected %q left to the caller", text)
+		}
+	}
+
+}
+
+func TestRunSessionSlash_ChangesSettingsThroughTheEngine(t *testing.T) {
+	ctx := context.Background()
+	eng := &scriptedEngine{}
+	approval := &approvalState{autoApproveAll: true}
+
+	if next, ok := runSessionSlash(ctx, eng, t.TempDir(), "sess_1", approval, "/mode suggest"); !ok || next != "sess_1" {
+		t.Fatalf("expected /mode handled")
+	}
+	if len(eng.updates) != 1 || eng.updates[0].ApprovalMode == nil || *eng.updates[0].ApprovalMode != api.ModeSuggest {
+		t.Fatalf("expected the mode set through UpdateSession, got %+v", eng.updates)
+	}
+	if approval.autoApproveAll {
+		t.Fatalf("expected approve-all reset by /mode")
+	}
+
+	runSessionSlash(ctx, eng, t.TempDir(), "sess_1", approval, "/model summarizer")
+	if eng.info.Model != "summarizer" {
+		t.Fatalf("expected the model set through UpdateSession, got %+v", eng.info)
+	}
+
+	for _, text := range []string{"/plan", "/usage", "/diff", "/skill"} {
+		if next, ok := runSessionSlash(ctx, eng, t.TempDir(), "sess_1", approval, text); !ok || next != "sess_1" {
+			t.Fatalf("expected %q handled", text)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "out.json")
+	runSessionSlash(ctx, eng, t.TempDir(), "sess_1", approval, "/export "+path)
+	if data, err := os.ReadFile(path); err != nil || string(data) != "sess_1 as json" {
+		t.Fatalf("expected a JSON export, got %q err=%v", data, err)
+	}
+}
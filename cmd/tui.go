@@ -0,0 +1,675 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui [session-id]",
+	Short: "Start a full-screen chat with transcript, plan, tool and approval panes",
+	Long: `Start a full-screen chat. The transcript scrolls on its own; the side panes show the
+live plan and the session's tool calls (select one and press Enter to expand it).
+Approvals open a pane with the call's diff: a approves, r rejects, m edits the
+arguments, and in a batch space toggles the selected call and Enter confirms.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runTUI,
+}
+
+func init() {
+	tuiCmd.Flags().StringVar(&activeSkillFlag, "skill", "", "Set initial active skill for a new session")
+	tuiCmd.Flags().StringVar(&approvalModeFlag, "approval-mode", "", "suggest | auto | full-auto (default: auto)")
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func runTUI(cmd *cobra.Command, args []string) {
+	workspaceRoot, err := resolveWorkspaceRoot()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	eng, err := newAPIEngine(workspaceRoot)
+	if err != nil {
+		fmt.Printf("Error initializing engine: %v\n", err)
+		return
+	}
+
+	ctx := context.Background()
+	sessionID := ""
+	note := ""
+	if len(args) > 0 {
+		if info, err := eng.GetSession(ctx, args[0]); err == nil {
+			sessionID = args[0]
+			note = fmt.Sprintf("Resumed %s (%d messages)", sessionID, info.MessageCount)
+		}
+	}
+	if sessionID == "" {
+		sessionID, err = eng.StartSession(ctx, api.StartOptions{
+			ApprovalMode: resolveApprovalMode(),
+			ActiveSkill:  activeSkillFlag,
+		})
+		if err != nil {
+			fmt.Printf("Error starting session: %v\n", err)
+			return
+		}
+		note = "New session " + sessionID
+	}
+
+	m := newTUIModel(ctx, eng, sessionID)
+	m.addEntry(tuiNote, note)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+	}
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// TUI Model
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+type tuiFocus int
+
+const (
+	focusInput tuiFocus = iota
+	focusTranscript
+	focusTools
+)
+
+type tuiEntryKind int
+
+const (
+	tuiUser tuiEntryKind = iota
+	tuiAgent
+	tuiReasoning
+	tuiToolCall
+	tuiNote
+	tuiError
+)
+
+// tuiEntry is one block of the transcript; deltas extend the last agent or reasoning entry.
+type tuiEntry struct {
+	kind tuiEntryKind
+	text string
+}
+
+// tuiTool is a row of the tool pane.
+type tuiTool struct {
+	call     api.ToolCallPayload
+	result   *api.ToolResult
+	expanded bool
+}
+
+// tuiApproval is the state of the approval pane. A single call is a batch of one.
+type tuiApproval struct {
+	payload  api.ApprovalPayload
+	calls    []api.ToolCallPayload
+	approved []bool
+	modified map[int]api.Args
+	cursor   int
+
+	diff    viewport.Model
+	editing bool
+	editor  textarea.Model
+	editErr string
+}
+
+// Stream messages carry the stream they came from, so events of a stream that was
+// replaced (after Resume) are ignored.
+type (
+	tuiStreamMsg struct {
+		stream api.EventStream
+		err    error
+	}
+	tuiEventMsg struct {
+		stream api.EventStream
+		event  api.Event
+	}
+	tuiStreamEndMsg struct {
+		stream api.EventStream
+		err    error
+	}
+)
+
+const tuiInputHeight = 3
+
+type tuiModel struct {
+	ctx       context.Context
+	eng       api.Engine
+	sessionID string
+
+	width, height int
+	focus         tuiFocus
+
+	entries    []tuiEntry
+	transcript viewport.Model
+
+	plan       *api.PlanPayload
+	tools      []*tuiTool
+	toolCursor int
+
+	input textarea.Model
+
+	stream     api.EventStream
+	running    bool
+	turnCtx    context.Context
+	turnCancel context.CancelFunc
+	status     string
+	approval   *tuiApproval
+}
+
+func newTUIModel(ctx context.Context, eng api.Engine, sessionID string) *tuiModel {
+	input := textarea.New()
+	input.Placeholder = "Message the agent (Enter to send, Ctrl+J for a newline)"
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.SetHeight(tuiInputHeight)
+	input.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	input.KeyMap.InsertNewline.SetKeys("ctrl+j")
+	input.Focus()
+
+	m := &tuiModel{
+		ctx:        ctx,
+		eng:        eng,
+		sessionID:  sessionID,
+		width:      100,
+		height:     30,
+		transcript: viewport.New(60, 20),
+		input:      input,
+		status:     "idle",
+	}
+	m.layout()
+	return m
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		return m, nil
+
+	case tuiStreamMsg:
+		if msg.err != nil {
+			m.addEntry(tuiError, msg.err.Error())
+			m.endTurn("idle")
+			return m, nil
+		}
+		m.stream = msg.stream
+		return m, m.recv(msg.stream)
+
+	case tuiEventMsg:
+		if msg.stream != m.stream {
+			return m, nil
+		}
+		m.handleEvent(msg.event)
+		return m, m.recv(msg.stream)
+
+	case tuiStreamEndMsg:
+		if msg.stream != m.stream {
+			return m, nil
+		}
+		msg.stream.Close()
+		m.stream = nil
+		if msg.err != nil && m.ctx.Err() == nil && m.running && m.approval == nil {
+			m.addEntry(tuiError, msg.err.Error())
+		}
+		if m.approval == nil {
+			m.endTurn("idle")
+		}
+		return m, nil
+
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.transcript, cmd = m.transcript.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *tuiModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		if m.running {
+			m.stopTurn()
+		}
+		return m, tea.Quit
+	}
+	if m.approval != nil {
+		return m, m.handleApprovalKey(msg)
+	}
+
+	switch msg.String() {
+	case "esc":
+		if m.running {
+			m.stopTurn()
+		}
+		return m, nil
+	case "tab":
+		m.setFocus((m.focus + 1) % 3)
+		return m, nil
+	case "shift+tab":
+		m.setFocus((m.focus + 2) % 3)
+		return m, nil
+	case "pgup", "pgdown":
+		var cmd tea.Cmd
+		m.transcript, cmd = m.transcript.Update(msg)
+		return m, cmd
+	}
+
+	switch m.focus {
+	case focusTranscript:
+		var cmd tea.Cmd
+		m.transcript, cmd = m.transcript.Update(msg)
+		return m, cmd
+	case focusTools:
+		switch msg.String() {
+		case "up", "k":
+			if m.toolCursor > 0 {
+				m.toolCursor--
+			}
+		case "down", "j":
+			if m.toolCursor < len(m.tools)-1 {
+				m.toolCursor++
+			}
+		case "enter", " ":
+			if m.toolCursor < len(m.tools) {
+				m.tools[m.toolCursor].expanded = !m.tools[m.toolCursor].expanded
+			}
+		}
+		return m, nil
+	}
+
+	if msg.String() == "enter" {
+		return m, m.submit()
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submit sends the input as a new turn, or steers the running one.
+func (m *tuiModel) submit() tea.Cmd {
+	text := strings.TrimSpace(m.input.Value())
+	if text == "" {
+		return nil
+	}
+	m.input.Reset()
+
+	if text == "/quit" || text == "/exit" || text == "/q" {
+		return tea.Quit
+	}
+	if m.running {
+		if err := m.eng.Steer(m.sessionID, text); err != nil {
+			m.addEntry(tuiError, err.Error())
+			return nil
+		}
+		m.addEntry(tuiUser, text+" (steer)")
+		return nil
+	}
+
+	m.addEntry(tuiUser, text)
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.turnCtx, m.turnCancel = ctx, cancel
+	m.running = true
+	m.status = "running"
+	eng, sessionID := m.eng, m.sessionID
+	return func() tea.Msg {
+		stream, err := eng.Send(ctx, sessionID, text)
+		return tuiStreamMsg{stream: stream, err: err}
+	}
+}
+
+func (m *tuiModel) recv(stream api.EventStream) tea.Cmd {
+	ctx := m.ctx
+	return func() tea.Msg {
+		ev, err := stream.Recv(ctx)
+		if err != nil {
+			return tuiStreamEndMsg{stream: stream, err: ignoreEOF(err)}
+		}
+		return tuiEventMsg{stream: stream, event: ev}
+	}
+}
+
+// stopTurn cancels the running turn (see turnControls).
+func (m *tuiModel) stopTurn() {
+	m.status = "stopping"
+	stop, _ := turnControls(m.eng, m.sessionID, m.turnCancel)
+	stop()
+}
+
+func (m *tuiModel) endTurn(status string) {
+	m.running = false
+	m.status = status
+	if m.turnCancel != nil {
+		m.turnCancel()
+		m.turnCtx, m.turnCancel = nil, nil
+	}
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func (m *tuiModel) handleEvent(e api.Event) {
+	switch e.Type {
+	case api.EventDelta:
+		if e.Delta == nil || e.Delta.Text == "" || e.Delta.Source == api.DeltaToolArg {
+			return
+		}
+		kind := tuiAgent
+		if e.Delta.Source == api.DeltaReasoning {
+			kind = tuiReasoning
+		}
+		m.appendText(kind, e.Delta.Text)
+
+	case api.EventThinking:
+		if e.Thinking != nil && strings.TrimSpace(e.Thinking.Message) != "" {
+			m.addEntry(tuiNote, e.Thinking.Message)
+		}
+
+	case api.EventToolCall:
+		if e.ToolCall == nil {
+			return
+		}
+		m.addEntry(tuiToolCall, "🔧 "+e.ToolCall.ToolName)
+		m.tools = append(m.tools, &tuiTool{call: *e.ToolCall})
+		if m.focus != focusTools {
+			m.toolCursor = len(m.tools) - 1
+		}
+
+	case api.EventToolResult:
+		if e.ToolResult == nil {
+			return
+		}
+		result := e.ToolResult.Result
+		if t := m.findTool(e.ToolResult.ToolCallID); t != nil {
+			t.result = &result
+		} else {
+			m.tools = append(m.tools, &tuiTool{
+				call:   api.ToolCallPayload{ToolCallID: e.ToolResult.ToolCallID, ToolName: e.ToolResult.ToolName},
+				result: &result,
+			})
+		}
+		if result.Status == "error" {
+			m.addEntry(tuiToolCall, fmt.Sprintf("   ✗ %s: %s", e.ToolResult.ToolName, result.Error))
+		} else {
+			m.addEntry(tuiToolCall, "   ✓ "+e.ToolResult.ToolName)
+		}
+
+	case api.EventPlan:
+		if e.Plan != nil {
+			plan := *e.Plan
+			m.plan = &plan
+		}
+
+	case api.EventProgressReport:
+		if r := e.ProgressReport; r != nil {
+			m.addEntry(tuiNote, fmt.Sprintf("🏁 Autopilot stopped: %s (%d turns) %s", r.Reason, r.Turns, r.Message))
+			if r.Plan != nil {
+				plan := *r.Plan
+				m.plan = &plan
+			}
+		}
+
+	case api.EventApproval:
+		if e.Approval != nil {
+			m.openApproval(*e.Approval)
+		}
+
+	case api.EventError:
+		if e.Error != nil {
+			m.addEntry(tuiError, fmt.Sprintf("%s: %s", e.Error.Code, e.Error.Message))
+		}
+
+	case api.EventDone:
+		reason := "completed"
+		if e.Done != nil && e.Done.Reason != "" {
+			reason = e.Done.Reason
+		}
+		if reason != "completed" {
+			m.addEntry(tuiNote, "Turn "+reason)
+		}
+		m.endTurn("idle")
+	}
+}
+
+func (m *tuiModel) findTool(id string) *tuiTool {
+	for i := len(m.tools) - 1; i >= 0; i-- {
+		if m.tools[i].call.ToolCallID == id {
+			return m.tools[i]
+		}
+	}
+	return nil
+}
+
+func (m *tuiModel) addEntry(kind tuiEntryKind, text string) {
+	m.entries = append(m.entries, tuiEntry{kind: kind, text: text})
+	m.refreshTranscript()
+}
+
+// appendText extends the last entry when it has the same kind.
+func (m *tuiModel) appendText(kind tuiEntryKind, text string) {
+	if n := len(m.entries); n > 0 && m.entries[n-1].kind == kind {
+		m.entries[n-1].text += text
+		m.refreshTranscript()
+		return
+	}
+	m.addEntry(kind, text)
+}
+
+func (m *tuiModel) setFocus(f tuiFocus) {
+	m.focus = f
+	if f == focusInput {
+		m.input.Focus()
+	} else {
+		m.input.Blur()
+	}
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Approval Pane
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+func (m *tuiModel) openApproval(p api.ApprovalPayload) {
+	calls := p.Calls()
+	a := &tuiApproval{
+		payload:  p,
+		calls:    calls,
+		approved: make([]bool, len(calls)),
+		modified: make(map[int]api.Args),
+		diff:     viewport.New(0, 0),
+	}
+	for i := range a.approved {
+		a.approved[i] = true
+	}
+	m.approval = a
+	m.status = "awaiting approval"
+	m.layout()
+	m.showApprovalCall()
+}
+
+func (m *tuiModel) handleApprovalKey(msg tea.KeyMsg) tea.Cmd {
+	a := m.approval
+	if a.editing {
+		switch msg.String() {
+		case "esc":
+			a.editing = false
+			a.editErr = ""
+			return nil
+		case "ctrl+s":
+			var args api.Args
+			if err := json.Unmarshal([]byte(a.editor.Value()), &args); err != nil {
+				a.editErr = "Invalid JSON: " + err.Error()
+				return nil
+			}
+			a.modified[a.cursor] = args
+			a.approved[a.cursor] = true
+			a.editing = false
+			a.editErr = ""
+			m.showApprovalCall()
+			if len(a.calls) == 1 {
+				return m.decide(a.decision())
+			}
+			return nil
+		}
+		var cmd tea.Cmd
+		a.editor, cmd = a.editor.Update(msg)
+		return cmd
+	}
+
+	switch msg.String() {
+	case "a", "y":
+		for i := range a.approved {
+			a.approved[i] = true
+		}
+		return m.decide(a.decision())
+	case "r", "n":
+		for i := range a.approved {
+			a.approved[i] = false
+		}
+		a.modified = map[int]api.Args{}
+		return m.decide(a.decision())
+	case "enter":
+		return m.decide(a.decision())
+	case " ", "x":
+		a.approved[a.cursor] = !a.approved[a.cursor]
+	case "up", "k":
+		if a.cursor > 0 {
+			a.cursor--
+			m.showApprovalCall()
+		}
+	case "down", "j":
+		if a.cursor < len(a.calls)-1 {
+			a.cursor++
+			m.showApprovalCall()
+		}
+	case "m", "e":
+		m.editApprovalCall()
+	case "esc":
+		if m.running {
+			m.stopTurn()
+		}
+	default:
+		var cmd tea.Cmd
+		a.diff, cmd = a.diff.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// showApprovalCall fills the diff pane with the selected call's preview.
+func (m *tuiModel) showApprovalCall() {
+	a := m.approval
+	call := a.calls[a.cursor]
+	if args, ok := a.modified[a.cursor]; ok {
+		call.Args = args
+	}
+	a.diff.SetContent(renderCallPreview(call, a.diff.Width))
+	a.diff.GotoTop()
+}
+
+func (m *tuiModel) editApprovalCall() {
+	a := m.approval
+	args := a.calls[a.cursor].Args
+	if modified, ok := a.modified[a.cursor]; ok {
+		args = modified
+	}
+	data, _ := json.MarshalIndent(args, "", "  ")
+
+	editor := textarea.New()
+	editor.ShowLineNumbers = true
+	editor.CharLimit = 0
+	editor.SetWidth(a.diff.Width)
+	editor.SetHeight(max(a.diff.Height, 3))
+	editor.SetValue(string(data))
+	editor.Focus()
+	a.editor = editor
+	a.editing = true
+}
+
+// decision builds the decision from the pane: approved or rejected per call, with
+// edited arguments as modifications.
+func (a *tuiApproval) decision() api.Decision {
+	p := a.payload
+	if len(p.Items) == 0 {
+		d := api.Decision{Kind: api.DecisionReject, RequestID: p.RequestID, ToolCallID: p.ToolCallID}
+		if args, ok := a.modified[0]; ok && a.approved[0] {
+			d.Kind, d.ModifiedArgs = api.DecisionModify, args
+		} else if a.approved[0] {
+			d.Kind = api.DecisionApprove
+		}
+		return d
+	}
+
+	d := api.Decision{Kind: api.DecisionReject, RequestID: p.RequestID}
+	for i, call := range a.calls {
+		item := api.ItemDecision{ToolCallID: call.ToolCallID, Kind: api.DecisionReject}
+		if a.approved[i] {
+			d.Kind = api.DecisionApprove
+			item.Kind = api.DecisionApprove
+			if args, ok := a.modified[i]; ok {
+				item.Kind, item.ModifiedArgs = api.DecisionModify, args
+			}
+		}
+		d.Items = append(d.Items, item)
+	}
+	return d
+}
+
+// decide closes the approval pane and resumes the turn.
+func (m *tuiModel) decide(d api.Decision) tea.Cmd {
+	a := m.approval
+	approved := 0
+	for _, ok := range a.approved {
+		if ok {
+			approved++
+		}
+	}
+	switch approved {
+	case len(a.calls):
+		m.addEntry(tuiNote, "✓ Approved "+approvalCallNames(a.calls))
+	case 0:
+		m.addEntry(tuiNote, "✗ Rejected "+approvalCallNames(a.calls))
+	default:
+		m.addEntry(tuiNote, fmt.Sprintf("✓ Approved %d of %d calls", approved, len(a.calls)))
+	}
+	m.approval = nil
+	m.status = "running"
+	m.layout()
+
+	ctx, eng, sessionID := m.turnCtx, m.eng, m.sessionID
+	return func() tea.Msg {
+		stream, err := eng.Resume(ctx, sessionID, d)
+		return tuiStreamMsg{stream: stream, err: err}
+	}
+}
+
+func approvalCallNames(calls []api.ToolCallPayload) string {
+	names := make([]string, len(calls))
+	for i, c := range calls {
+		names[i] = c.ToolName
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// driveTUI runs the model's stream commands synchronously until the stream ends.
+func driveTUI(m *tuiModel, cmd tea.Cmd) {
+	for cmd != nil {
+		msg := cmd()
+		switch msg.(type) {
+		case tuiStreamMsg, tuiEventMsg, tuiStreamEndMsg:
+		default:
+			return
+		}
+		_, cmd = m.Update(msg)
+	}
+}
+
+func tuiKey(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "space":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func sendTUIMessage(m *tuiModel, text string) {
+	m.input.SetValue(text)
+	_, cmd := m.Update(tuiKey("enter"))
+	driveTUI(m, cmd)
+}
+
+func TestTUIModel_FillsTranscriptPlanAndToolPanes(t *testing.T) {
+	plan := &api.PlanPayload{PlanID: "p1", Items: []api.PlanItem{{ID: 1, Text: "List files", Status: api.PlanRunning}}}
+	eng := &scriptedEngine{scripts: [][]api.Event{{
+		{Type: api.EventDelta, Delta: &api.DeltaPayload{Text: "Let me "}},
+		{Type: api.EventDelta, Delta: &api.DeltaPayload{Text: "look."}},
+		{Type: api.EventPlan, Plan: plan},
+		{Type: api.EventToolCall, ToolCall: &api.ToolCallPayload{ToolCallID: "c1", ToolName: "ls", Args: api.Args{"path": "."}}},
+		{Type: api.EventToolResult, ToolResult: &api.ToolResultPayload{ToolCallID: "c1", ToolName: "ls", Result: api.ToolResult{Status: "success", Content: "a.txt"}}},
+		doneEvent("completed"),
+	}}}
+	m := newTUIModel(context.Background(), eng, "sess_1")
+	sendTUIMessage(m, "list files")
+
+	if m.running || m.status != "idle" {
+		t.Fatalf("expected the turn finished, got running=%v status=%q", m.running, m.status)
+	}
+	if len(m.entries) != 4 || m.entries[1].text != "Let me look." {
+		t.Fatalf("expected deltas merged into one reply, got %+v", m.entries)
+	}
+	if len(m.tools) != 1 || m.tools[0].result == nil || m.tools[0].result.Content != "a.txt" {
+		t.Fatalf("expected the tool call with its result, got %+v", m.tools)
+	}
+
+	view := m.View()
+	for _, want := range []string{"list files", "Let me look.", "Plan 0/1", "List files", "Tools (1)"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in the view:\n%s", want, view)
+		}
+	}
+
+	m.setFocus(focusTools)
+	m.Update(tuiKey("enter"))
+	if !m.tools[0].expanded || !strings.Contains(m.View(), "a.txt") {
+		t.Fatalf("expected the tool call expanded with its result:\n%s", m.View())
+	}
+}
+
+func TestTUIModel_BatchApprovalTogglesCalls(t *testing.T) {
+	write := api.ToolCallPayload{ToolCallID: "c1", ToolName: "write_file", NeedApproval: true,
+		Preview: &api.Preview{Kind: api.PreviewDiff, Summary: "edit a.txt", Content: "--- a.txt\n+++ a.txt\n@@ -1 +1 @@\n-old\n+new"}}
+	shell := api.ToolCallPayload{ToolCallID: "c2", ToolName: "shell", NeedApproval: true}
+	eng := &scriptedEngine{scripts: [][]api.Event{
+		{approvalEvent(write, shell)},
+		{doneEvent("completed")},
+	}}
+	m := newTUIModel(context.Background(), eng, "sess_1")
+	sendTUIMessage(m, "edit")
+
+	if m.approval == nil || !m.running {
+		t.Fatalf("expected the approval pane open, got %+v", m.approval)
+	}
+	if view := m.View(); !strings.Contains(view, "+new") || !strings.Contains(view, "edit a.txt") {
+		t.Fatalf("expected the diff in the approval pane:\n%s", view)
+	}
+
+	m.Update(tuiKey("down"))
+	m.Update(tuiKey("space"))
+	_, cmd := m.Update(tuiKey("enter"))
+	driveTUI(m, cmd)
+
+	if m.approval != nil || m.running {
+		t.Fatalf("expected the turn resumed and finished")
+	}
+	d := eng.decisions[0]
+	if d.Kind != api.DecisionApprove || len(d.Items) != 2 || d.Items[0].Kind != api.DecisionApprove || d.Items[1].Kind != api.DecisionReject {
+		t.Fatalf("expected write_file approved and shell rejected, got %+v", d)
+	}
+}
+
+func TestTUIModel_ModifyEditsArguments(t *testing.T) {
+	call := api.ToolCallPayload{ToolCallID: "c1", ToolName: "shell", Args: api.Args{"command": "rm -rf build"}, NeedApproval: true}
+	eng := &scriptedEngine{scripts: [][]api.Event{
+		{approvalEvent(call)},
+		{doneEvent("completed")},
+	}}
+	m := newTUIModel(context.Background(), eng, "sess_1")
+	sendTUIMessage(m, "clean")
+
+	m.Update(tuiKey("m"))
+	if !m.approval.editing || !strings.Contains(m.approval.editor.Value(), "rm -rf build") {
+		t.Fatalf("expected the arguments in the editor, got %q", m.approval.editor.Value())
+	}
+	m.approval.editor.SetValue(`{"command": "rm -rf build/tmp"}`)
+	_, cmd := m.Update(tuiKey("ctrl+s"))
+	driveTUI(m, cmd)
+
+	d := eng.decisions[0]
+	if d.Kind != api.DecisionModify || d.ToolCallID != "c1" || d.ModifiedArgs["command"] != "rm -rf build/tmp" {
+		t.Fatalf("expected a modify decision, got %+v", d)
+	}
+}
+
+func TestTUIModel_SteersAndStopsThroughTheEngine(t *testing.T) {
+	eng := &scriptedEngine{}
+	m := newTUIModel(context.Background(), eng, "sess_1")
+	m.running = true
+	m.input.SetValue("use tabs instead")
+	m.Update(tuiKey("enter"))
+	if len(eng.steers) != 1 || eng.steers[0] != "use tabs instead" {
+		t.Fatalf("expected the message steered into the turn, got %q", eng.steers)
+	}
+
+	// The engine has no turn to cancel, so the turn's context is cancelled instead.
+	canceled := make(chan struct{})
+	m.turnCancel = func() { close(canceled) }
+	m.stopTurn()
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the turn context cancelled")
+	}
+}
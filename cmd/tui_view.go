@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"AgentEngine/cmd/ui"
+	"AgentEngine/pkg/engine/api"
+	planpkg "AgentEngine/pkg/engine/plan"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// TUI Rendering
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+var (
+	tuiPaneStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+	tuiFocusStyle   = tuiPaneStyle.BorderForeground(lipgloss.Color("63"))
+	tuiWarnStyle    = tuiPaneStyle.BorderForeground(lipgloss.Color("214"))
+	tuiTitleStyle   = lipgloss.NewStyle().Bold(true)
+	tuiDimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	tuiUserStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	tuiErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	tuiSelectStyle  = lipgloss.NewStyle().Reverse(true)
+	tuiAddStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("34"))
+	tuiCommandStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("221"))
+)
+
+// tuiSideWidth is the width of the plan and tool column; narrow terminals hide it.
+func (m *tuiModel) tuiSideWidth() int {
+	if m.width < 90 {
+		return 0
+	}
+	return m.width / 3
+}
+
+// bodyHeight is the height of the panes between the header and the input box.
+func (m *tuiModel) bodyHeight() int {
+	return max(m.height-2-(tuiInputHeight+2), 6)
+}
+
+// layout sizes the viewports and the input after a resize or a pane change.
+func (m *tuiModel) layout() {
+	mainWidth := m.width - m.tuiSideWidth()
+	m.transcript.Width = max(mainWidth-2, 10)
+	m.transcript.Height = m.bodyHeight() - 2
+	m.input.SetWidth(max(m.width-2, 10))
+	if a := m.approval; a != nil {
+		a.diff.Width = m.transcript.Width
+		a.diff.Height = max(m.transcript.Height-len(m.approvalHeader()), 3)
+		if a.editing {
+			a.editor.SetWidth(a.diff.Width)
+			a.editor.SetHeight(a.diff.Height)
+		}
+		m.showApprovalCall()
+	}
+	m.refreshTranscript()
+}
+
+func (m *tuiModel) View() string {
+	header := tuiTitleStyle.Render("sea") + tuiDimStyle.Render(fmt.Sprintf("  %s · %s", m.sessionID, m.status))
+
+	var main string
+	if m.approval != nil {
+		main = tuiWarnStyle.Render(m.approvalView())
+	} else {
+		main = m.paneStyle(focusTranscript).Render(m.transcript.View())
+	}
+	body := main
+	if side := m.tuiSideWidth(); side > 0 {
+		planHeight := 0
+		if m.plan != nil && len(m.plan.Items) > 0 {
+			planHeight = m.bodyHeight() / 2
+		}
+		column := []string{}
+		if planHeight > 0 {
+			column = append(column, tuiPaneStyle.Render(m.planView(side-2, planHeight-2)))
+		}
+		column = append(column, m.paneStyle(focusTools).Render(m.toolsView(side-2, m.bodyHeight()-planHeight-2)))
+		body = lipgloss.JoinHorizontal(lipgloss.Top, main, lipgloss.JoinVertical(lipgloss.Left, column...))
+	}
+
+	input := m.paneStyle(focusInput).Render(m.input.View())
+	return lipgloss.JoinVertical(lipgloss.Left, header, body, input, tuiDimStyle.Render(m.helpLine()))
+}
+
+func (m *tuiModel) paneStyle(f tuiFocus) lipgloss.Style {
+	if m.focus == f && m.approval == nil {
+		return tuiFocusStyle
+	}
+	return tuiPaneStyle
+}
+
+func (m *tuiModel) helpLine() string {
+	switch {
+	case m.approval != nil && m.approval.editing:
+		return "ctrl+s save arguments · esc cancel"
+	case m.approval != nil && len(m.approval.calls) > 1:
+		return "a approve all · r reject all · ↑/↓ select · space toggle · m modify · enter confirm · pgup/pgdn scroll"
+	case m.approval != nil:
+		return "a approve · r reject · m modify · pgup/pgdn scroll · esc stop turn"
+	case m.running:
+		return "enter steer · esc stop · tab focus · pgup/pgdn scroll · ctrl+c quit"
+	}
+	return "enter send · ctrl+j newline · tab focus · pgup/pgdn scroll · ctrl+c quit"
+}
+
+// refreshTranscript re-renders the transcript, staying at the bottom if it was there.
+func (m *tuiModel) refreshTranscript() {
+	follow := m.transcript.AtBottom() || m.transcript.TotalLineCount() <= m.transcript.Height
+	width := m.transcript.Width
+	blocks := make([]string, 0, len(m.entries))
+	for _, e := range m.entries {
+		blocks = append(blocks, renderTUIEntry(e, width))
+	}
+	m.transcript.SetContent(strings.Join(blocks, "\n"))
+	if follow {
+		m.transcript.GotoBottom()
+	}
+}
+
+func renderTUIEntry(e tuiEntry, width int) string {
+	wrap := lipgloss.NewStyle().Width(width)
+	text := strings.TrimRight(e.text, "\n")
+	switch e.kind {
+	case tuiUser:
+		return "\n" + wrap.Inherit(tuiUserStyle).Render("💬 "+text)
+	case tuiAgent:
+		return "\n" + wrap.Render("🤖 "+strings.TrimLeft(text, "\n"))
+	case tuiReasoning:
+		return wrap.Inherit(tuiDimStyle).Render("💭 " + strings.TrimSpace(text))
+	case tuiToolCall, tuiNote:
+		return wrap.Inherit(tuiDimStyle).Render(text)
+	case tuiError:
+		return wrap.Inherit(tuiErrorStyle).Render("❌ " + text)
+	}
+	return wrap.Render(text)
+}
+
+// clipLines fits lines into a width × height box, cutting long lines and the tail.
+func clipLines(lines []string, width, height int) string {
+	if height < 1 {
+		return ""
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	clip := lipgloss.NewStyle().MaxWidth(width)
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = clip.Render(l)
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(strings.Join(out, "\n"))
+}
+
+func (m *tuiModel) planView(width, height int) string {
+	p := m.plan
+	done := planpkg.Counts(p)[api.PlanDone]
+	lines := []string{tuiTitleStyle.Render(fmt.Sprintf("Plan %d/%d", done, len(p.Items)))}
+	lines = append(lines, planTreeLines(p)...)
+	return clipLines(lines, width, height)
+}
+
+// toolsView lists tool calls, newest last, keeping the selected one in view.
+func (m *tuiModel) toolsView(width, height int) string {
+	title := tuiTitleStyle.Render(fmt.Sprintf("Tools (%d)", len(m.tools)))
+	if len(m.tools) == 0 {
+		return clipLines([]string{title, tuiDimStyle.Render("No tool calls yet")}, width, height)
+	}
+
+	var lines []string
+	selectedLine := 0
+	for i, t := range m.tools {
+		mark, arrow := tuiDimStyle.Render("…"), "▸"
+		if t.result != nil && t.result.Status == "error" {
+			mark = tuiErrorStyle.Render("✗")
+		} else if t.result != nil {
+			mark = tuiAddStyle.Render("✓")
+		}
+		if t.expanded {
+			arrow = "▾"
+		}
+		line := fmt.Sprintf("%s %s %s %s", arrow, mark, t.call.ToolName, tuiDimStyle.Render(argsSummary(t.call.Args)))
+		if i == m.toolCursor && m.focus == focusTools {
+			line = tuiSelectStyle.Render(fmt.Sprintf("%s %s %s", arrow, t.call.ToolName, argsSummary(t.call.Args)))
+		}
+		if i == m.toolCursor {
+			selectedLine = len(lines)
+		}
+		lines = append(lines, line)
+		if t.expanded {
+			lines = append(lines, toolDetailLines(t)...)
+		}
+	}
+
+	// Scroll so the selected row stays visible below the title.
+	rows := height - 1
+	start := 0
+	if selectedLine >= rows {
+		start = selectedLine - rows + 1
+	}
+	return clipLines(append([]string{title}, lines[start:]...), width, height)
+}
+
+// toolDetailLines shows an expanded call's arguments and result, indented.
+func toolDetailLines(t *tuiTool) []string {
+	const maxLines = 12
+	var lines []string
+	if len(t.call.Args) > 0 {
+		data, _ := json.MarshalIndent(t.call.Args, "", "  ")
+		lines = append(lines, strings.Split(string(data), "\n")...)
+	}
+	if t.result != nil {
+		content := t.result.Content
+		if t.result.Status == "error" {
+			content = tuiErrorStyle.Render(t.result.Error)
+		}
+		lines = append(lines, "──")
+		lines = append(lines, strings.Split(strings.TrimRight(content, "\n"), "\n")...)
+	}
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines], fmt.Sprintf("… %d more lines", len(lines)-maxLines))
+	}
+	for i, l := range lines {
+		lines[i] = "    " + tuiDimStyle.Render(l)
+	}
+	return lines
+}
+
+// argsSummary shows arguments on one line.
+func argsSummary(args api.Args) string {
+	if len(args) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(args)
+	return truncateSkillStr(string(data), 60)
+}
+
+// approvalHeader lists the calls of the approval, with the selected one marked.
+func (m *tuiModel) approvalHeader() []string {
+	a := m.approval
+	lines := []string{tuiTitleStyle.Render("⚠️  Approval required")}
+	for i, call := range a.calls {
+		box := "[ ]"
+		if a.approved[i] {
+			box = "[x]"
+		}
+		label := call.ToolName
+		if _, ok := a.modified[i]; ok {
+			label += " (modified)"
+		}
+		if call.Preview != nil && call.Preview.Summary != "" {
+			label += ": " + call.Preview.Summary
+		}
+		line := box + " " + label
+		if len(a.calls) == 1 {
+			line = label
+		}
+		if i == a.cursor && len(a.calls) > 1 {
+			line = tuiSelectStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	call := a.calls[a.cursor]
+	if p := call.Preview; p != nil {
+		if p.RiskHint != "" {
+			lines = append(lines, tuiErrorStyle.Render("Risk: "+p.RiskHint))
+		}
+		if len(p.Affected) > 0 {
+			lines = append(lines, tuiDimStyle.Render("Affected: "+strings.Join(p.Affected, ", ")))
+		}
+	}
+	return append(lines, "")
+}
+
+func (m *tuiModel) approvalView() string {
+	a := m.approval
+	width, height := m.transcript.Width, m.transcript.Height
+	lines := m.approvalHeader()
+	if a.editing {
+		if a.editErr != "" {
+			lines = append(lines, tuiErrorStyle.Render(a.editErr))
+		}
+		lines = append(lines, strings.Split(a.editor.View(), "\n")...)
+	} else {
+		lines = append(lines, strings.Split(a.diff.View(), "\n")...)
+	}
+	return clipLines(lines, width, height)
+}
+
+// renderCallPreview renders a call's preview: diffs highlighted, commands and text as
+// is, and the arguments when there is no preview.
+func renderCallPreview(call api.ToolCallPayload, width int) string {
+	p := call.Preview
+	if p == nil || p.Content == "" {
+		data, _ := json.MarshalIndent(call.Args, "", "  ")
+		return string(data)
+	}
+	switch p.Kind {
+	case api.PreviewDiff:
+		return ui.HighlightDiff(strings.TrimRight(p.Content, "\n"))
+	case api.PreviewCommand:
+		return tuiCommandStyle.Render("$ " + p.Content)
+	}
+	return lipgloss.NewStyle().Width(width).Render(p.Content)
+}
@@ -5,33 +5,47 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"AgentEngine/cmd/ui"
 	"AgentEngine/pkg/engine/api"
+	planpkg "AgentEngine/pkg/engine/plan"
+	"AgentEngine/pkg/engine/runtime"
 )
 
+// showReasoning streams model reasoning in full instead of a collapsed one-line preview.
+var showReasoning bool
+
+// lastReasoning holds the reasoning of the most recent model reply, for /reasoning.
+var lastReasoning string
+
 type approvalState struct {
 	autoApproveAll bool
+
+	// skipMemoryReview leaves memory proposals pending instead of prompting.
+	skipMemoryReview bool
 }
 
-func runTurnWithApprovals(ctx context.Context, eng api.Engine, sessionID, message string, approver *ui.CLIApprover, a *approvalState) error {
+func runTurnWithApprovals(ctx context.Context, eng api.Engine, sessionID, message string, approver *ui.CLIApprover, a *approvalState, parts ...api.ContentPart) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	lastReasoning = ""
 
-	stream, err := eng.Send(ctx, sessionID, message)
+	stream, err := sendWithParts(ctx, eng, sessionID, message, parts)
 	if err != nil {
 		return err
 	}
 
+	stop, steer := turnControls(eng, sessionID, cancel)
 	for {
-		pending, err := consumeEventStream(ctx, stream, cancel)
+		pending, err := consumeEventStream(ctx, stream, stop, steer)
 		if err != nil {
 			stream.Close()
 			return err
 		}
 		if pending == nil {
 			stream.Close()
-			return nil
+			return reviewMemoryProposal(ctx, eng, sessionID, approver, a)
 		}
 
 		var decision api.Decision
@@ -70,9 +84,79 @@ func runTurnWithApprovals(ctx context.Context, eng api.Engine, sessionID, messag
 	}
 }
 
-func consumeEventStream(ctx context.Context, stream api.EventStream, cancel context.CancelFunc) (*api.ApprovalPayload, error) {
-	// Start input monitor for cancellation (switch to raw mode)
-	cleanup := monitorCancellation(ctx, cancel)
+// newApprover returns the terminal approver; with the runtime engine, arguments edited
+// with Modify are checked against the tool's schema before the turn resumes.
+func newApprover(eng api.Engine) *ui.CLIApprover {
+	approver := ui.NewCLIApprover()
+	if rt, ok := eng.(*runtime.Engine); ok {
+		approver.ValidateArgs = rt.ValidateToolArgs
+	}
+	return approver
+}
+
+// turnControls returns how the input monitor cancels and steers the running turn. Cancel
+// goes through the engine so the turn records partial output and finishes as canceled;
+// the context is cancelled only when the engine cannot stop the turn.
+func turnControls(eng api.Engine, sessionID string, cancelCtx context.CancelFunc) (func(), func(string)) {
+	stop := func() {
+		// Cancel waits for the turn to finish, which needs the event loop running.
+		go func() {
+			if err := eng.Cancel(sessionID); err != nil && cancelCtx != nil {
+				cancelCtx()
+			}
+		}()
+	}
+	steer := func(message string) {
+		if err := eng.Steer(sessionID, message); err != nil {
+			ui.Printf("❌ %v\r\n", err)
+		}
+	}
+	return stop, steer
+}
+
+// sendWithParts sends message with attachments, which need the runtime engine.
+func sendWithParts(ctx context.Context, eng api.Engine, sessionID, message string, parts []api.ContentPart) (api.EventStream, error) {
+	if len(parts) == 0 {
+		return eng.Send(ctx, sessionID, message)
+	}
+	runtimeEng, ok := eng.(*runtime.Engine)
+	if !ok {
+		return nil, fmt.Errorf("attachments are not supported by this engine")
+	}
+	return runtimeEng.SendParts(ctx, sessionID, message, parts)
+}
+
+// reviewMemoryProposal lets the user accept, edit or discard memories proposed during the turn.
+// Proposals stay pending on the session when the review is skipped.
+func reviewMemoryProposal(ctx context.Context, eng api.Engine, sessionID string, approver *ui.CLIApprover, a *approvalState) error {
+	if a != nil && (a.autoApproveAll || a.skipMemoryReview) {
+		return nil
+	}
+	proposal, err := eng.PendingMemoryProposal(ctx, sessionID)
+	if err != nil || proposal == nil {
+		return err
+	}
+
+	reviews, err := approver.ReviewMemories(ctx, *proposal)
+	if err != nil {
+		return err
+	}
+	if reviews == nil {
+		return nil
+	}
+	stored, err := eng.ResolveMemoryProposal(ctx, sessionID, proposal.RequestID, reviews)
+	if err != nil {
+		return err
+	}
+	if len(stored) > 0 {
+		ui.Printf("🧠 Saved %d memory entries\n", len(stored))
+	}
+	return nil
+}
+
+func consumeEventStream(ctx context.Context, stream api.EventStream, cancel func(), steer func(string)) (*api.ApprovalPayload, error) {
+	// Start input monitor for cancellation and steering (switch to raw mode)
+	cleanup := monitorCancellation(ctx, cancel, steer)
 	defer cleanup()
 
 	stopSpinner, spinnerDone := ui.StartLoading("Thinking...")
@@ -87,8 +171,28 @@ func consumeEventStream(ctx context.Context, stream api.EventStream, cancel cont
 
 	prefixPrinted := false
 	firstEvent := true
+	// Replies render as markdown; anything else printed mid-reply flushes it first.
+	md := ui.NewMarkdownRenderer(func(s string) { ui.Print(s) })
+	defer md.Flush()
 	toolArgBuffer := "" // Buffer for scrolling tool argument display
 
+	// Reasoning renders dimmed: collapsed to a scrolling preview line (expanded with
+	// showReasoning) and summarized once the reply or a tool call starts.
+	var reasoning strings.Builder
+	endReasoning := func() {
+		if reasoning.Len() == 0 {
+			return
+		}
+		lastReasoning = reasoning.String()
+		reasoning.Reset()
+		if showReasoning {
+			ui.Print("\033[0m\n")
+			return
+		}
+		ui.Printf("\r\033[K\033[2m💭 Thought for %d words (/reasoning to expand)\033[0m\n", len(strings.Fields(lastReasoning)))
+	}
+	defer endReasoning()
+
 	for {
 		e, err := stream.Recv(ctx)
 		if err != nil {
@@ -104,8 +208,13 @@ func consumeEventStream(ctx context.Context, stream api.EventStream, cancel cont
 			firstEvent = false
 		}
 
+		if e.Type != api.EventDelta || e.Delta == nil || e.Delta.Source == api.DeltaReasoning || e.Delta.Source == api.DeltaToolArg {
+			md.Flush()
+		}
+
 		switch e.Type {
 		case api.EventThinking:
+			endReasoning()
 			// Keep thinking output lightweight to avoid UI spam.
 			if e.Thinking != nil && strings.TrimSpace(e.Thinking.Message) != "" {
 				ui.Printf("\n🤔 %s\n", e.Thinking.Message)
@@ -116,6 +225,22 @@ func consumeEventStream(ctx context.Context, stream api.EventStream, cancel cont
 				continue
 			}
 			// Style based on delta source
+			if e.Delta.Source == api.DeltaReasoning {
+				if reasoning.Len() == 0 {
+					ui.Print("\n")
+					if showReasoning {
+						ui.Print("\033[2m💭 ")
+					}
+				}
+				reasoning.WriteString(e.Delta.Text)
+				if showReasoning {
+					ui.Print(e.Delta.Text)
+				} else {
+					ui.Printf("\r\033[2m💭 %s\033[0m\033[K", reasoningPreview(reasoning.String(), 70))
+				}
+				continue
+			}
+			endReasoning()
 			switch e.Delta.Source {
 			case api.DeltaToolArg:
 				// Scrolling gray display: append to buffer, show only last ~60 chars
@@ -135,16 +260,18 @@ func consumeEventStream(ctx context.Context, stream api.EventStream, cancel cont
 				}
 				// Normal text
 				if !prefixPrinted {
-					ui.Print("\n🤖 Agent: ")
+					ui.Print("\n")
+					md.Start("🤖 Agent: ")
 					prefixPrinted = true
 				}
-				ui.Print(e.Delta.Text)
+				md.Write(e.Delta.Text)
 			}
 
 		case api.EventToolCall:
 			if e.ToolCall == nil {
 				continue
 			}
+			endReasoning()
 			// Clear tool arg display line
 			if toolArgBuffer != "" {
 				ui.Print("\r\033[K") // Clear the gray line
@@ -173,6 +300,12 @@ func consumeEventStream(ctx context.Context, stream api.EventStream, cancel cont
 			}
 			renderPlan(*e.Plan)
 
+		case api.EventProgressReport:
+			if e.ProgressReport == nil {
+				continue
+			}
+			renderProgressReport(*e.ProgressReport)
+
 		case api.EventApproval:
 			if e.Approval == nil {
 				return nil, fmt.Errorf("approval event missing payload")
@@ -187,29 +320,131 @@ func consumeEventStream(ctx context.Context, stream api.EventStream, cancel cont
 			return nil, fmt.Errorf("unknown error")
 
 		case api.EventDone:
+			endReasoning()
 			if prefixPrinted {
 				ui.Print("\n")
 			}
+			if e.Done != nil && e.Done.Reason == "canceled" {
+				ui.Print("🛑 Turn canceled\n")
+			}
 			return nil, nil
 		}
 	}
 }
 
-func renderPlan(plan api.PlanPayload) {
-	if len(plan.Items) == 0 {
+// reasoningPreview returns the last width runes of the reasoning on one line.
+func reasoningPreview(s string, width int) string {
+	r := []rune(strings.Join(strings.Fields(s), " "))
+	if len(r) <= width {
+		return string(r)
+	}
+	return "..." + string(r[len(r)-width+3:])
+}
+
+func renderPlan(p api.PlanPayload) {
+	if len(p.Items) == 0 {
 		return
 	}
-	total := len(plan.Items)
-	done := 0
-	for _, it := range plan.Items {
-		if it.Status == api.PlanDone {
-			done++
+	total := len(p.Items)
+	done := planpkg.Counts(&p)[api.PlanDone]
+
+	ui.Printf("\n\n🗂️  plan %s (%d/%d done)\n", p.PlanID, done, total)
+	for _, line := range planTreeLines(&p) {
+		ui.Printf("  %s\n", line)
+	}
+	ui.Print("\n")
+}
+
+func renderProgressReport(r api.ProgressReportPayload) {
+	elapsed := time.Duration(r.ElapsedMs) * time.Millisecond
+	ui.Printf("\n\n🏁 Autopilot stopped: %s (%d turns, %s, ~%d tokens)\n", r.Reason, r.Turns, elapsed.Round(time.Second), r.EstimatedTokens)
+	if r.Message != "" {
+		ui.Printf("   %s\n", r.Message)
+	}
+	if r.Plan != nil {
+		renderPlan(*r.Plan)
+	}
+}
+
+// planTreeLines draws plan items as a tree with status marks, dependencies and evidence.
+func planTreeLines(p *api.PlanPayload) []string {
+	var items []api.PlanItem
+	var depths []int
+	planpkg.Walk(p, func(it api.PlanItem, depth int) {
+		items = append(items, it)
+		depths = append(depths, depth)
+	})
+
+	var current int
+	if next := planpkg.Next(p); next != nil {
+		current = next.ID
+	}
+
+	lines := make([]string, 0, len(items))
+	for i, it := range items {
+		var prefix strings.Builder
+		for d := 1; d <= depths[i]; d++ {
+			// Continue the ancestor's rail if it has a later sibling.
+			last := !hasLaterSibling(depths, i, d)
+			switch {
+			case d < depths[i] && last:
+				prefix.WriteString("   ")
+			case d < depths[i]:
+				prefix.WriteString("│  ")
+			case last:
+				prefix.WriteString("└─ ")
+			default:
+				prefix.WriteString("├─ ")
+			}
+		}
+
+		line := fmt.Sprintf("%s%s %d. %s", prefix.String(), planStatusMark(it.Status), it.ID, it.Text)
+		if len(it.DependsOn) > 0 {
+			deps := make([]string, len(it.DependsOn))
+			for j, d := range it.DependsOn {
+				deps[j] = fmt.Sprintf("#%d", d)
+			}
+			line += fmt.Sprintf(" \033[90m(after %s)\033[0m", strings.Join(deps, ", "))
+		}
+		if len(it.Evidence) > 0 {
+			line += fmt.Sprintf(" \033[90m[%d tool calls]\033[0m", len(it.Evidence))
+		}
+		if it.ID == current {
+			line += " ◀"
+		}
+		lines = append(lines, line)
+		if it.Notes != "" && it.Status != api.PlanDone {
+			lines = append(lines, fmt.Sprintf("%s   \033[90m%s\033[0m", strings.Repeat("   ", depths[i]), truncateSkillStr(it.Notes, 80)))
 		}
 	}
+	return lines
+}
 
-	ui.Printf("\n\n🗂️  plan %s (%d/%d done)\n", plan.PlanID, done, total)
-	for _, it := range plan.Items {
-		ui.Printf("  - [%s] %d. %s\n", it.Status, it.ID, it.Text)
+// hasLaterSibling reports whether the ancestor of items[i] at depth d (or items[i] itself
+// when d is its own depth) is followed by another item at that depth before the tree climbs above it.
+func hasLaterSibling(depths []int, i, d int) bool {
+	for j := i + 1; j < len(depths); j++ {
+		if depths[j] < d {
+			return false
+		}
+		if depths[j] == d {
+			return true
+		}
+	}
+	return false
+}
+
+func planStatusMark(s api.PlanItemStatus) string {
+	switch s {
+	case api.PlanDone:
+		return "\033[32m✔\033[0m"
+	case api.PlanRunning:
+		return "\033[33m▶\033[0m"
+	case api.PlanBlocked:
+		return "\033[31m⏸\033[0m"
+	case api.PlanErrored:
+		return "\033[31m✖\033[0m"
+	default:
+		return "○"
 	}
-	ui.Print("\n")
 }
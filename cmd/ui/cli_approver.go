@@ -18,6 +18,9 @@ import (
 type CLIApprover struct {
 	// Reader for input (defaults to os.Stdin)
 	Reader *bufio.Reader
+
+	// ValidateArgs checks arguments edited with Modify before they are sent (optional).
+	ValidateArgs func(toolName string, args api.Args) error
 }
 
 // NewCLIApprover creates a new CLI approver
@@ -34,6 +37,10 @@ func hitlDebugEnabled() bool {
 
 // RequestApproval prompts the user with an interactive approval UI
 func (c *CLIApprover) RequestApproval(ctx context.Context, req api.ApprovalPayload) (api.Decision, bool, error) {
+	if len(req.Items) > 1 {
+		return c.requestBatchApproval(req)
+	}
+
 	// Print approval panel
 	fmt.Println()
 	fmt.Println("\033[33m╭──────────────────────────────────────────────────────────╮\033[0m")
@@ -41,24 +48,48 @@ func (c *CLIApprover) RequestApproval(ctx context.Context, req api.ApprovalPaylo
 	fmt.Println("\033[33m╰──────────────────────────────────────────────────────────╯\033[0m")
 	fmt.Println()
 
+	printToolCall(req.ToolCall)
+	fmt.Println()
+
+	// Try interactive mode first
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		if hitlDebugEnabled() {
+			logger.Info("hitl", "RequestApproval using interactive bubbletea UI")
+		}
+		return c.interactiveApproval(ctx, req)
+	}
+
+	// Fallback to simple prompt
+	if hitlDebugEnabled() {
+		logger.Info("hitl", "RequestApproval using simple stdin prompt (non-tty)")
+	}
+	return c.simpleApproval(ctx, req)
+}
+
+// printToolCall shows the preview of a tool call, or its arguments when it has none.
+func printToolCall(call api.ToolCallPayload) {
 	// Show preview if available
-	if req.ToolCall.Preview != nil {
-		fmt.Printf("\033[1mPreview:\033[0m %s\n", req.ToolCall.Preview.Summary)
-		if req.ToolCall.Preview.RiskHint != "" {
-			fmt.Printf("\033[1mRisk:\033[0m %s\n", req.ToolCall.Preview.RiskHint)
+	if call.Preview != nil {
+		fmt.Printf("\033[1mPreview:\033[0m %s\n", call.Preview.Summary)
+		if call.Preview.RiskHint != "" {
+			fmt.Printf("\033[1mRisk:\033[0m %s\n", call.Preview.RiskHint)
 		}
-		if len(req.ToolCall.Preview.Affected) > 0 {
-			fmt.Printf("\033[1mAffected:\033[0m %s\n", strings.Join(req.ToolCall.Preview.Affected, ", "))
+		if len(call.Preview.Affected) > 0 {
+			fmt.Printf("\033[1mAffected:\033[0m %s\n", strings.Join(call.Preview.Affected, ", "))
 		}
-		if req.ToolCall.Preview.Content != "" {
+		if call.Preview.Content != "" {
 			fmt.Println()
-			fmt.Println(req.ToolCall.Preview.Content)
+			if call.Preview.Kind == api.PreviewDiff {
+				fmt.Println(HighlightDiff(call.Preview.Content))
+			} else {
+				fmt.Println(call.Preview.Content)
+			}
 		}
 	} else {
-		fmt.Printf("\033[1mTool:\033[0m %s\n", req.ToolCall.ToolName)
-		if len(req.ToolCall.Args) > 0 {
+		fmt.Printf("\033[1mTool:\033[0m %s\n", call.ToolName)
+		if len(call.Args) > 0 {
 			fmt.Println("\033[1mArguments:\033[0m")
-			for k, v := range req.ToolCall.Args {
+			for k, v := range call.Args {
 				vStr := fmt.Sprintf("%v", v)
 				if len(vStr) > 100 {
 					vStr = vStr[:100] + "..."
@@ -67,26 +98,10 @@ func (c *CLIApprover) RequestApproval(ctx context.Context, req api.ApprovalPaylo
 			}
 		}
 	}
-
-	fmt.Println()
-
-	// Try interactive mode first
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		if hitlDebugEnabled() {
-			logger.Info("hitl", "RequestApproval using interactive bubbletea UI")
-		}
-		return c.interactiveApproval(req)
-	}
-
-	// Fallback to simple prompt
-	if hitlDebugEnabled() {
-		logger.Info("hitl", "RequestApproval using simple stdin prompt (non-tty)")
-	}
-	return c.simpleApproval(req)
 }
 
 // interactiveApproval uses bubbletea for selection
-func (c *CLIApprover) interactiveApproval(req api.ApprovalPayload) (api.Decision, bool, error) {
+func (c *CLIApprover) interactiveApproval(ctx context.Context, req api.ApprovalPayload) (api.Decision, bool, error) {
 	if hitlDebugEnabled() {
 		logger.Info("hitl", "interactiveApproval start", map[string]interface{}{"request_id": req.RequestID, "tool_call_id": req.ToolCallID})
 	}
@@ -99,7 +114,7 @@ func (c *CLIApprover) interactiveApproval(req api.ApprovalPayload) (api.Decision
 		if hitlDebugEnabled() {
 			logger.Info("hitl", "interactiveApproval failed; falling back to simple prompt", map[string]interface{}{"err": err.Error()})
 		}
-		return c.simpleApproval(req)
+		return c.simpleApproval(ctx, req)
 	}
 
 	m, ok := finalModel.(approvalModel)
@@ -117,7 +132,7 @@ func (c *CLIApprover) interactiveApproval(req api.ApprovalPayload) (api.Decision
 	if hitlDebugEnabled() {
 		logger.Info("hitl", "interactiveApproval selected", map[string]interface{}{"selected": m.selected})
 	}
-	return c.makeDecision(req, m.selected)
+	return c.makeDecision(ctx, req, m.selected)
 }
 
 // approvalModel is the bubbletea model for the approval prompt
@@ -132,7 +147,7 @@ type approvalModel struct {
 func initialApprovalModel(req api.ApprovalPayload) approvalModel {
 	return approvalModel{
 		req:      req,
-		options:  []string{"Approve", "Reject", "Auto-approve all"},
+		options:  []string{"Approve", "Reject", "Auto-approve all", "Modify in editor"},
 		selected: 0,
 	}
 }
@@ -171,6 +186,10 @@ func (m approvalModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.selected = 1
 			m.chosen = true
 			return m, tea.Quit
+		case "m", "M", "e", "E":
+			m.selected = 3
+			m.chosen = true
+			return m, tea.Quit
 		}
 	}
 	return m, nil
@@ -200,6 +219,8 @@ func (m approvalModel) View() string {
 				line = fmt.Sprintf("%s \033[1;31m%s %s\033[0m", cursor, checked, opt)
 			case 2:
 				line = fmt.Sprintf("%s \033[1;34m%s %s\033[0m", cursor, checked, opt)
+			case 3:
+				line = fmt.Sprintf("%s \033[1;33m%s %s\033[0m", cursor, checked, opt)
 			default:
 				line = fmt.Sprintf("%s %s %s", cursor, checked, opt)
 			}
@@ -213,7 +234,7 @@ func (m approvalModel) View() string {
 	return s.String()
 }
 
-func (c *CLIApprover) makeDecision(req api.ApprovalPayload, selected int) (api.Decision, bool, error) {
+func (c *CLIApprover) makeDecision(ctx context.Context, req api.ApprovalPayload, selected int) (api.Decision, bool, error) {
 	switch selected {
 	case 0:
 		fmt.Println("\033[32m✓ Approved\033[0m")
@@ -236,6 +257,8 @@ func (c *CLIApprover) makeDecision(req api.ApprovalPayload, selected int) (api.D
 			RequestID:  req.RequestID,
 			ToolCallID: req.ToolCallID,
 		}, true, nil
+	case 3:
+		return c.modifyDecision(ctx, req)
 	}
 	return api.Decision{
 		Kind:       api.DecisionReject,
@@ -244,10 +267,30 @@ func (c *CLIApprover) makeDecision(req api.ApprovalPayload, selected int) (api.D
 	}, false, nil
 }
 
+// modifyDecision opens the call's arguments in the editor and approves the call with
+// the edited ones. Without an edit the approval prompt is shown again.
+func (c *CLIApprover) modifyDecision(ctx context.Context, req api.ApprovalPayload) (api.Decision, bool, error) {
+	args, note, ok, err := c.modifyArgs(req.ToolCall)
+	if err != nil {
+		fmt.Printf("\033[31m✗ %v\033[0m\n", err)
+	}
+	if err != nil || !ok {
+		return c.RequestApproval(ctx, req)
+	}
+	fmt.Println("\033[33m✎ Approved with modified arguments\033[0m")
+	return api.Decision{
+		Kind:         api.DecisionModify,
+		RequestID:    req.RequestID,
+		ToolCallID:   req.ToolCallID,
+		ModifiedArgs: args,
+		Note:         note,
+	}, false, nil
+}
+
 // simpleApproval for non-interactive terminals
-func (c *CLIApprover) simpleApproval(req api.ApprovalPayload) (api.Decision, bool, error) {
-	fmt.Println("  (A)pprove  |  (R)eject  |  Auto-approve (all)")
-	fmt.Print("\nChoice [A/r/all]: ")
+func (c *CLIApprover) simpleApproval(ctx context.Context, req api.ApprovalPayload) (api.Decision, bool, error) {
+	fmt.Println("  (A)pprove  |  (R)eject  |  (M)odify in editor  |  Auto-approve (all)")
+	fmt.Print("\nChoice [A/r/m/all]: ")
 
 	input, err := c.Reader.ReadString('\n')
 	if err != nil {
@@ -282,6 +325,8 @@ func (c *CLIApprover) simpleApproval(req api.ApprovalPayload) (api.Decision, boo
 			RequestID:  req.RequestID,
 			ToolCallID: req.ToolCallID,
 		}, true, nil
+	case "m", "modify", "e", "edit":
+		return c.modifyDecision(ctx, req)
 	default:
 		fmt.Println("\033[33m? Defaulting to Approve\033[0m")
 		return api.Decision{
@@ -291,3 +336,158 @@ func (c *CLIApprover) simpleApproval(req api.ApprovalPayload) (api.Decision, boo
 		}, false, nil
 	}
 }
+
+// requestBatchApproval shows a checklist for the tool calls of one assistant message;
+// each call is approved or rejected individually.
+func (c *CLIApprover) requestBatchApproval(req api.ApprovalPayload) (api.Decision, bool, error) {
+	fmt.Println()
+	fmt.Println("\033[33m╭──────────────────────────────────────────────────────────╮\033[0m")
+	fmt.Printf("\033[33m│\033[0m  \033[1;33m⚠️  %-2d Tool Actions Require Approval\033[0m                     \033[33m│\033[0m\n", len(req.Items))
+	fmt.Println("\033[33m╰──────────────────────────────────────────────────────────╯\033[0m")
+	for i, call := range req.Items {
+		fmt.Printf("\n\033[1m%d.\033[0m ", i+1)
+		printToolCall(call)
+	}
+	fmt.Println()
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		model := batchApprovalModel{items: req.Items, approved: make([]bool, len(req.Items))}
+		for i := range model.approved {
+			model.approved[i] = true
+		}
+		finalModel, err := tea.NewProgram(model).Run()
+		if err == nil {
+			m, ok := finalModel.(batchApprovalModel)
+			if !ok || m.cancelled {
+				return batchDecision(req, make([]bool, len(req.Items))), false, nil
+			}
+			return batchDecision(req, m.approved), false, nil
+		}
+		if hitlDebugEnabled() {
+			logger.Info("hitl", "batch approval UI failed; falling back to simple prompt", map[string]interface{}{"err": err.Error()})
+		}
+	}
+
+	fmt.Println("  Approve which? all  |  none  |  numbers (e.g. 1,3)  |  auto (all, and future actions)")
+	fmt.Print("\nChoice [all]: ")
+	input, err := c.Reader.ReadString('\n')
+	if err != nil {
+		return batchDecision(req, make([]bool, len(req.Items))), false, err
+	}
+	input = strings.TrimSpace(strings.ToLower(input))
+	if input == "auto" {
+		fmt.Println("\033[34m✓ Auto-approving all future actions\033[0m")
+		return batchDecision(req, parseBatchSelection("all", len(req.Items))), true, nil
+	}
+	return batchDecision(req, parseBatchSelection(input, len(req.Items))), false, nil
+}
+
+// parseBatchSelection turns "all", "none" or a list of 1-based numbers into the
+// approved flags of n items. Anything else approves all, like the single prompt.
+func parseBatchSelection(input string, n int) []bool {
+	approved := make([]bool, n)
+	switch input {
+	case "none", "r", "reject", "n", "no":
+		return approved
+	case "", "all", "a", "approve", "y", "yes":
+		for i := range approved {
+			approved[i] = true
+		}
+		return approved
+	}
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		var i int
+		if _, err := fmt.Sscanf(field, "%d", &i); err != nil || i < 1 || i > n {
+			fmt.Println("\033[33m? Defaulting to Approve all\033[0m")
+			return parseBatchSelection("all", n)
+		}
+		approved[i-1] = true
+	}
+	return approved
+}
+
+// batchDecision builds a per-item decision from the approved flags.
+func batchDecision(req api.ApprovalPayload, approved []bool) api.Decision {
+	decision := api.Decision{Kind: api.DecisionReject, RequestID: req.RequestID}
+	count := 0
+	for i, call := range req.Items {
+		kind := api.DecisionReject
+		if approved[i] {
+			kind = api.DecisionApprove
+			decision.Kind = api.DecisionApprove
+			count++
+		}
+		decision.Items = append(decision.Items, api.ItemDecision{ToolCallID: call.ToolCallID, Kind: kind})
+	}
+	switch count {
+	case len(req.Items):
+		fmt.Println("\033[32m✓ Approved all\033[0m")
+	case 0:
+		fmt.Println("\033[31m✗ Rejected all\033[0m")
+	default:
+		fmt.Printf("\033[33m✓ Approved %d of %d\033[0m\n", count, len(req.Items))
+	}
+	return decision
+}
+
+// batchApprovalModel is the bubbletea checklist for a batch approval
+type batchApprovalModel struct {
+	items     []api.ToolCallPayload
+	approved  []bool
+	cursor    int
+	cancelled bool
+}
+
+func (m batchApprovalModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m batchApprovalModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c", "q":
+		m.cancelled = true
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = (m.cursor + len(m.items) - 1) % len(m.items)
+	case "down", "j":
+		m.cursor = (m.cursor + 1) % len(m.items)
+	case " ", "x":
+		m.approved[m.cursor] = !m.approved[m.cursor]
+	case "a", "A":
+		for i := range m.approved {
+			m.approved[i] = true
+		}
+	case "r", "R":
+		for i := range m.approved {
+			m.approved[i] = false
+		}
+	case "enter":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m batchApprovalModel) View() string {
+	s := strings.Builder{}
+	for i, call := range m.items {
+		cursor := " "
+		if m.cursor == i {
+			cursor = "❯"
+		}
+		label := call.ToolName
+		if call.Preview != nil && call.Preview.Summary != "" {
+			label += ": " + call.Preview.Summary
+		}
+		if m.approved[i] {
+			s.WriteString(fmt.Sprintf("%s \033[1;32m☑ %d. %s\033[0m\n", cursor, i+1, label))
+		} else {
+			s.WriteString(fmt.Sprintf("%s \033[2m☐ %d. %s\033[0m\n", cursor, i+1, label))
+		}
+	}
+	s.WriteString("\n\033[2mspace toggle · a approve all · r reject all · enter confirm\033[0m\n")
+	return s.String()
+}
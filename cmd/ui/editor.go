@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rawArgFields names the argument that Modify opens as plain text for file-writing
+// tools; other tools open all their arguments as YAML.
+var rawArgFields = map[string]string{
+	"write_file": "content",
+	"edit_file":  "new_text",
+}
+
+// editorCommand returns $VISUAL or $EDITOR split into words (so "code --wait" works),
+// or the platform's default editor.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// openInEditor writes text to a temporary file named after pattern, opens it in the
+// editor and returns the saved text.
+func openInEditor(text, pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	_, werr := f.WriteString(text)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return "", werr
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s: %w", editor[0], err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// argsEditText returns the text Modify opens for a call and the temp file pattern.
+func argsEditText(call api.ToolCallPayload) (string, string, error) {
+	if field := rawArgFields[call.ToolName]; field != "" {
+		if text, ok := call.Args[field].(string); ok {
+			ext := ""
+			if path, ok := call.Args["path"].(string); ok {
+				ext = filepath.Ext(path)
+			}
+			return text, "sea-" + call.ToolName + "-*" + ext, nil
+		}
+	}
+	data, err := yaml.Marshal(call.Args)
+	if err != nil {
+		return "", "", err
+	}
+	header := fmt.Sprintf("# Arguments of %s. Save and close the editor to continue; JSON works too.\n", call.ToolName)
+	return header + string(data), "sea-" + call.ToolName + "-*.yaml", nil
+}
+
+// parseEditedArgs turns the edited text back into the call's arguments.
+func parseEditedArgs(call api.ToolCallPayload, original, edited string) (api.Args, error) {
+	if field := rawArgFields[call.ToolName]; field != "" {
+		if _, ok := call.Args[field].(string); ok {
+			// Editors add a final newline; keep the original's ending.
+			if !strings.HasSuffix(original, "\n") {
+				edited = strings.TrimSuffix(strings.TrimSuffix(edited, "\n"), "\r")
+			}
+			args := make(api.Args, len(call.Args))
+			for k, v := range call.Args {
+				args[k] = v
+			}
+			args[field] = edited
+			return args, nil
+		}
+	}
+	var parsed api.Args
+	if err := yaml.Unmarshal([]byte(edited), &parsed); err != nil {
+		return nil, err
+	}
+	// Round-trip through JSON so values have the types the model's arguments have.
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, err
+	}
+	args := api.Args{}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// modifyArgs lets the user edit a call's arguments in their editor. Edited arguments
+// are checked with ValidateArgs; on a problem the user can edit again. It returns the
+// new arguments and an optional note for the model, or false when the user gave up or
+// changed nothing.
+func (c *CLIApprover) modifyArgs(call api.ToolCallPayload) (api.Args, string, bool, error) {
+	text, pattern, err := argsEditText(call)
+	if err != nil {
+		return nil, "", false, err
+	}
+	original := text
+	for {
+		edited, err := openInEditor(text, pattern)
+		if err != nil {
+			return nil, "", false, err
+		}
+		args, err := parseEditedArgs(call, original, edited)
+		if err == nil && c.ValidateArgs != nil {
+			err = c.ValidateArgs(call.ToolName, args)
+		}
+		if err == nil {
+			if reflect.DeepEqual(args, call.Args) {
+				fmt.Println("\033[33mArguments unchanged\033[0m")
+				return nil, "", false, nil
+			}
+			fmt.Print("Note for the agent (optional, Enter to skip): ")
+			note, _ := c.Reader.ReadString('\n')
+			return args, strings.TrimSpace(note), true, nil
+		}
+
+		fmt.Printf("\033[31m✗ Invalid arguments: %v\033[0m\n", err)
+		fmt.Print("Edit again? [Y/n]: ")
+		answer, rerr := c.Reader.ReadString('\n')
+		if rerr != nil || strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			return nil, "", false, nil
+		}
+		text = edited
+	}
+}
@@ -3,7 +3,10 @@ package ui
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
@@ -27,6 +30,19 @@ type Command struct {
 var DefaultCommands = []Command{
 	{"/compress", "Compress conversation history, keep last 3 turns"},
 	{"/init", "Initialize persona templates (project/local)"},
+	{"/memory", "List, search, remove or review memories"},
+	{"/attach", "Attach an image or file to the next message"},
+	{"/skill", "Show or switch the skill (name, off, auto)"},
+	{"/mode", "Switch approval mode (suggest, auto, full-auto)"},
+	{"/model", "Show or switch the model"},
+	{"/plan", "Show the session's plan"},
+	{"/usage", "Show token usage and cost"},
+	{"/diff", "Show files changed this session"},
+	{"/export", "Save the transcript as markdown or JSON"},
+	{"/sessions", "List sessions"},
+	{"/resume", "Switch to another session"},
+	{"/clear", "Start a fresh session with the same settings"},
+	{"/reasoning", "Show the reasoning behind the last reply"},
 	{"/help", "Show help"},
 	{"/quit", "Quit session"},
 }
@@ -46,6 +62,45 @@ type inputModel struct {
 	commands     []Command
 	showCommands bool
 	selectedCmd  int
+
+	// @path completion
+	paths           *PathIndex
+	mentions        []string
+	showMentions    bool
+	selectedMention int
+}
+
+// maxMentionChoices bounds the @path completion menu.
+const maxMentionChoices = 8
+
+// mentionRe finds @path mentions: an @ at the start or after whitespace.
+var mentionRe = regexp.MustCompile(`(^|\s)@(\S+)`)
+
+// Mentions returns the paths mentioned with @ in text, in order and without
+// duplicates. Trailing punctuation is left to the caller to interpret.
+func Mentions(text string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, m := range mentionRe.FindAllStringSubmatch(text, -1) {
+		if !seen[m[2]] {
+			seen[m[2]] = true
+			out = append(out, m[2])
+		}
+	}
+	return out
+}
+
+// mentionQuery returns the @mention being typed at the end of val, if any.
+func mentionQuery(val string) (string, bool) {
+	if val == "" || strings.TrimRightFunc(val, unicode.IsSpace) != val {
+		return "", false
+	}
+	i := strings.LastIndexFunc(val, unicode.IsSpace)
+	word := val[i+1:]
+	if !strings.HasPrefix(word, "@") {
+		return "", false
+	}
+	return word[1:], true
 }
 
 // NewInputModel creates a new input model with optional prompt
@@ -82,6 +137,31 @@ func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Handle @path completion navigation
+		if m.showMentions {
+			switch msg.Type {
+			case tea.KeyUp:
+				if m.selectedMention > 0 {
+					m.selectedMention--
+				}
+				return m, nil
+			case tea.KeyDown:
+				if m.selectedMention < len(m.mentions)-1 {
+					m.selectedMention++
+				}
+				return m, nil
+			case tea.KeyTab, tea.KeyEnter:
+				// Enter on a path typed out in full sends the message.
+				if query, _ := mentionQuery(m.textarea.Value()); msg.Type == tea.KeyTab || !slices.Contains(m.mentions, query) {
+					m.completeMention()
+					return m, nil
+				}
+			case tea.KeyEsc:
+				m.showMentions = false
+				return m, nil
+			}
+		}
+
 		// Handle command completion navigation
 		if m.showCommands {
 			switch msg.Type {
@@ -170,10 +250,46 @@ func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	} else {
 		m.showCommands = false
 	}
+	m.updateMentions()
 
 	return m, tea.Batch(cmds...)
 }
 
+// updateMentions offers workspace paths while an @mention is being typed.
+func (m *inputModel) updateMentions() {
+	query, ok := mentionQuery(m.textarea.Value())
+	if !ok || m.paths == nil {
+		m.showMentions = false
+		return
+	}
+	m.mentions = slices.DeleteFunc(m.paths.Match(query, maxMentionChoices+1), func(p string) bool {
+		// A completed directory lists what is inside it, not itself.
+		return p == query && strings.HasSuffix(p, "/")
+	})
+	m.mentions = m.mentions[:min(len(m.mentions), maxMentionChoices)]
+	m.showMentions = len(m.mentions) > 0
+	if m.selectedMention >= len(m.mentions) {
+		m.selectedMention = 0
+	}
+}
+
+// completeMention replaces the mention being typed with the selected path. A file ends
+// the mention; a directory keeps completing inside it.
+func (m *inputModel) completeMention() {
+	if len(m.mentions) == 0 {
+		return
+	}
+	val := m.textarea.Value()
+	choice := m.mentions[m.selectedMention]
+	val = val[:strings.LastIndex(val, "@")+1] + choice
+	if !strings.HasSuffix(choice, "/") {
+		val += " "
+	}
+	m.textarea.SetValue(val)
+	m.selectedMention = 0
+	m.updateMentions()
+}
+
 // filterCommands returns commands matching the prefix
 func filterCommands(cmds []Command, prefix string) []Command {
 	if prefix == "/" {
@@ -199,6 +315,30 @@ func (m inputModel) View() string {
 	// Textarea
 	b.WriteString(m.textarea.View())
 
+	// @path completion menu
+	if m.showMentions {
+		b.WriteString("\n")
+		var menu strings.Builder
+		for i, p := range m.mentions {
+			if i == m.selectedMention {
+				menu.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("86")).Render("> @" + p))
+			} else {
+				menu.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render("  @" + p))
+			}
+			if i < len(m.mentions)-1 {
+				menu.WriteString("\n")
+			}
+		}
+		b.WriteString(lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(0, 1).
+			Render(menu.String()))
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("↑↓ Select | Tab/Enter Complete | Esc Close"))
+		return b.String()
+	}
+
 	// Command completion menu
 	if m.showCommands && len(m.commands) > 0 {
 		b.WriteString("\n")
@@ -285,10 +425,12 @@ func ReadInput(prompt string) (InputResult, error) {
 	}, nil
 }
 
-// ReadInputWithHistory reads input with previously entered values available
-func ReadInputWithHistory(prompt string, history []string) (InputResult, error) {
+// ReadInputWithHistory reads input with previously entered values available. With
+// paths, typing @ completes workspace paths (see Mentions).
+func ReadInputWithHistory(prompt string, history []string, paths *PathIndex) (InputResult, error) {
 	m := newInputModel(prompt, "Type a message...")
 	m.history = append([]string(nil), history...)
+	m.paths = paths
 	p := tea.NewProgram(m)
 
 	finalModel, err := p.Run()
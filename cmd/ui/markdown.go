@@ -0,0 +1,448 @@
+package ui
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Markdown Rendering
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+const (
+	ansiReset     = "\033[0m"
+	ansiBold      = "\033[1m"
+	ansiDim       = "\033[2m"
+	ansiItalic    = "\033[3m"
+	ansiUnderline = "\033[4m"
+	ansiRed       = "\033[31m"
+	ansiGreen     = "\033[32m"
+	ansiYellow    = "\033[33m"
+	ansiBlue      = "\033[34m"
+	ansiMagenta   = "\033[35m"
+	ansiCyan      = "\033[36m"
+	ansiGray      = "\033[90m"
+)
+
+// ColorEnabled reports whether output may be styled: stdout is a terminal and NO_COLOR
+// is not set.
+func ColorEnabled() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// MarkdownRenderer renders streamed markdown. Text shows as it arrives; once a line is
+// complete it is redrawn styled (headings, lists, quotes, inline code and emphasis,
+// code blocks highlighted by language). Table rows are held until the table ends so
+// the columns can be aligned. Without color the text passes through unchanged.
+type MarkdownRenderer struct {
+	out   func(string)
+	color bool
+	width int // terminal width; 0 when unknown
+
+	lead  string // text before the current line on the same terminal row
+	line  string // the current, incomplete line
+	shown int    // bytes of line already printed raw
+
+	inFence   bool
+	fence     string // the opening fence marker (``` or ~~~)
+	fenceLang string
+	table     []string
+}
+
+// NewMarkdownRenderer returns a renderer that writes to out (e.g. Print), styled when
+// ColorEnabled.
+func NewMarkdownRenderer(out func(string)) *MarkdownRenderer {
+	r := &MarkdownRenderer{out: out, color: ColorEnabled()}
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		r.width = w
+	}
+	return r
+}
+
+// Start prints prefix at the start of a reply; it stays in front of the first line.
+func (r *MarkdownRenderer) Start(prefix string) {
+	r.out(prefix)
+	r.lead = prefix
+}
+
+// Write renders the next chunk of streamed text.
+func (r *MarkdownRenderer) Write(text string) {
+	for text != "" {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			r.line += text
+			r.showPartial()
+			return
+		}
+		r.line += strings.TrimSuffix(text[:i], "\r")
+		text = text[i+1:]
+		r.completeLine(true)
+	}
+}
+
+// Flush ends the reply: the last line is styled and any held table printed. The
+// next Write starts a fresh reply.
+func (r *MarkdownRenderer) Flush() {
+	if r.line != "" || r.shown > 0 {
+		r.completeLine(false)
+	}
+	r.flushTable()
+	r.inFence, r.fence, r.fenceLang = false, "", ""
+	r.lead = ""
+}
+
+func (r *MarkdownRenderer) showPartial() {
+	if r.color && !r.inFence {
+		if isTableRow(r.line) {
+			return
+		}
+		if len(r.table) > 0 && strings.TrimSpace(r.line) != "" {
+			r.flushTable()
+		}
+	}
+	if r.shown < len(r.line) {
+		r.out(r.line[r.shown:])
+		r.shown = len(r.line)
+	}
+}
+
+func (r *MarkdownRenderer) completeLine(newline bool) {
+	line := r.line
+	end := ""
+	if newline {
+		end = "\n"
+	}
+	defer func() {
+		r.line, r.shown, r.lead = "", 0, ""
+	}()
+
+	if !r.color {
+		r.out(line[r.shown:] + end)
+		return
+	}
+	if !r.inFence && isTableRow(line) && r.shown == 0 {
+		r.table = append(r.table, line)
+		return
+	}
+	r.flushTable()
+
+	styled := r.renderLine(line)
+	switch {
+	case r.shown == 0:
+		r.out(styled + end)
+	case r.width > 0 && lipgloss.Width(r.lead+line) < r.width:
+		// The raw text fits on one row: redraw it styled.
+		r.out("\r\033[K" + r.lead + styled + end)
+	default:
+		// It wrapped; leave it as printed.
+		r.out(line[r.shown:] + end)
+	}
+}
+
+var (
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	ruleRe     = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	bulletRe   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	quoteRe    = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	boldRe     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicRe   = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*`)
+	linkRe     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	tableSepRe = regexp.MustCompile(`^\s*:?-+:?\s*$`)
+)
+
+// renderLine styles one complete line, tracking code fences.
+func (r *MarkdownRenderer) renderLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if r.inFence {
+		if strings.HasPrefix(trimmed, r.fence) && strings.Trim(trimmed, r.fence[:1]) == "" {
+			r.inFence = false
+			return ansiGray + "└─" + ansiReset
+		}
+		return highlightCode(line, r.fenceLang)
+	}
+	if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+		r.inFence, r.fence = true, trimmed[:3]
+		r.fenceLang = strings.ToLower(strings.TrimSpace(strings.Trim(trimmed, "`~")))
+		return ansiGray + "┌─ " + r.fenceLang + ansiReset
+	}
+
+	if m := headingRe.FindStringSubmatch(trimmed); m != nil {
+		style := ansiBold + ansiCyan
+		if len(m[1]) == 1 {
+			style += ansiUnderline
+		}
+		return style + m[2] + ansiReset
+	}
+	if ruleRe.MatchString(line) {
+		return ansiGray + strings.Repeat("─", 40) + ansiReset
+	}
+	if m := quoteRe.FindStringSubmatch(line); m != nil {
+		return ansiGray + "│ " + ansiReset + ansiItalic + renderInline(m[1]) + ansiReset
+	}
+	if m := bulletRe.FindStringSubmatch(line); m != nil {
+		return m[1] + ansiCyan + "•" + ansiReset + " " + renderInline(m[2])
+	}
+	return renderInline(line)
+}
+
+// renderInline styles inline code, bold, italics and links; code spans are left as is.
+func renderInline(s string) string {
+	parts := strings.Split(s, "`")
+	if len(parts)%2 == 0 {
+		// An unmatched backtick: treat it as text.
+		parts[len(parts)-2] += "`" + parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+	var b strings.Builder
+	for i, p := range parts {
+		if i%2 == 1 {
+			b.WriteString(ansiYellow + p + ansiReset)
+			continue
+		}
+		p = linkRe.ReplaceAllString(p, ansiUnderline+"$1"+ansiReset+ansiGray+" ($2)"+ansiReset)
+		p = boldRe.ReplaceAllString(p, ansiBold+"$1$2"+ansiReset)
+		p = italicRe.ReplaceAllString(p, "$1"+ansiItalic+"$2"+ansiReset)
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+// flushTable prints the held table rows with aligned columns.
+func (r *MarkdownRenderer) flushTable() {
+	if len(r.table) == 0 {
+		return
+	}
+	rows := make([][]string, 0, len(r.table))
+	sep := -1
+	var widths []int
+	for _, line := range r.table {
+		cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+		isSep := true
+		for i, c := range cells {
+			c = strings.TrimSpace(c)
+			if !tableSepRe.MatchString(c) {
+				isSep = false
+			}
+			cells[i] = renderInline(c)
+		}
+		if isSep && sep < 0 && len(rows) == 1 {
+			sep = len(rows)
+			rows = append(rows, nil)
+			continue
+		}
+		for i, c := range cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(c))
+		}
+		rows = append(rows, cells)
+	}
+	r.table = nil
+
+	var b strings.Builder
+	for i, cells := range rows {
+		if cells == nil {
+			segs := make([]string, len(widths))
+			for j, w := range widths {
+				segs[j] = strings.Repeat("─", w)
+			}
+			b.WriteString(ansiGray + strings.Join(segs, "─┼─") + ansiReset + "\n")
+			continue
+		}
+		for j, w := range widths {
+			c := ""
+			if j < len(cells) {
+				c = cells[j]
+			}
+			if i == 0 && sep == 1 {
+				c = ansiBold + c + ansiReset
+			}
+			if j > 0 {
+				b.WriteString(ansiGray + " │ " + ansiReset)
+			}
+			b.WriteString(c + strings.Repeat(" ", w-lipgloss.Width(c)))
+		}
+		b.WriteString("\n")
+	}
+	r.out(b.String())
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Syntax Highlighting
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// codeLang describes what the line highlighter needs to know about a language.
+type codeLang struct {
+	comments []string
+	keywords map[string]bool
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	cLikeComments = []string{"//"}
+	hashComments  = []string{"#"}
+
+	goLang = codeLang{cLikeComments, words(`break case chan const continue default defer else fallthrough for func go goto
+		if import interface map package range return select struct switch type var nil true false iota`)}
+	pyLang = codeLang{hashComments, words(`and as assert async await break class continue def del elif else except finally
+		for from global if import in is lambda None nonlocal not or pass raise return True False try while with yield`)}
+	jsLang = codeLang{cLikeComments, words(`async await break case catch class const continue default delete do else export
+		extends false finally for from function if import in instanceof interface let new null return static super switch
+		this throw true try type typeof undefined var void while yield`)}
+	shLang = codeLang{hashComments, words(`if then else elif fi for while until do done case esac in function return
+		export local echo exit set unset cd source`)}
+	rustLang = codeLang{cLikeComments, words(`as async await break const continue crate else enum extern false fn for if
+		impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while`)}
+	cLang = codeLang{cLikeComments, words(`auto break case char class const continue default do double else enum extern
+		false final float for if import int long new null private protected public return short static struct switch
+		this throw true try typedef union unsigned void volatile while`)}
+	sqlLang = codeLang{[]string{"--"}, words(`select from where and or not insert into values update set delete create
+		table drop alter join left right inner outer on group by order having limit as null is in like distinct`)}
+	dataLang = codeLang{hashComments, words(`true false null yes no`)}
+
+	codeLangs = map[string]codeLang{
+		"go": goLang, "golang": goLang,
+		"python": pyLang, "py": pyLang,
+		"javascript": jsLang, "js": jsLang, "jsx": jsLang, "typescript": jsLang, "ts": jsLang, "tsx": jsLang,
+		"sh": shLang, "bash": shLang, "shell": shLang, "zsh": shLang, "console": shLang,
+		"rust": rustLang, "rs": rustLang,
+		"c": cLang, "cpp": cLang, "c++": cLang, "java": cLang, "csharp": cLang, "cs": cLang, "kotlin": cLang,
+		"sql":  sqlLang,
+		"json": dataLang, "yaml": dataLang, "yml": dataLang, "toml": dataLang,
+	}
+)
+
+// HighlightCode colors code by language: keywords, strings, numbers and line comments.
+// Unknown languages get strings and numbers only; without color the code is returned
+// unchanged.
+func HighlightCode(code, lang string) string {
+	if !ColorEnabled() {
+		return code
+	}
+	return highlightCode(code, lang)
+}
+
+func highlightCode(code, lang string) string {
+	l := codeLangs[strings.ToLower(lang)]
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = highlightLine(line, l, lang == "sql")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func highlightLine(line string, l codeLang, foldCase bool) string {
+	var b strings.Builder
+	rs := []rune(line)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		rest := string(rs[i:])
+
+		if comment := lineCommentAt(rest, l.comments, i == 0 || !isWordRune(rs[i-1])); comment {
+			b.WriteString(ansiGray + rest + ansiReset)
+			break
+		}
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for j < len(rs) && rs[j] != c {
+				if rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(rs))
+			b.WriteString(ansiGreen + string(rs[i:j]) + ansiReset)
+			i = j
+			continue
+		case c >= '0' && c <= '9' && (i == 0 || !isWordRune(rs[i-1])):
+			j := i
+			for j < len(rs) && (isWordRune(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			b.WriteString(ansiMagenta + string(rs[i:j]) + ansiReset)
+			i = j
+			continue
+		case isWordRune(c):
+			j := i
+			for j < len(rs) && isWordRune(rs[j]) {
+				j++
+			}
+			word := string(rs[i:j])
+			key := word
+			if foldCase {
+				key = strings.ToLower(word)
+			}
+			if l.keywords[key] {
+				b.WriteString(ansiBlue + word + ansiReset)
+			} else {
+				b.WriteString(word)
+			}
+			i = j
+			continue
+		}
+		b.WriteRune(c)
+		i++
+	}
+	return b.String()
+}
+
+// lineCommentAt reports whether s starts a line comment. "#" only counts at a word
+// boundary so "a#b" and URLs' fragments stay code.
+func lineCommentAt(s string, comments []string, boundary bool) bool {
+	for _, c := range comments {
+		if strings.HasPrefix(s, c) && (c != "#" || boundary) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// HighlightDiff colors a unified diff: additions green, deletions red, hunk headers
+// cyan. Without color the diff is returned unchanged.
+func HighlightDiff(diff string) string {
+	if !ColorEnabled() {
+		return diff
+	}
+	return highlightDiff(diff)
+}
+
+func highlightDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "+++"), strings.HasPrefix(l, "---"):
+			lines[i] = ansiBold + l + ansiReset
+		case strings.HasPrefix(l, "@@"):
+			lines[i] = ansiCyan + l + ansiReset
+		case strings.HasPrefix(l, "+"):
+			lines[i] = ansiGreen + l + ansiReset
+		case strings.HasPrefix(l, "-"):
+			lines[i] = ansiRed + l + ansiReset
+		}
+	}
+	return strings.Join(lines, "\n")
+}
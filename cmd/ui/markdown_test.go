@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiRe = regexp.MustCompile(`\033\[[0-9;]*[A-Za-z]`)
+
+func newTestRenderer(color bool) (*MarkdownRenderer, *strings.Builder) {
+	var out strings.Builder
+	r := &MarkdownRenderer{out: func(s string) { out.WriteString(s) }, color: color, width: 80}
+	return r, &out
+}
+
+func TestMarkdownRenderer_StylesLinesAndFencesSplitAcrossChunks(t *testing.T) {
+	r, out := newTestRenderer(true)
+	for _, chunk := range []string{"# Ti", "tle\nUse `go ", "test` **now**\n``", "`go\nfunc main() {}\n", "```\n- done"} {
+		r.Write(chunk)
+	}
+	r.Flush()
+
+	got := out.String()
+	for _, want := range []string{
+		ansiBold + ansiCyan + ansiUnderline + "Title" + ansiReset,
+		ansiYellow + "go test" + ansiReset,
+		ansiBold + "now" + ansiReset,
+		"┌─ go",
+		ansiBlue + "func" + ansiReset + " main",
+		ansiCyan + "•" + ansiReset + " done",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, ansiBlue+"done") {
+		t.Fatalf("expected the fence closed before the list: %q", got)
+	}
+}
+
+func TestMarkdownRenderer_AlignsTables(t *testing.T) {
+	r, out := newTestRenderer(true)
+	r.Write("| Name | Size |\n|---|---|\n| main.go | 12 |\n")
+	r.Write("Done")
+	r.Flush()
+
+	lines := strings.Split(ansiRe.ReplaceAllString(out.String(), ""), "\n")
+	for i, l := range lines {
+		// What a terminal shows after a line is redrawn.
+		lines[i] = l[strings.LastIndex(l, "\r")+1:]
+	}
+	want := []string{"Name    │ Size", "────────┼─────", "main.go │ 12  ", "Done"}
+	for i, w := range want {
+		if i >= len(lines) || lines[i] != w {
+			t.Fatalf("expected line %d %q, got %q", i, w, lines)
+		}
+	}
+}
+
+func TestMarkdownRenderer_PassesTextThroughWithoutColor(t *testing.T) {
+	r, out := newTestRenderer(false)
+	text := "# Title\n| a | b |\n```go\nx := 1\n```\npartial"
+	r.Write(text[:9])
+	r.Write(text[9:])
+	r.Flush()
+
+	if out.String() != text {
+		t.Fatalf("expected the text unchanged, got %q", out.String())
+	}
+}
+
+func TestHighlightCodeAndDiff(t *testing.T) {
+	got := highlightCode(`x = "a # b" # note`, "python")
+	if !strings.Contains(got, ansiGreen+`"a # b"`+ansiReset) || !strings.Contains(got, ansiGray+"# note"+ansiReset) {
+		t.Fatalf("expected the string and the comment highlighted, got %q", got)
+	}
+
+	diff := highlightDiff("@@ -1 +1 @@\n-old\n+new\n same")
+	for _, want := range []string{ansiCyan + "@@ -1 +1 @@", ansiRed + "-old", ansiGreen + "+new", "\n same"} {
+		if !strings.Contains(diff, want) {
+			t.Fatalf("expected %q in %q", want, diff)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+
+	"golang.org/x/term"
+)
+
+// ReviewMemories walks the user through a memory proposal, one candidate at a time.
+// It returns nil without prompting when stdin is not a terminal, leaving the proposal pending.
+func (c *CLIApprover) ReviewMemories(ctx context.Context, req api.MemoryProposalPayload) ([]api.MemoryReview, error) {
+	if len(req.Candidates) == 0 || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, nil
+	}
+
+	fmt.Println()
+	fmt.Println("\033[36m╭──────────────────────────────────────────────────────────╮\033[0m")
+	fmt.Println("\033[36m│\033[0m  \033[1;36m🧠 Proposed Memories\033[0m                                    \033[36m│\033[0m")
+	fmt.Println("\033[36m╰──────────────────────────────────────────────────────────╯\033[0m")
+
+	reviews := make([]api.MemoryReview, 0, len(req.Candidates))
+	for i, cand := range req.Candidates {
+		if err := ctx.Err(); err != nil {
+			return reviews, err
+		}
+
+		fmt.Printf("\n\033[1m[%d/%d]\033[0m %s (%s)\n", i+1, len(req.Candidates), cand.Type, cand.Source)
+		fmt.Printf("  %s\n", cand.Content)
+		if len(cand.Tags) > 0 {
+			fmt.Printf("  \033[90mtags: %s\033[0m\n", strings.Join(cand.Tags, ", "))
+		}
+		fmt.Print("\nChoice [(a)ccept/(e)dit/(D)iscard/(s)kip rest]: ")
+
+		input, err := c.Reader.ReadString('\n')
+		if err != nil {
+			return reviews, err
+		}
+
+		switch strings.TrimSpace(strings.ToLower(input)) {
+		case "a", "accept", "y", "yes":
+			reviews = append(reviews, api.MemoryReview{CandidateID: cand.CandidateID, Kind: api.MemoryReviewAccept})
+			fmt.Println("\033[32m✓ Saved\033[0m")
+		case "e", "edit":
+			fmt.Print("New content (empty keeps current): ")
+			edited, err := c.Reader.ReadString('\n')
+			if err != nil {
+				return reviews, err
+			}
+			reviews = append(reviews, api.MemoryReview{CandidateID: cand.CandidateID, Kind: api.MemoryReviewEdit, Content: strings.TrimSpace(edited)})
+			fmt.Println("\033[32m✓ Saved (edited)\033[0m")
+		case "s", "skip":
+			fmt.Println("\033[90m– Discarded remaining proposals\033[0m")
+			return reviews, nil
+		default:
+			reviews = append(reviews, api.MemoryReview{CandidateID: cand.CandidateID, Kind: api.MemoryReviewDiscard})
+			fmt.Println("\033[90m✗ Discarded\033[0m")
+		}
+	}
+	return reviews, nil
+}
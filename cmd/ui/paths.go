@@ -0,0 +1,285 @@
+package ui
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Workspace Paths
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// maxIndexedPaths bounds the walk of very large workspaces.
+const maxIndexedPaths = 20000
+
+// alwaysSkipped are directories never offered for mentions.
+var alwaysSkipped = map[string]bool{".git": true, "node_modules": true, "__pycache__": true}
+
+// ignoreFiles are read in every directory; their patterns use .gitignore syntax.
+var ignoreFiles = []string{".gitignore", ".seaignore"}
+
+// PathIndex lists a workspace's files and directories for @mentions, skipping what
+// .gitignore and .seaignore exclude. Paths are slash-separated and relative to the
+// root; directories end in "/". The workspace is walked on first use.
+type PathIndex struct {
+	root   string
+	paths  []string
+	loaded bool
+}
+
+// NewPathIndex returns an index of root.
+func NewPathIndex(root string) *PathIndex {
+	return &PathIndex{root: root}
+}
+
+// Root returns the indexed directory.
+func (x *PathIndex) Root() string {
+	return x.root
+}
+
+// Paths returns every indexed path, sorted.
+func (x *PathIndex) Paths() []string {
+	if !x.loaded {
+		x.paths = walkWorkspace(x.root)
+		x.loaded = true
+	}
+	return x.paths
+}
+
+// Children returns the entries directly inside dir ("" for the root).
+func (x *PathIndex) Children(dir string) []string {
+	dir = strings.TrimSuffix(dir, "/")
+	var out []string
+	for _, p := range x.Paths() {
+		parent := path.Dir(strings.TrimSuffix(p, "/"))
+		if parent == "." {
+			parent = ""
+		}
+		if parent == dir {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Match returns up to limit paths fuzzily matching query, best first. The query's
+// characters must appear in order; matches at the start of a name or path segment and
+// runs of consecutive characters rank higher.
+func (x *PathIndex) Match(query string, limit int) []string {
+	type scored struct {
+		path  string
+		score int
+	}
+	var hits []scored
+	q := strings.ToLower(query)
+	for _, p := range x.Paths() {
+		if s, ok := fuzzyScore(q, strings.ToLower(p)); ok {
+			hits = append(hits, scored{p, s})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return len(hits[i].path) < len(hits[j].path)
+	})
+	out := make([]string, 0, min(limit, len(hits)))
+	for _, h := range hits[:min(limit, len(hits))] {
+		out = append(out, h.path)
+	}
+	return out
+}
+
+func fuzzyScore(q, p string) (int, bool) {
+	if q == "" {
+		// Without a query, offer the top level.
+		return -strings.Count(strings.TrimSuffix(p, "/"), "/") * 10, true
+	}
+	base := path.Base(strings.TrimSuffix(p, "/"))
+	score := 0
+	switch {
+	case strings.HasPrefix(p, q):
+		score += 100
+	case strings.HasPrefix(base, q):
+		score += 80
+	case strings.Contains(base, q):
+		score += 50
+	case strings.Contains(p, q):
+		score += 30
+	}
+
+	qi, run := 0, 0
+	for i := 0; i < len(p) && qi < len(q); i++ {
+		if p[i] != q[qi] {
+			run = 0
+			continue
+		}
+		qi++
+		run++
+		score += run
+		if i == 0 || strings.ContainsRune("/_-. ", rune(p[i-1])) {
+			score += 5
+		}
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score - len(p)/4, true
+}
+
+// walkWorkspace lists root's paths, applying ignore files as it descends.
+func walkWorkspace(root string) []string {
+	var paths []string
+	rules := map[string][]ignoreRule{} // by directory, relative to root
+	_ = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(paths) >= maxIndexedPaths {
+			return filepath.SkipAll
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rules[""] = loadIgnoreRules(p, "")
+			return nil
+		}
+		if d.IsDir() && alwaysSkipped[d.Name()] {
+			return filepath.SkipDir
+		}
+		if ignored(rules, rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			rules[rel] = loadIgnoreRules(p, rel)
+			paths = append(paths, rel+"/")
+			return nil
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+// ignoreRule is one .gitignore pattern from the ignore file in base.
+type ignoreRule struct {
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+func loadIgnoreRules(dir, base string) []ignoreRule {
+	var rules []ignoreRule
+	for _, name := range ignoreFiles {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if r, ok := parseIgnoreRule(base, scanner.Text()); ok {
+				rules = append(rules, r)
+			}
+		}
+		f.Close()
+	}
+	return rules
+}
+
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	r := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate, line = true, line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly, line = true, strings.TrimSuffix(line, "/")
+	}
+	// Patterns without an inner slash match a name at any depth.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// globToRegexp translates a .gitignore glob: "**" spans directories, "*" and "?"
+// stay within one.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				b.WriteString("(.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			if j := strings.IndexByte(glob[i:], ']'); j > 0 {
+				b.WriteString(strings.Replace(glob[i:i+j+1], "[!", "[^", 1))
+				i += j
+			} else {
+				b.WriteString(`\[`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// ignored applies the rules of rel's ancestors in order; the last match wins.
+func ignored(rules map[string][]ignoreRule, rel string, isDir bool) bool {
+	result := false
+	dirs := []string{""}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		dirs = append(dirs, strings.Join(parts[:i], "/"))
+	}
+	for _, dir := range dirs {
+		for _, r := range rules[dir] {
+			sub := rel
+			if r.base != "" {
+				sub = strings.TrimPrefix(rel, r.base+"/")
+			}
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if r.re.MatchString(sub) {
+				result = !r.negate
+			}
+		}
+	}
+	return result
+}
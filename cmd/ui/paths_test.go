@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPathIndex_RespectsIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":          "build/\n*.log\n!keep.log\n/secret.txt\n",
+		"src/.seaignore":      "gen_*.go\n",
+		"src/main.go":         "",
+		"src/gen_types.go":    "",
+		"src/secret.txt":      "",
+		"secret.txt":          "",
+		"build/out.bin":       "",
+		"app.log":             "",
+		"keep.log":            "",
+		".git/config":         "",
+		"docs/guide/intro.md": "",
+	})
+
+	got := NewPathIndex(root).Paths()
+	want := []string{".gitignore", "docs/", "docs/guide/", "docs/guide/intro.md", "keep.log", "src/", "src/.seaignore", "src/main.go", "src/secret.txt"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPathIndex_MatchRanksFuzzyHits(t *testing.T) {
+	x := &PathIndex{loaded: true, paths: []string{"cmd/", "cmd/chat.go", "cmd/ui/input.go", "pkg/engine/runtime/chat_test.go", "README.md"}}
+
+	if got := x.Match("chat", 2); !slices.Equal(got, []string{"cmd/chat.go", "pkg/engine/runtime/chat_test.go"}) {
+		t.Fatalf("unexpected matches %q", got)
+	}
+	if got := x.Match("cuin", 5); !slices.Equal(got, []string{"cmd/ui/input.go"}) {
+		t.Fatalf("expected a subsequence match, got %q", got)
+	}
+	if got := x.Match("xyz", 5); len(got) != 0 {
+		t.Fatalf("expected no matches, got %q", got)
+	}
+}
+
+func TestInputModel_CompletesMentions(t *testing.T) {
+	m := newInputModel("", "")
+	m.paths = &PathIndex{loaded: true, paths: []string{"cmd/", "cmd/chat.go", "cmd/run.go"}}
+
+	m.textarea.SetValue("read @cm")
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = model.(inputModel)
+	if !m.showMentions || m.mentions[0] != "cmd/" {
+		t.Fatalf("expected the directory offered first, got %q", m.mentions)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = model.(inputModel)
+	if m.textarea.Value() != "read @cmd/" || !m.showMentions || slices.Contains(m.mentions, "cmd/") {
+		t.Fatalf("expected completion to continue inside cmd/, got %q %q", m.textarea.Value(), m.mentions)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(inputModel)
+	if m.textarea.Value() != "read @cmd/run.go " || m.showMentions || m.submitted {
+		t.Fatalf("expected the file completed without submitting, got %q", m.textarea.Value())
+	}
+
+	if got := Mentions("see @cmd/chat.go, and @cmd/chat.go or mail a@b.c"); !slices.Equal(got, []string{"cmd/chat.go,", "cmd/chat.go"}) {
+		t.Fatalf("unexpected mentions %q", got)
+	}
+}
@@ -4,6 +4,7 @@ package api
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -24,6 +25,33 @@ type Engine interface {
 
 	// Resume continues from an interrupt point (approval/cancel/modify), returns same event stream
 	Resume(ctx context.Context, sessionID string, decision Decision) (EventStream, error)
+
+	// Cancel stops the session's running turn, which records its partial output and
+	// finishes with reason "canceled"; a pending approval is dropped.
+	Cancel(sessionID string) error
+	// Steer adds a user message to the running turn, seen by its next LLM request.
+	Steer(sessionID, message string) error
+
+	// UpdateSession changes a session's settings between turns; it fails with
+	// turn_in_progress while a turn runs.
+	UpdateSession(ctx context.Context, sessionID string, update SessionUpdate) (SessionInfo, error)
+
+	// Session views: the plan (nil when there is none), the files the session's tool
+	// calls changed, and the conversation exported as "markdown" or "json".
+	SessionPlan(ctx context.Context, sessionID string) (*PlanPayload, error)
+	ChangedFiles(ctx context.Context, sessionID string) ([]FileChange, error)
+	ExportSession(ctx context.Context, sessionID, format string, w io.Writer) error
+
+	// ModelRoles returns the model name used for each model role.
+	ModelRoles() map[string]string
+	// UsageRecords returns a session's usage by day, skill and model ("" for all sessions).
+	UsageRecords(ctx context.Context, sessionID string) ([]UsageRecord, error)
+
+	// Memory proposals: PendingMemoryProposal returns the session's unresolved
+	// proposal (nil if none); ResolveMemoryProposal stores the accepted and edited
+	// candidates and returns the stored entries.
+	PendingMemoryProposal(ctx context.Context, sessionID string) (*MemoryProposalPayload, error)
+	ResolveMemoryProposal(ctx context.Context, sessionID, requestID string, reviews []MemoryReview) ([]MemoryEntry, error)
 }
 
 // StartOptions configures session behavior.
@@ -35,6 +63,18 @@ type StartOptions struct {
 
 	// ActiveSkill sets the initial active skill (optional)
 	ActiveSkill string
+
+	// UntilPlanDone makes Send/Resume keep issuing continuation turns while the
+	// session plan has pending or running items, within the Autopilot limits.
+	UntilPlanDone bool
+	Autopilot     AutopilotLimits
+}
+
+// AutopilotLimits bounds an until-plan-done run. Zero values use engine defaults.
+type AutopilotLimits struct {
+	MaxTurns    int           // continuation turns per Send/Resume (default: 20)
+	MaxDuration time.Duration // wall time, checked between turns (default: 30m)
+	TokenBudget int           // estimated tokens used across turns (0 = unlimited)
 }
 
 // SessionInfo is the public view of a session.
@@ -44,6 +84,40 @@ type SessionInfo struct {
 	UpdatedAt    time.Time
 	MessageCount int
 	ActiveSkill  string
+	ApprovalMode ApprovalMode
+	Model        string // model role or name chosen for the session; "" is the main model
+	Usage        Usage  // cumulative usage of the session's turns
+}
+
+// SessionUpdate changes a session's settings between turns. Nil fields are left as
+// they are.
+type SessionUpdate struct {
+	ApprovalMode *ApprovalMode
+
+	// ActiveSkill locks the session to a skill; "" runs without one.
+	ActiveSkill *string
+
+	// AutoSkill turns skill routing on (unlocking the active skill) or off.
+	AutoSkill *bool
+
+	// Model selects a model role or model name for the session's turns; "" returns to
+	// the main model.
+	Model *string
+}
+
+// FileChange is a workspace file the session's tool calls changed.
+type FileChange struct {
+	Path  string `json:"path"`
+	Edits int    `json:"edits"` // successful write_file and edit_file calls
+}
+
+// UsageRecord is the usage of one session on one day, for one skill and model.
+type UsageRecord struct {
+	SessionID string
+	Day       string // YYYY-MM-DD, local time
+	Skill     string // "" when no skill was active
+	Model     string
+	Usage     Usage
 }
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -83,6 +157,23 @@ type Decision struct {
 	RequestID    string
 	ToolCallID   string
 	ModifiedArgs Args // for modify kind
+
+	// Note is passed to the model with the result of a modified call, e.g. why the
+	// arguments were changed.
+	Note string
+
+	// Items decides individual calls of a batch approval. Calls without an item get
+	// Kind; ModifiedArgs applies to the call named by ToolCallID (the first one when
+	// empty).
+	Items []ItemDecision
+}
+
+// ItemDecision is the decision for one tool call of a batch approval.
+type ItemDecision struct {
+	ToolCallID   string
+	Kind         DecisionKind
+	ModifiedArgs Args   // for modify kind
+	Note         string // for modify kind (see Decision.Note)
 }
 
 // Args is the canonical argument container for tools.
@@ -34,6 +34,12 @@ const (
 	EventPlan       EventType = "plan"
 	EventDone       EventType = "done"
 	EventError      EventType = "error"
+
+	// EventMemoryProposal proposes new memory entries for review after a turn.
+	EventMemoryProposal EventType = "memory_proposal"
+
+	// EventProgressReport summarizes an until-plan-done run when it stops.
+	EventProgressReport EventType = "progress_report"
 )
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -59,6 +65,9 @@ type Event struct {
 	Done       *DonePayload       `json:"done,omitempty"`
 	Error      *ErrorPayload      `json:"error,omitempty"`
 
+	MemoryProposal *MemoryProposalPayload `json:"memory_proposal,omitempty"`
+	ProgressReport *ProgressReportPayload `json:"progress_report,omitempty"`
+
 	// Display hint for UI (optional, does not affect engine semantics)
 	Display *DisplayHint `json:"display,omitempty"`
 }
@@ -71,8 +80,9 @@ type Event struct {
 type DeltaSource string
 
 const (
-	DeltaText    DeltaSource = "text"     // Normal assistant response
-	DeltaToolArg DeltaSource = "tool_arg" // Tool argument being generated
+	DeltaText      DeltaSource = "text"      // Normal assistant response
+	DeltaToolArg   DeltaSource = "tool_arg"  // Tool argument being generated
+	DeltaReasoning DeltaSource = "reasoning" // Model reasoning ("thinking") before the answer
 )
 
 // DeltaPayload contains streaming text increments.
@@ -81,7 +91,8 @@ type DeltaPayload struct {
 	Source DeltaSource `json:"source,omitempty"` // Default: "text"
 }
 
-// ThinkingPayload contains progress/explanation messages.
+// ThinkingPayload contains engine progress messages (see StartOptions.EmitThinking).
+// Model reasoning streams as EventDelta with Source DeltaReasoning instead.
 type ThinkingPayload struct {
 	Message string `json:"message"`
 }
@@ -108,6 +119,9 @@ type ToolResult struct {
 	Status  string `json:"status"` // "success" | "error"
 	Error   string `json:"error,omitempty"`
 	Data    any    `json:"data,omitempty"` // Optional structured data
+
+	// Parts carries non-text output (e.g. an image read from disk) for the model.
+	Parts []ContentPart `json:"parts,omitempty"`
 }
 
 // ApprovalPayload requests user approval for a tool call.
@@ -116,11 +130,56 @@ type ApprovalPayload struct {
 	ToolCallID string          `json:"tool_call_id"`
 	ToolCall   ToolCallPayload `json:"tool_call"`
 	Mode       ApprovalMode    `json:"mode"`
+
+	// Items lists every tool call needing approval when one assistant message made
+	// several; ToolCall is the first of them. Empty for a single call.
+	Items []ToolCallPayload `json:"items,omitempty"`
+}
+
+// Calls returns the tool calls the approval covers.
+func (p ApprovalPayload) Calls() []ToolCallPayload {
+	if len(p.Items) > 0 {
+		return p.Items
+	}
+	return []ToolCallPayload{p.ToolCall}
 }
 
 // DonePayload marks turn completion.
 type DonePayload struct {
-	Reason string `json:"reason,omitempty"` // e.g., "completed", "rejected", "canceled", "error"
+	Reason string `json:"reason,omitempty"` // e.g., "completed", "rejected", "canceled", "error", or a limit code
+	Usage  *Usage `json:"usage,omitempty"`  // usage of the whole turn, including work before approvals
+}
+
+// MemoryProposalPayload asks the user to accept, edit or discard proposed memories.
+// The engine keeps it as the session's pending proposal until resolved.
+type MemoryProposalPayload struct {
+	RequestID  string            `json:"request_id"`
+	Candidates []MemoryCandidate `json:"candidates"`
+}
+
+// Progress report stop reasons.
+const (
+	StopPlanDone         = "plan_done"
+	StopPlanBlocked      = "plan_blocked"
+	StopPlanErrored      = "plan_errored"
+	StopPlanStalled      = "plan_stalled" // open items remain but none is actionable
+	StopNoPlan           = "no_plan"
+	StopNoProgress       = "no_progress"
+	StopApprovalRequired = "approval_required"
+	StopMaxTurns         = "max_turns"
+	StopMaxDuration      = "max_duration"
+	StopTokenBudget      = "token_budget"
+	StopTurnEnded        = "turn_ended" // the last turn was canceled, rejected or failed
+)
+
+// ProgressReportPayload is emitted once when an until-plan-done run stops.
+type ProgressReportPayload struct {
+	Reason          string       `json:"reason"`
+	Turns           int          `json:"turns"`
+	ElapsedMs       int64        `json:"elapsed_ms"`
+	EstimatedTokens int          `json:"estimated_tokens"`
+	Plan            *PlanPayload `json:"plan,omitempty"`
+	Message         string       `json:"message,omitempty"`
 }
 
 // ErrorPayload contains error information.
@@ -145,10 +204,16 @@ const (
 )
 
 // PlanItem represents a single task in a plan.
+// Items form a tree through ParentID and a dependency graph through DependsOn.
 type PlanItem struct {
 	ID     int            `json:"id"`
 	Text   string         `json:"text"`
 	Status PlanItemStatus `json:"status"`
+
+	ParentID  int      `json:"parent_id,omitempty"`  // 0 = top-level item
+	DependsOn []int    `json:"depends_on,omitempty"` // items that must be done first
+	Notes     string   `json:"notes,omitempty"`
+	Evidence  []string `json:"evidence,omitempty"` // tool call IDs that completed this item
 }
 
 // PlanPayload contains the full plan state.
@@ -200,6 +265,7 @@ const (
 	ErrInvalidSession    = "invalid_session"
 	ErrTurnInProgress    = "turn_in_progress"
 	ErrNoPendingApproval = "no_pending_approval"
+	ErrNoActiveTurn      = "no_active_turn"
 	ErrApprovalMismatch  = "approval_mismatch"
 	ErrToolNotFound      = "tool_not_found"
 	ErrToolArgsInvalid   = "tool_args_invalid"
@@ -207,4 +273,21 @@ const (
 	ErrWorkspaceEscape   = "workspace_escape"
 	ErrToolExecuteFailed = "tool_execute_failed"
 	ErrStoreError        = "store_error"
+
+	// Limit codes end a turn early. They double as DonePayload.Reason.
+	ErrLimitLLMCalls  = "limit_llm_calls"
+	ErrLimitToolCalls = "limit_tool_calls"
+	ErrLimitTokens    = "limit_tokens"
+	ErrLimitCost      = "limit_cost"
+	ErrLimitDuration  = "limit_duration"
+	ErrToolLoop       = "tool_loop"
 )
+
+// IsLimitCode reports whether code is one of the turn/session limit codes.
+func IsLimitCode(code string) bool {
+	switch code {
+	case ErrLimitLLMCalls, ErrLimitToolCalls, ErrLimitTokens, ErrLimitCost, ErrLimitDuration, ErrToolLoop:
+		return true
+	}
+	return false
+}
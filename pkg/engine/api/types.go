@@ -1,6 +1,11 @@
 package api
 
-import "time"
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // Policy Context
@@ -93,13 +98,63 @@ func IsSystemTool(name string) bool {
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 
 // LLMMessage represents a message in the LLM conversation.
+// Content is the text of the message; Parts adds non-text content such as images.
 type LLMMessage struct {
 	Role       string        `json:"role"` // "system" | "user" | "assistant" | "tool"
 	Content    string        `json:"content"`
+	Parts      []ContentPart `json:"parts,omitempty"`        // attachments, sent after Content
+	Reasoning  string        `json:"reasoning,omitempty"`    // model reasoning behind an assistant message
 	ToolCalls  []LLMToolCall `json:"tool_calls,omitempty"`   // for assistant role
 	ToolCallID string        `json:"tool_call_id,omitempty"` // for tool role
 }
 
+// Content part types.
+const (
+	PartText  = "text"
+	PartImage = "image"
+	PartFile  = "file"
+)
+
+// ContentPart is one piece of multimodal message content.
+type ContentPart struct {
+	Type     string `json:"type"` // "text" | "image" | "file"
+	Text     string `json:"text,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"`     // inline bytes (images)
+	Path     string `json:"path,omitempty"`     // source file, for display and file references
+	BlobRef  string `json:"blob_ref,omitempty"` // content hash of Data stored outside the session (see store)
+}
+
+// ImagePart returns an image part with inline data.
+func ImagePart(mimeType string, data []byte, path string) ContentPart {
+	return ContentPart{Type: PartImage, MIMEType: mimeType, Data: data, Path: path}
+}
+
+// ImageMIMEType returns the MIME type for image files models accept, or "" for other files.
+func ImageMIMEType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	}
+	return ""
+}
+
+// HasImages reports whether the message carries image parts.
+func (m LLMMessage) HasImages() bool {
+	for _, p := range m.Parts {
+		if p.Type == PartImage {
+			return true
+		}
+	}
+	return false
+}
+
 // LLMToolCall represents a tool call from the LLM.
 type LLMToolCall struct {
 	ID   string `json:"id"`
@@ -122,6 +177,9 @@ type Session struct {
 	Summary  string           `json:"summary,omitempty"` // Compressed history summary
 	Messages []LLMMessage     `json:"messages"`
 	Pending  *PendingApproval `json:"pending,omitempty"`
+
+	// PendingMemory holds memory proposals awaiting review (does not block turns).
+	PendingMemory *MemoryProposalPayload `json:"pending_memory,omitempty"`
 }
 
 // PendingApproval stores the state needed to resume after approval.
@@ -132,6 +190,61 @@ type PendingApproval struct {
 	Preview   *Preview        `json:"preview,omitempty"`
 	CreatedAt time.Time       `json:"created_at"`
 	StopAfter bool            `json:"stop_after,omitempty"`
+
+	// Batch holds the remaining tool calls of the assistant message, in order, when
+	// the approval covers several of them. Calls with NeedApproval set are decided by
+	// the user; the others run in between as usual. ToolCall is the first call needing
+	// approval.
+	Batch []ToolCallPayload `json:"batch,omitempty"`
+
+	// Seq is the last event sequence number of the turn before it suspended.
+	Seq int64 `json:"seq,omitempty"`
+
+	// Usage of the suspended turn so far, carried over so limits span the whole turn.
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// Calls returns the tool calls waiting on the approval, in execution order.
+func (p *PendingApproval) Calls() []ToolCallPayload {
+	if len(p.Batch) > 0 {
+		return p.Batch
+	}
+	call := p.ToolCall
+	call.NeedApproval = true
+	return []ToolCallPayload{call}
+}
+
+// Usage counts the work done by a turn or session. Token counts come from the
+// provider when it reports them and are estimated from message sizes otherwise;
+// cost is always an estimate from a price table.
+type Usage struct {
+	LLMCalls         int           `json:"llm_calls"`
+	ToolCalls        int           `json:"tool_calls"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	CachedTokens     int           `json:"cached_tokens,omitempty"`   // part of PromptTokens
+	EstimatedCalls   int           `json:"estimated_calls,omitempty"` // LLM calls without provider usage
+	Cost             float64       `json:"cost"`
+	Duration         time.Duration `json:"duration"`
+}
+
+// Tokens returns prompt plus completion tokens.
+func (u Usage) Tokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add returns the sum of u and o.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		LLMCalls:         u.LLMCalls + o.LLMCalls,
+		ToolCalls:        u.ToolCalls + o.ToolCalls,
+		PromptTokens:     u.PromptTokens + o.PromptTokens,
+		CompletionTokens: u.CompletionTokens + o.CompletionTokens,
+		CachedTokens:     u.CachedTokens + o.CachedTokens,
+		EstimatedCalls:   u.EstimatedCalls + o.EstimatedCalls,
+		Cost:             u.Cost + o.Cost,
+		Duration:         u.Duration + o.Duration,
+	}
 }
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -148,6 +261,9 @@ type State struct {
 	Messages     []LLMMessage
 
 	Metadata map[string]any
+
+	// Emit publishes an event into the current turn's stream (set by the runtime; may be nil).
+	Emit func(ctx context.Context, e Event)
 }
 
 // TurnOutcome represents how a turn completed.
@@ -166,6 +282,7 @@ type TurnSummary struct {
 
 	Outcome       TurnOutcome
 	AssistantText string
+	Continuation  bool // started by autopilot to work through the plan
 
 	ToolCalls  []ToolCallRef
 	Approvals  []ApprovalRef
@@ -197,6 +314,7 @@ type SkillMeta struct {
 	License       string   `json:"license,omitempty"`
 	Compatibility string   `json:"compatibility,omitempty"`
 	AllowedTools  []string `json:"allowed_tools,omitempty"`
+	Model         string   `json:"model,omitempty"` // model role or model name requested by the skill
 	Path          string   `json:"path"`
 }
 
@@ -208,6 +326,41 @@ type Skill struct {
 	References []string          `json:"references,omitempty"`
 	Assets     []string          `json:"assets,omitempty"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	Hooks      []SkillHook       `json:"hooks,omitempty"`
+}
+
+// SkillHookEvent identifies when a skill hook runs.
+type SkillHookEvent string
+
+const (
+	HookOnActivate SkillHookEvent = "on-activate"
+	HookPreTool    SkillHookEvent = "pre-tool"
+	HookPostTool   SkillHookEvent = "post-tool"
+	HookOnTurnEnd  SkillHookEvent = "on-turn-end"
+)
+
+// SkillHook is a lifecycle script declared in skill frontmatter.
+// Scripts resolve under the skill's scripts/ directory (same rules as run_skill_script).
+type SkillHook struct {
+	Event      SkillHookEvent `json:"event"`
+	Script     string         `json:"script"`
+	Args       []string       `json:"args,omitempty"`
+	Tools      []string       `json:"tools,omitempty"`    // pre-tool/post-tool filter; empty = all tools
+	Blocking   bool           `json:"blocking,omitempty"` // pre-tool only: a failing hook blocks the call
+	TimeoutSec int            `json:"timeout_sec,omitempty"`
+}
+
+// MatchesTool reports whether the hook applies to the given tool name.
+func (h SkillHook) MatchesTool(toolName string) bool {
+	if len(h.Tools) == 0 {
+		return true
+	}
+	for _, t := range h.Tools {
+		if t == toolName || t == "*" {
+			return true
+		}
+	}
+	return false
 }
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -241,4 +394,35 @@ type MemoryEntry struct {
 	Tags      []string     `json:"tags,omitempty"`
 	CreatedAt time.Time    `json:"created_at"`
 	UpdatedAt time.Time    `json:"updated_at"`
+
+	// Usage tracking (updated when the entry is injected into a turn).
+	LastUsed *time.Time `json:"last_used,omitempty"`
+	Hits     int        `json:"hits,omitempty"`
+}
+
+// MemoryCandidate is a proposed memory entry awaiting user review.
+type MemoryCandidate struct {
+	CandidateID string       `json:"candidate_id"`
+	Type        MemoryType   `json:"type"`
+	Content     string       `json:"content"`
+	Source      MemorySource `json:"source"`
+	Tags        []string     `json:"tags,omitempty"`
+}
+
+// MemoryReviewKind is the review outcome for a memory candidate.
+type MemoryReviewKind string
+
+const (
+	MemoryReviewAccept  MemoryReviewKind = "accept"
+	MemoryReviewEdit    MemoryReviewKind = "edit"
+	MemoryReviewDiscard MemoryReviewKind = "discard"
+)
+
+// MemoryReview resolves one candidate. For edit, Content (and optionally Type/Tags) replace the proposal.
+type MemoryReview struct {
+	CandidateID string           `json:"candidate_id"`
+	Kind        MemoryReviewKind `json:"kind"`
+	Content     string           `json:"content,omitempty"`
+	Type        MemoryType       `json:"type,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
 }
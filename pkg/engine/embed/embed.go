@@ -0,0 +1,139 @@
+// Package embed provides text embedding providers and an on-disk vector cache.
+package embed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Provider turns texts into embedding vectors.
+type Provider interface {
+	// Model identifies the embedding space; vectors from different models are not comparable.
+	Model() string
+	// Embed returns one vector per input text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Cosine returns the cosine similarity of two vectors (0 when either is empty or lengths differ).
+func Cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		na += x * x
+		nb += y * y
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Cache
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Cache stores vectors on disk keyed by a hash of model and content.
+// A nil or dir-less cache only keeps vectors in memory.
+type Cache struct {
+	dir string
+	mu  sync.Mutex
+	mem map[string][]float32
+}
+
+// NewCache creates a cache rooted at dir (created lazily on first write).
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir, mem: make(map[string][]float32)}
+}
+
+// Key returns the content-hash cache key for text embedded with model.
+func Key(model, text string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) get(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.mem[key]; ok {
+		return v, true
+	}
+	if c.dir == "" {
+		return nil, false
+	}
+	b, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var v []float32
+	if err := json.Unmarshal(b, &v); err != nil || len(v) == 0 {
+		return nil, false
+	}
+	c.mem[key] = v
+	return v, true
+}
+
+func (c *Cache) put(key string, v []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mem[key] = v
+	if c.dir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	tmp := filepath.Join(c.dir, key+".json.tmp")
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, filepath.Join(c.dir, key+".json"))
+}
+
+// Embed returns vectors for texts, calling the provider only for cache misses.
+func (c *Cache) Embed(ctx context.Context, p Provider, texts []string) ([][]float32, error) {
+	if c == nil {
+		return p.Embed(ctx, texts)
+	}
+
+	out := make([][]float32, len(texts))
+	var missIdx []int
+	var missTexts []string
+	for i, t := range texts {
+		if v, ok := c.get(Key(p.Model(), t)); ok {
+			out[i] = v
+			continue
+		}
+		missIdx = append(missIdx, i)
+		missTexts = append(missTexts, t)
+	}
+	if len(missTexts) == 0 {
+		return out, nil
+	}
+
+	vecs, err := p.Embed(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missIdx {
+		if j >= len(vecs) {
+			break
+		}
+		out[i] = vecs[j]
+		c.put(Key(p.Model(), texts[i]), vecs[j])
+	}
+	return out, nil
+}
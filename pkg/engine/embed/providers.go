@@ -0,0 +1,197 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// OpenAI-compatible provider
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// OpenAIProvider calls an OpenAI-compatible /embeddings endpoint.
+type OpenAIProvider struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIProvider creates an embeddings client (default model: text-embedding-3-small).
+func NewOpenAIProvider(baseURL, apiKey, model string) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+	return &OpenAIProvider{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (p *OpenAIProvider) Model() string { return p.model }
+
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(map[string]any{"model": p.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimRight(p.baseURL, "/") + "/embeddings"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings request failed: %s: %s", resp.Status, strings.TrimSpace(string(raw)))
+	}
+
+	var parsed struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("decode embeddings response: %w", err)
+	}
+	out := make([][]float32, len(texts))
+	for _, d := range parsed.Data {
+		if d.Index >= 0 && d.Index < len(out) {
+			out[d.Index] = d.Embedding
+		}
+	}
+	for i := range out {
+		if out[i] == nil {
+			return nil, fmt.Errorf("embeddings response missing index %d", i)
+		}
+	}
+	return out, nil
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Hash provider
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// HashProvider is a deterministic, offline provider based on feature hashing of
+// words and character bigrams. It needs no network and handles CJK text, which
+// makes it suitable for tests and as a zero-config fallback.
+type HashProvider struct {
+	Dim int
+}
+
+// NewHashProvider creates a hash provider with the given dimension (default 256).
+func NewHashProvider(dim int) *HashProvider {
+	if dim <= 0 {
+		dim = 256
+	}
+	return &HashProvider{Dim: dim}
+}
+
+func (p *HashProvider) Model() string { return fmt.Sprintf("hash-%d", p.dim()) }
+
+func (p *HashProvider) dim() int {
+	if p.Dim <= 0 {
+		return 256
+	}
+	return p.Dim
+}
+
+func (p *HashProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i] = p.vector(t)
+	}
+	return out, nil
+}
+
+func (p *HashProvider) vector(text string) []float32 {
+	v := make([]float32, p.dim())
+	add := func(feature string, weight float32) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(feature))
+		v[int(h.Sum32()%uint32(len(v)))] += weight
+	}
+
+	for _, w := range Tokenize(text) {
+		add("w:"+w, 1)
+	}
+	// Character bigrams give CJK text (no spaces) useful overlap.
+	var prev rune
+	for _, r := range strings.ToLower(text) {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			prev = 0
+			continue
+		}
+		if prev != 0 {
+			add("b:"+string([]rune{prev, r}), 0.5)
+		}
+		prev = r
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += float64(x * x)
+	}
+	if norm > 0 {
+		n := float32(math.Sqrt(norm))
+		for i := range v {
+			v[i] /= n
+		}
+	}
+	return v
+}
+
+// Tokenize lowercases text and splits it into terms: runs of letters/digits for
+// alphabetic scripts and single characters for Han/Kana/Hangul.
+func Tokenize(text string) []string {
+	var out []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			out = append(out, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			out = append(out, string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Filtering & Duplicates
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// DefaultDuplicateThreshold is the term overlap at which two entries are flagged as duplicates.
+const DefaultDuplicateThreshold = 0.8
+
+// Filter selects memory entries for listing, pruning and export. Zero fields match everything.
+type Filter struct {
+	Types     []api.MemoryType
+	Tags      []string // entry must carry all of these (case-insensitive)
+	Source    api.MemorySource
+	Query     string        // substring match on id, content or tags
+	OlderThan time.Duration // last activity before now-OlderThan
+	NewerThan time.Duration // last activity after now-NewerThan
+
+	// Now is injectable for tests.
+	Now func() time.Time
+}
+
+// Match reports whether the entry passes the filter.
+func (f Filter) Match(e api.MemoryEntry) bool {
+	if f.Source != "" && e.Source != f.Source {
+		return false
+	}
+	if len(f.Types) > 0 {
+		ok := false
+		for _, t := range f.Types {
+			if e.Type == t {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	for _, want := range f.Tags {
+		if !hasTag(e, want) {
+			return false
+		}
+	}
+	if q := strings.ToLower(strings.TrimSpace(f.Query)); q != "" && !matchMemory(q, e) {
+		return false
+	}
+	if f.OlderThan > 0 || f.NewerThan > 0 {
+		now := time.Now()
+		if f.Now != nil {
+			now = f.Now()
+		}
+		last := LastActivity(e)
+		if f.OlderThan > 0 && !last.Before(now.Add(-f.OlderThan)) {
+			return false
+		}
+		if f.NewerThan > 0 && last.Before(now.Add(-f.NewerThan)) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterEntries returns the entries that pass the filter, preserving order.
+func FilterEntries(entries []api.MemoryEntry, f Filter) []api.MemoryEntry {
+	var out []api.MemoryEntry
+	for _, e := range entries {
+		if f.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Duplicate is a pair of entries whose contents are near-identical.
+// Keep is the entry worth keeping (most recently active, then most used); Drop is the other.
+type Duplicate struct {
+	Keep       api.MemoryEntry `json:"keep"`
+	Drop       api.MemoryEntry `json:"drop"`
+	Similarity float64         `json:"similarity"`
+}
+
+// FindDuplicates returns pairs of entries with Similarity >= threshold (0 = default).
+// Each entry is dropped at most once, so removing every Drop leaves one entry per group.
+func FindDuplicates(entries []api.MemoryEntry, threshold float64) []Duplicate {
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+	dropped := make(map[string]bool)
+	var out []Duplicate
+	for i := 0; i < len(entries); i++ {
+		if dropped[entries[i].ID] {
+			continue
+		}
+		for j := i + 1; j < len(entries); j++ {
+			if dropped[entries[j].ID] {
+				continue
+			}
+			sim := Similarity(entries[i].Content, entries[j].Content)
+			if sim < threshold {
+				continue
+			}
+			keep, drop := entries[i], entries[j]
+			if preferEntry(drop, keep) {
+				keep, drop = drop, keep
+			}
+			dropped[drop.ID] = true
+			out = append(out, Duplicate{Keep: keep, Drop: drop, Similarity: sim})
+			if drop.ID == entries[i].ID {
+				break
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Similarity > out[j].Similarity })
+	return out
+}
+
+// preferEntry reports whether a should be kept over b.
+func preferEntry(a, b api.MemoryEntry) bool {
+	la, lb := LastActivity(a), LastActivity(b)
+	if !la.Equal(lb) {
+		return la.After(lb)
+	}
+	return a.Hits > b.Hits
+}
+
+// ParseAge parses an age such as "90d", "2w" or any time.ParseDuration value ("36h").
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return 0, nil
+	}
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(s[:len(s)-1]))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age: %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age: %q", s)
+	}
+	return d, nil
+}
+
+// ParseType validates a memory type name.
+func ParseType(s string) (api.MemoryType, error) {
+	t := api.MemoryType(strings.ToLower(strings.TrimSpace(s)))
+	switch t {
+	case api.MemoryFact, api.MemoryPreference, api.MemoryDecision, api.MemoryLesson:
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid memory type: %q (fact|preference|decision|lesson)", s)
+}
+
+// ParseSource validates a memory source name.
+func ParseSource(s string) (api.MemorySource, error) {
+	src := api.MemorySource(strings.ToLower(strings.TrimSpace(s)))
+	switch src {
+	case api.MemorySourceUser, api.MemorySourceProject:
+		return src, nil
+	}
+	return "", fmt.Errorf("invalid memory source: %q (user|project)", s)
+}
+
+func hasTag(e api.MemoryEntry, tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, t := range e.Tags {
+		if strings.ToLower(strings.TrimSpace(t)) == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestMarkdown_RoundTrip(t *testing.T) {
+	used := time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+	in := []api.MemoryEntry{
+		{
+			ID: "a", Type: api.MemoryFact, Source: api.MemorySourceProject,
+			Content:   "Postgres runs on port 5433.\n\n- not a meta line: kept as content",
+			Tags:      []string{"db", "docker"},
+			CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			LastUsed:  &used,
+			Hits:      3,
+		},
+		{ID: "b", Type: api.MemoryPreference, Source: api.MemorySourceUser, Content: "Reply in Chinese"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, in); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out, err := ReadMarkdown(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip mismatch:\nin:  %+v\nout: %+v", in, out)
+	}
+}
+
+func TestFindDuplicates_KeepsMostRecent(t *testing.T) {
+	now := time.Now()
+	entries := []api.MemoryEntry{
+		{ID: "old", Content: "Postgres runs on port 5433 in docker compose", UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "new", Content: "postgres runs on port 5433 in the docker compose", UpdatedAt: now},
+		{ID: "other", Content: "Reply in Chinese", UpdatedAt: now},
+	}
+	dups := FindDuplicates(entries, 0)
+	if len(dups) != 1 || dups[0].Keep.ID != "new" || dups[0].Drop.ID != "old" {
+		t.Fatalf("unexpected duplicates: %+v", dups)
+	}
+
+	older := FilterEntries(entries, Filter{OlderThan: 24 * time.Hour})
+	if len(older) != 1 || older[0].ID != "old" {
+		t.Fatalf("expected only old entry, got %+v", older)
+	}
+}
+
+func TestStructuredManager_ImportSkipsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	m := NewStructuredManager(t.TempDir())
+	if err := m.Add(ctx, api.MemoryEntry{ID: "db", Type: api.MemoryFact, Source: api.MemorySourceProject, Content: "Postgres runs on port 5433"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	created := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	res, err := m.Import(ctx, []api.MemoryEntry{
+		{Type: api.MemoryFact, Content: "postgres runs on port 5433"},
+		{Type: api.MemoryLesson, Content: "Run migrations before seeding", CreatedAt: created},
+		{Type: "todo", Content: "not a memory type"},
+	}, 0, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Added) != 1 || len(res.Duplicates) != 1 || len(res.Invalid) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	got, err := m.Get(ctx, res.Added[0].ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Source != api.MemorySourceProject || !got.CreatedAt.Equal(created) {
+		t.Fatalf("expected defaults and preserved timestamps, got %+v", got)
+	}
+}
+
+func TestStructuredManager_ImportNormalizesTypeAndSource(t *testing.T) {
+	ctx := context.Background()
+	m := NewStructuredManager(t.TempDir())
+
+	res, err := m.Import(ctx, []api.MemoryEntry{
+		{Type: "Fact", Source: "Project", Content: "The API listens on port 8080"},
+		{Type: "PREFERENCE", Source: " User ", Content: "Keep answers short"},
+	}, 0, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Added) != 2 || len(res.Invalid) != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	project, _ := m.List(ctx, api.MemorySourceProject)
+	user, _ := m.List(ctx, api.MemorySourceUser)
+	if len(project) != 1 || project[0].Type != api.MemoryFact || project[0].Source != api.MemorySourceProject {
+		t.Fatalf("expected a normalized project fact, got %+v", project)
+	}
+	if len(user) != 1 || user[0].Type != api.MemoryPreference || user[0].Source != api.MemorySourceUser {
+		t.Fatalf("expected a normalized user preference, got %+v", user)
+	}
+}
+
+func TestStructuredManager_MoveBetweenSources(t *testing.T) {
+	ctx := context.Background()
+	m := NewStructuredManager(t.TempDir())
+	entry := api.MemoryEntry{ID: "lang", Type: api.MemoryPreference, Source: api.MemorySourceUser, Content: "Reply in Chinese", Hits: 2}
+	if err := m.Add(ctx, entry); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	for _, to := range []api.MemorySource{api.MemorySourceProject, api.MemorySourceUser} {
+		from := entry.Source
+		entry.Source = to
+		if err := m.Move(ctx, entry, from); err != nil {
+			t.Fatalf("move to %s: %v", to, err)
+		}
+		moved, _ := m.List(ctx, to)
+		left, _ := m.List(ctx, from)
+		if len(moved) != 1 || moved[0].ID != "lang" || moved[0].Source != to || moved[0].Hits != 2 || len(left) != 0 {
+			t.Fatalf("expected the entry only in %s, got %s=%+v %s=%+v", to, to, moved, from, left)
+		}
+	}
+
+	if err := m.Move(ctx, entry, api.MemorySourceProject); err == nil {
+		t.Fatalf("expected a move from a source without the entry refused")
+	}
+}
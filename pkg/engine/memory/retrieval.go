@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/embed"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Ranked Retrieval
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Query describes what the current turn needs from memory.
+type Query struct {
+	Text        string // usually the latest user message
+	Skill       string // active skill (entries tagged with it are boosted)
+	TokenBudget int    // max estimated tokens to return (0 = no cap)
+	Limit       int    // max entries to return (0 = no cap)
+
+	// MaxChars is how much of each entry's content the caller uses (0 = all of it);
+	// the token budget counts only that part.
+	MaxChars int
+}
+
+// ScoredEntry is a ranked memory entry with its score breakdown.
+type ScoredEntry struct {
+	Entry     api.MemoryEntry `json:"entry"`
+	Score     float64         `json:"score"`
+	Relevance float64         `json:"relevance"` // combined lexical/semantic relevance in [0,1]
+	BM25      float64         `json:"bm25"`      // normalized to [0,1] within the candidate set
+	Semantic  float64         `json:"semantic,omitempty"`
+	Recency   float64         `json:"recency"` // exponential decay since last update/use
+	Usage     float64         `json:"usage"`   // saturating function of hit count
+	Tokens    int             `json:"tokens"`
+}
+
+// Retriever ranks memory entries with BM25, optional embeddings, and recency/usage decay.
+type Retriever struct {
+	// Provider enables semantic similarity when set; Cache avoids re-embedding unchanged entries.
+	Provider embed.Provider
+	Cache    *embed.Cache
+
+	// HalfLife controls recency decay (default 30 days).
+	HalfLife time.Duration
+
+	// Now is injectable for tests.
+	Now func() time.Time
+}
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// Preferences apply regardless of topic, so they stay eligible without a lexical match.
+	preferenceBaseRelevance = 0.3
+	skillTagBoost           = 0.2
+)
+
+// Rank scores entries against the query, drops irrelevant ones, and applies the budget.
+func (r *Retriever) Rank(ctx context.Context, entries []api.MemoryEntry, q Query) []ScoredEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	if r != nil && r.Now != nil {
+		now = r.Now()
+	}
+	halfLife := 30 * 24 * time.Hour
+	if r != nil && r.HalfLife > 0 {
+		halfLife = r.HalfLife
+	}
+
+	docs := make([][]string, len(entries))
+	for i, e := range entries {
+		docs[i] = embed.Tokenize(entryText(e))
+	}
+	queryTerms := embed.Tokenize(q.Text)
+	bm := bm25Scores(docs, queryTerms)
+	maxBM := 0.0
+	for _, s := range bm {
+		maxBM = math.Max(maxBM, s)
+	}
+
+	var sims []float64
+	if r != nil && r.Provider != nil && strings.TrimSpace(q.Text) != "" {
+		sims = r.similarities(ctx, entries, q.Text)
+	}
+
+	emptyQuery := len(queryTerms) == 0
+	skill := strings.ToLower(strings.TrimSpace(q.Skill))
+
+	out := make([]ScoredEntry, 0, len(entries))
+	for i, e := range entries {
+		if strings.TrimSpace(e.Content) == "" {
+			continue
+		}
+		text := e.Content
+		if q.MaxChars > 0 && len(text) > q.MaxChars {
+			text = text[:q.MaxChars]
+		}
+		se := ScoredEntry{Entry: e, Tokens: estimateTokens(text)}
+		if maxBM > 0 {
+			se.BM25 = bm[i] / maxBM
+		}
+		if sims != nil {
+			se.Semantic = math.Max(0, sims[i])
+			se.Relevance = 0.5*se.BM25 + 0.5*se.Semantic
+		} else {
+			se.Relevance = se.BM25
+		}
+		if e.Type == api.MemoryPreference {
+			se.Relevance = math.Max(se.Relevance, preferenceBaseRelevance)
+		}
+		if skill != "" && hasSkillTag(e, skill) {
+			se.Relevance += skillTagBoost
+		}
+		if emptyQuery {
+			// Nothing to match against: fall back to recency/usage ordering.
+			se.Relevance = math.Max(se.Relevance, 0.1)
+		}
+		if se.Relevance <= 0 {
+			continue
+		}
+
+		age := now.Sub(LastActivity(e))
+		if age < 0 {
+			age = 0
+		}
+		se.Recency = math.Pow(0.5, float64(age)/float64(halfLife))
+		hits := math.Log1p(float64(e.Hits))
+		se.Usage = hits / (1 + hits)
+
+		se.Score = se.Relevance * (0.7 + 0.2*se.Recency + 0.1*se.Usage)
+		out = append(out, se)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score == out[j].Score {
+			return out[i].Entry.ID < out[j].Entry.ID
+		}
+		return out[i].Score > out[j].Score
+	})
+
+	return applyBudget(out, q.TokenBudget, q.Limit)
+}
+
+func (r *Retriever) similarities(ctx context.Context, entries []api.MemoryEntry, query string) []float64 {
+	texts := make([]string, len(entries))
+	for i, e := range entries {
+		texts[i] = entryText(e)
+	}
+	vecs, err := r.Cache.Embed(ctx, r.Provider, texts)
+	if err != nil {
+		return nil
+	}
+	qv, err := r.Provider.Embed(ctx, []string{query})
+	if err != nil || len(qv) == 0 {
+		return nil
+	}
+	out := make([]float64, len(entries))
+	for i := range entries {
+		out[i] = embed.Cosine(qv[0], vecs[i])
+	}
+	return out
+}
+
+// applyBudget keeps the best entries that fit into the token budget and limit.
+func applyBudget(ranked []ScoredEntry, budget, limit int) []ScoredEntry {
+	if budget <= 0 && limit <= 0 {
+		return ranked
+	}
+	out := ranked[:0:0]
+	used := 0
+	for _, se := range ranked {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		if budget > 0 && used+se.Tokens > budget {
+			continue // a smaller, lower-ranked entry may still fit
+		}
+		used += se.Tokens
+		out = append(out, se)
+	}
+	return out
+}
+
+// bm25Scores computes Okapi BM25 for each document against the query terms.
+func bm25Scores(docs [][]string, query []string) []float64 {
+	scores := make([]float64, len(docs))
+	if len(query) == 0 || len(docs) == 0 {
+		return scores
+	}
+
+	df := make(map[string]int)
+	totalLen := 0
+	tfs := make([]map[string]int, len(docs))
+	for i, d := range docs {
+		totalLen += len(d)
+		tf := make(map[string]int, len(d))
+		for _, t := range d {
+			tf[t]++
+		}
+		tfs[i] = tf
+		for t := range tf {
+			df[t]++
+		}
+	}
+	avgLen := float64(totalLen) / float64(len(docs))
+	if avgLen == 0 {
+		return scores
+	}
+
+	n := float64(len(docs))
+	seen := make(map[string]bool, len(query))
+	for _, t := range query {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if df[t] == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+		for i, tf := range tfs {
+			f := float64(tf[t])
+			if f == 0 {
+				continue
+			}
+			dl := float64(len(docs[i]))
+			scores[i] += idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*dl/avgLen))
+		}
+	}
+	return scores
+}
+
+// Similarity returns the Jaccard overlap of the two texts' terms in [0,1].
+// It is used to detect near-duplicate memories before proposing or adding them.
+func Similarity(a, b string) float64 {
+	ta, tb := termSet(a), termSet(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	inter := 0
+	for t := range ta {
+		if tb[t] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(ta)+len(tb)-inter)
+}
+
+func termSet(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, t := range embed.Tokenize(s) {
+		out[t] = true
+	}
+	return out
+}
+
+func entryText(e api.MemoryEntry) string {
+	return strings.TrimSpace(e.Content + " " + strings.Join(e.Tags, " "))
+}
+
+func hasSkillTag(e api.MemoryEntry, skill string) bool {
+	for _, t := range e.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == skill || t == "skill:"+skill {
+			return true
+		}
+	}
+	return false
+}
+
+// estimateTokens approximates token count: ~4 bytes per token for ASCII text,
+// one token per character for CJK and other multi-byte scripts.
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
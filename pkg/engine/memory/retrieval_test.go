@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestRetriever_RanksByRelevanceAndAppliesBudget(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []api.MemoryEntry{
+		{ID: "go", Type: api.MemoryFact, Source: api.MemorySourceProject, Content: "The build uses go 1.24 and make native", UpdatedAt: now},
+		{ID: "db", Type: api.MemoryFact, Source: api.MemorySourceProject, Content: "Postgres runs on port 5433 in docker compose", UpdatedAt: now},
+		{ID: "lang", Type: api.MemoryPreference, Source: api.MemorySourceUser, Content: "Reply in Chinese", UpdatedAt: now},
+		{ID: "old-db", Type: api.MemoryFact, Source: api.MemorySourceProject, Content: "Postgres runs on port 5432 in docker compose", UpdatedAt: now.Add(-365 * 24 * time.Hour)},
+		{ID: "big", Type: api.MemoryFact, Source: api.MemorySourceProject, Content: "postgres " + strings.Repeat("x", 4000), UpdatedAt: now},
+	}
+	r := &Retriever{Now: func() time.Time { return now }}
+
+	got := r.Rank(context.Background(), entries, Query{Text: "which postgres port?", TokenBudget: 200})
+	ids := make([]string, 0, len(got))
+	for _, se := range got {
+		ids = append(ids, se.Entry.ID)
+	}
+
+	if len(ids) == 0 || ids[0] != "db" {
+		t.Fatalf("expected db first, got %v", ids)
+	}
+	pos := map[string]int{}
+	for i, id := range ids {
+		pos[id] = i
+	}
+	if _, ok := pos["go"]; ok {
+		t.Fatalf("expected unrelated fact to be dropped, got %v", ids)
+	}
+	if _, ok := pos["lang"]; !ok {
+		t.Fatalf("expected preference to stay eligible, got %v", ids)
+	}
+	if p, ok := pos["old-db"]; !ok || p < pos["db"] {
+		t.Fatalf("expected stale entry to rank below fresh one, got %v", ids)
+	}
+	if _, ok := pos["big"]; ok {
+		t.Fatalf("expected oversized entry to be cut by token budget, got %v", ids)
+	}
+
+	// Only the injected prefix counts against the budget.
+	got = r.Rank(context.Background(), entries, Query{Text: "which postgres port?", TokenBudget: 200, MaxChars: 200})
+	found := false
+	for _, se := range got {
+		found = found || se.Entry.ID == "big"
+	}
+	if !found {
+		t.Fatalf("expected the clipped entry to fit the budget, got %+v", got)
+	}
+}
+
+func TestStructuredManager_MarkUsedAndStale(t *testing.T) {
+	ctx := context.Background()
+	m := NewStructuredManager(t.TempDir())
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	for _, e := range []api.MemoryEntry{
+		{ID: "a", Type: api.MemoryFact, Source: api.MemorySourceProject, Content: "alpha", CreatedAt: old},
+		{ID: "b", Type: api.MemoryFact, Source: api.MemorySourceUser, Content: "beta", CreatedAt: old},
+	} {
+		if err := m.Add(ctx, e); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	// Add stamps UpdatedAt; backdate both so only usage distinguishes them.
+	for _, src := range []api.MemorySource{api.MemorySourceProject, api.MemorySourceUser} {
+		entries, _ := m.load(src)
+		for i := range entries {
+			entries[i].UpdatedAt = old
+		}
+		if err := m.save(src, entries); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	if err := m.MarkUsed(ctx, []string{"a"}); err != nil {
+		t.Fatalf("mark used: %v", err)
+	}
+	project, _ := m.List(ctx, api.MemorySourceProject)
+	if project[0].Hits != 1 || project[0].LastUsed == nil {
+		t.Fatalf("expected usage to be recorded, got %+v", project[0])
+	}
+
+	// Updates through the tool must not reset usage.
+	upd := project[0]
+	upd.LastUsed = nil
+	upd.Hits = 0
+	upd.Content = "alpha v2"
+	if err := m.Update(ctx, upd); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	project, _ = m.List(ctx, api.MemorySourceProject)
+	if project[0].Hits != 1 {
+		t.Fatalf("expected hits preserved on update, got %d", project[0].Hits)
+	}
+
+	stale, err := m.Stale(ctx, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("stale: %v", err)
+	}
+	if len(stale) != 1 || stale[0].ID != "b" {
+		t.Fatalf("expected only b to be stale, got %+v", stale)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +18,7 @@ import (
 // It is intentionally simple: a small JSON file per source with atomic writes.
 type StructuredManager struct {
 	workspaceRoot string
+	retriever     *Retriever
 	mu            sync.Mutex
 }
 
@@ -121,10 +123,17 @@ func (m *StructuredManager) Update(ctx context.Context, entry api.MemoryEntry) e
 			continue
 		}
 		found = true
-		created := entries[i].CreatedAt
+		prev := entries[i]
 		entries[i] = entry
 		if entries[i].CreatedAt.IsZero() {
-			entries[i].CreatedAt = created
+			entries[i].CreatedAt = prev.CreatedAt
+		}
+		// Usage tracking is owned by the manager; keep it unless the caller set it.
+		if entries[i].LastUsed == nil {
+			entries[i].LastUsed = prev.LastUsed
+		}
+		if entries[i].Hits == 0 {
+			entries[i].Hits = prev.Hits
 		}
 		entries[i].UpdatedAt = now
 		break
@@ -160,6 +169,54 @@ func (m *StructuredManager) Delete(ctx context.Context, id string) error {
 	return fmt.Errorf("memory entry not found: %s", id)
 }
 
+// Move stores entry under entry.Source and removes it from the from source, keeping
+// its ID and usage tracking.
+func (m *StructuredManager) Move(ctx context.Context, entry api.MemoryEntry, from api.MemorySource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry.Source != api.MemorySourceUser && entry.Source != api.MemorySourceProject {
+		return fmt.Errorf("invalid memory source: %q", entry.Source)
+	}
+	if entry.Source == from {
+		return fmt.Errorf("memory entry already in %s: %s", from, entry.ID)
+	}
+	if strings.TrimSpace(entry.Content) == "" {
+		return fmt.Errorf("memory content is required")
+	}
+
+	src, err := m.load(from)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, e := range src {
+		if e.ID == entry.ID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("memory entry not found: %s", entry.ID)
+	}
+	dst, err := m.load(entry.Source)
+	if err != nil {
+		return err
+	}
+	for _, e := range dst {
+		if e.ID == entry.ID {
+			return fmt.Errorf("memory entry already exists: %s", entry.ID)
+		}
+	}
+
+	entry.UpdatedAt = time.Now()
+	if err := m.save(entry.Source, append(dst, entry)); err != nil {
+		return err
+	}
+	_, err = m.deleteFrom(from, entry.ID)
+	return err
+}
+
 func (m *StructuredManager) deleteFrom(source api.MemorySource, id string) (bool, error) {
 	entries, err := m.load(source)
 	if err != nil {
@@ -180,6 +237,235 @@ func (m *StructuredManager) deleteFrom(source api.MemorySource, id string) (bool
 	return true, m.save(source, out)
 }
 
+// All returns project entries followed by user entries.
+func (m *StructuredManager) All(ctx context.Context) ([]api.MemoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loadAll()
+}
+
+// Get returns the entry with the given id from either source.
+func (m *StructuredManager) Get(ctx context.Context, id string) (api.MemoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries, err := m.loadAll()
+	if err != nil {
+		return api.MemoryEntry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return api.MemoryEntry{}, fmt.Errorf("memory entry not found: %s", id)
+}
+
+// ImportResult reports what Import did with each incoming entry.
+type ImportResult struct {
+	Added      []api.MemoryEntry `json:"added"`
+	Duplicates []Duplicate       `json:"duplicates,omitempty"` // Keep = stored entry, Drop = skipped incoming entry
+	Invalid    []api.MemoryEntry `json:"invalid,omitempty"`
+}
+
+// Import adds entries, preserving their timestamps and usage. Entries whose id already
+// exists or whose content duplicates a stored entry (Similarity >= threshold) are skipped.
+// With dryRun nothing is written.
+func (m *StructuredManager) Import(ctx context.Context, entries []api.MemoryEntry, threshold float64, dryRun bool) (ImportResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+	var res ImportResult
+
+	existing, err := m.loadAll()
+	if err != nil {
+		return res, err
+	}
+	bySource := map[api.MemorySource][]api.MemoryEntry{}
+	for _, src := range []api.MemorySource{api.MemorySourceProject, api.MemorySourceUser} {
+		if bySource[src], err = m.load(src); err != nil {
+			return res, err
+		}
+	}
+
+	now := time.Now()
+	for i, e := range entries {
+		e.Content = strings.TrimSpace(e.Content)
+		if e.Source == "" {
+			e.Source = api.MemorySourceProject
+		}
+		t, err := ParseType(string(e.Type))
+		if err != nil || e.Content == "" {
+			res.Invalid = append(res.Invalid, e)
+			continue
+		}
+		src, err := ParseSource(string(e.Source))
+		if err != nil {
+			res.Invalid = append(res.Invalid, e)
+			continue
+		}
+		e.Type, e.Source = t, src
+		if dup, ok := findDuplicateOf(e, existing, threshold); ok {
+			res.Duplicates = append(res.Duplicates, dup)
+			continue
+		}
+		if e.ID == "" {
+			e.ID = fmt.Sprintf("mem_%d_%d", now.UnixNano(), i)
+		}
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = now
+		}
+		if e.UpdatedAt.IsZero() {
+			e.UpdatedAt = e.CreatedAt
+		}
+		existing = append(existing, e)
+		bySource[e.Source] = append(bySource[e.Source], e)
+		res.Added = append(res.Added, e)
+	}
+
+	if dryRun || len(res.Added) == 0 {
+		return res, nil
+	}
+	touched := map[api.MemorySource]bool{}
+	for _, e := range res.Added {
+		touched[e.Source] = true
+	}
+	for src := range touched {
+		if err := m.save(src, bySource[src]); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func findDuplicateOf(e api.MemoryEntry, existing []api.MemoryEntry, threshold float64) (Duplicate, bool) {
+	for _, x := range existing {
+		if e.ID != "" && x.ID == e.ID {
+			return Duplicate{Keep: x, Drop: e, Similarity: 1}, true
+		}
+		if sim := Similarity(e.Content, x.Content); sim >= threshold {
+			return Duplicate{Keep: x, Drop: e, Similarity: sim}, true
+		}
+	}
+	return Duplicate{}, false
+}
+
+func (m *StructuredManager) loadAll() ([]api.MemoryEntry, error) {
+	project, err := m.load(api.MemorySourceProject)
+	if err != nil {
+		return nil, err
+	}
+	user, err := m.load(api.MemorySourceUser)
+	if err != nil {
+		return nil, err
+	}
+	return append(project, user...), nil
+}
+
+// SetRetriever configures ranked retrieval (e.g. to enable embeddings).
+func (m *StructuredManager) SetRetriever(r *Retriever) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retriever = r
+}
+
+// Retrieve ranks project and user entries against the query and applies its budget.
+func (m *StructuredManager) Retrieve(ctx context.Context, q Query) ([]ScoredEntry, error) {
+	m.mu.Lock()
+	project, err := m.load(api.MemorySourceProject)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	user, err := m.load(api.MemorySourceUser)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	retriever := m.retriever
+	m.mu.Unlock()
+
+	return retriever.Rank(ctx, append(project, user...), q), nil
+}
+
+// MarkUsed records that entries were injected into a turn (last_used and hit count).
+func (m *StructuredManager) MarkUsed(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	now := time.Now()
+	for _, source := range []api.MemorySource{api.MemorySourceProject, api.MemorySourceUser} {
+		entries, err := m.load(source)
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i := range entries {
+			if !want[entries[i].ID] {
+				continue
+			}
+			t := now
+			entries[i].LastUsed = &t
+			entries[i].Hits++
+			changed = true
+		}
+		if changed {
+			if err := m.save(source, entries); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stale returns entries not used (or updated) within maxAge, oldest first,
+// as candidates for pruning.
+func (m *StructuredManager) Stale(ctx context.Context, maxAge time.Duration) ([]api.MemoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	project, err := m.load(api.MemorySourceProject)
+	if err != nil {
+		return nil, err
+	}
+	user, err := m.load(api.MemorySourceUser)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var out []api.MemoryEntry
+	for _, e := range append(project, user...) {
+		if LastActivity(e).Before(cutoff) {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return LastActivity(out[i]).Before(LastActivity(out[j])) })
+	return out, nil
+}
+
+// LastActivity returns the most recent of last use, update and creation time.
+func LastActivity(e api.MemoryEntry) time.Time {
+	t := e.UpdatedAt
+	if e.LastUsed != nil && e.LastUsed.After(t) {
+		t = *e.LastUsed
+	}
+	if t.IsZero() {
+		t = e.CreatedAt
+	}
+	return t
+}
+
 func matchMemory(query string, e api.MemoryEntry) bool {
 	if strings.Contains(strings.ToLower(e.ID), query) {
 		return true
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Import / Export
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Export formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatJSONL    = "jsonl"
+)
+
+// FormatFromPath guesses the export format from a file extension (default: markdown).
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL
+	default:
+		return FormatMarkdown
+	}
+}
+
+// WriteJSONL writes one JSON entry per line.
+func WriteJSONL(w io.Writer, entries []api.MemoryEntry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJSONL reads entries written by WriteJSONL. Blank lines are ignored.
+func ReadJSONL(r io.Reader) ([]api.MemoryEntry, error) {
+	var out []api.MemoryEntry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var e api.MemoryEntry
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		out = append(out, e)
+	}
+	return out, sc.Err()
+}
+
+// WriteMarkdown writes entries as an editable Markdown document:
+//
+//	## mem_123
+//	- type: fact
+//	- source: project
+//	- tags: db, docker
+//
+//	Postgres runs on port 5433.
+func WriteMarkdown(w io.Writer, entries []api.MemoryEntry) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Memory\n")
+	for _, e := range entries {
+		fmt.Fprintf(bw, "\n## %s\n", e.ID)
+		fmt.Fprintf(bw, "- type: %s\n", e.Type)
+		fmt.Fprintf(bw, "- source: %s\n", e.Source)
+		if len(e.Tags) > 0 {
+			fmt.Fprintf(bw, "- tags: %s\n", strings.Join(e.Tags, ", "))
+		}
+		if !e.CreatedAt.IsZero() {
+			fmt.Fprintf(bw, "- created: %s\n", e.CreatedAt.Format(time.RFC3339))
+		}
+		if !e.UpdatedAt.IsZero() {
+			fmt.Fprintf(bw, "- updated: %s\n", e.UpdatedAt.Format(time.RFC3339))
+		}
+		if e.LastUsed != nil {
+			fmt.Fprintf(bw, "- last_used: %s\n", e.LastUsed.Format(time.RFC3339))
+		}
+		if e.Hits > 0 {
+			fmt.Fprintf(bw, "- hits: %d\n", e.Hits)
+		}
+		fmt.Fprintf(bw, "\n%s\n", strings.TrimSpace(e.Content))
+	}
+	return bw.Flush()
+}
+
+// ReadMarkdown parses documents written by WriteMarkdown. Each "## <id>" heading starts
+// an entry; leading "- key: value" lines are metadata and the rest is the content.
+// An empty id ("## ") lets the importer assign one.
+func ReadMarkdown(r io.Reader) ([]api.MemoryEntry, error) {
+	var (
+		out     []api.MemoryEntry
+		cur     *api.MemoryEntry
+		content []string
+		inMeta  bool
+	)
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		cur.Content = strings.TrimSpace(strings.Join(content, "\n"))
+		out = append(out, *cur)
+		cur, content = nil, nil
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "## ") || line == "##" {
+			flush()
+			cur = &api.MemoryEntry{ID: strings.TrimSpace(strings.TrimPrefix(line, "##"))}
+			inMeta = true
+			continue
+		}
+		if cur == nil {
+			continue // document title and preamble
+		}
+		if inMeta {
+			if key, val, ok := parseMetaLine(line); ok {
+				if err := applyMeta(cur, key, val); err != nil {
+					return nil, fmt.Errorf("entry %q: %v", cur.ID, err)
+				}
+				continue
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			inMeta = false
+		}
+		content = append(content, line)
+	}
+	flush()
+	return out, sc.Err()
+}
+
+func parseMetaLine(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "- ") {
+		return "", "", false
+	}
+	key, val, ok := strings.Cut(strings.TrimPrefix(line, "- "), ":")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.ToLower(strings.TrimSpace(key))
+	switch key {
+	case "type", "source", "tags", "created", "updated", "last_used", "hits":
+		return key, strings.TrimSpace(val), true
+	}
+	return "", "", false
+}
+
+func applyMeta(e *api.MemoryEntry, key, val string) error {
+	switch key {
+	case "type":
+		e.Type = api.MemoryType(strings.ToLower(val))
+	case "source":
+		e.Source = api.MemorySource(strings.ToLower(val))
+	case "tags":
+		e.Tags = nil
+		for _, t := range strings.Split(val, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				e.Tags = append(e.Tags, t)
+			}
+		}
+	case "created", "updated", "last_used":
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", key, err)
+		}
+		switch key {
+		case "created":
+			e.CreatedAt = t
+		case "updated":
+			e.UpdatedAt = t
+		default:
+			e.LastUsed = &t
+		}
+	case "hits":
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid hits: %v", err)
+		}
+		e.Hits = n
+	}
+	return nil
+}
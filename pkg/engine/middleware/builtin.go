@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/memory"
+	planpkg "AgentEngine/pkg/engine/plan"
 	"AgentEngine/pkg/engine/skill"
 )
 
@@ -74,11 +77,37 @@ type MemoryReader interface {
 	List(ctx context.Context, source api.MemorySource) ([]api.MemoryEntry, error)
 }
 
+// MemoryRetriever ranks memory against the current turn. Readers that implement it
+// get relevance-ranked, budget-capped injection instead of a plain listing.
+type MemoryRetriever interface {
+	Retrieve(ctx context.Context, q memory.Query) ([]memory.ScoredEntry, error)
+	MarkUsed(ctx context.Context, ids []string) error
+}
+
+// DefaultMemoryTokenBudget caps the estimated tokens of injected memory.
+const DefaultMemoryTokenBudget = 800
+
+// memoryEntryChars is how much of each entry is injected.
+const memoryEntryChars = 200
+
 // MemoryMiddleware injects memory entries into the prompt.
 // Note: This middleware only READS memory. Writing is done through the update_memory tool.
 type MemoryMiddleware struct {
 	BaseMiddleware
-	Reader MemoryReader
+	Reader      MemoryReader
+	TokenBudget int // 0 = DefaultMemoryTokenBudget
+
+	mu    sync.Mutex
+	turns map[string]memoryTurn // by turn ID: the running turn's retrieval
+}
+
+// memoryTurn caches a turn's ranked memory: BeforeTurn runs before every LLM call of
+// the turn, but retrieval (and embedding the query) is needed once. Entries live while
+// the turn runs; a turn suspended on an approval ranks again when it resumes.
+type memoryTurn struct {
+	sessionID string
+	query     string // the query text and skill the lines were ranked for
+	lines     []string
 }
 
 // NewMemoryMiddleware creates a new memory middleware.
@@ -86,6 +115,7 @@ func NewMemoryMiddleware(reader MemoryReader) *MemoryMiddleware {
 	return &MemoryMiddleware{
 		BaseMiddleware: NewBaseMiddleware("memory"),
 		Reader:         reader,
+		turns:          make(map[string]memoryTurn),
 	}
 }
 
@@ -95,6 +125,107 @@ func (m *MemoryMiddleware) BeforeTurn(ctx context.Context, state *api.State) err
 		return nil
 	}
 
+	var memoryLines []string
+	if retriever, ok := m.Reader.(MemoryRetriever); ok {
+		memoryLines = m.rankedLines(ctx, retriever, state)
+	} else {
+		memoryLines = m.listLines(ctx)
+	}
+
+	if len(memoryLines) == 0 {
+		return nil
+	}
+
+	memoryBlock := fmt.Sprintf(`
+--- MEMORY ---
+%s
+--- END MEMORY ---
+`, strings.Join(memoryLines, "\n"))
+
+	state.SystemPrompt = state.SystemPrompt + memoryBlock
+	return nil
+}
+
+// rankedLines retrieves the entries most relevant to the latest user message and
+// active skill, within the token budget. Retrieval runs once per turn run (again only
+// when a steering message changes the query), and usage is recorded when it does.
+func (m *MemoryMiddleware) rankedLines(ctx context.Context, retriever MemoryRetriever, state *api.State) []string {
+	text := lastUserContent(state.Messages)
+	query := state.ActiveSkill + "\x00" + text
+
+	m.mu.Lock()
+	cached, ok := m.turns[state.TurnID]
+	m.mu.Unlock()
+	if ok && cached.query == query {
+		return cached.lines
+	}
+
+	budget := m.TokenBudget
+	if budget <= 0 {
+		budget = DefaultMemoryTokenBudget
+	}
+	ranked, err := retriever.Retrieve(ctx, memory.Query{
+		Text:        text,
+		Skill:       state.ActiveSkill,
+		TokenBudget: budget,
+		MaxChars:    memoryEntryChars,
+	})
+	if err != nil {
+		return nil
+	}
+
+	lines := make([]string, 0, len(ranked))
+	ids := make([]string, 0, len(ranked))
+	for _, se := range ranked {
+		e := se.Entry
+		lines = append(lines, fmt.Sprintf("- [%s/%s] %s", e.Source, e.ID, truncate(e.Content, memoryEntryChars)))
+		ids = append(ids, e.ID)
+	}
+
+	m.mu.Lock()
+	if m.turns == nil {
+		m.turns = make(map[string]memoryTurn)
+	}
+	if !ok {
+		// A new turn of the session: drop whatever an earlier one left behind.
+		for id, t := range m.turns {
+			if t.sessionID == state.SessionID {
+				delete(m.turns, id)
+			}
+		}
+	}
+	m.turns[state.TurnID] = memoryTurn{sessionID: state.SessionID, query: query, lines: lines}
+	m.mu.Unlock()
+	// Count a hit once per turn run, not again for a steering message.
+	if !ok && len(ids) > 0 {
+		_ = retriever.MarkUsed(ctx, ids)
+	}
+	return lines
+}
+
+// OnEvent drops the turn's cached retrieval when the turn suspends on an approval; it
+// may never be resumed, and memory can change before it is.
+func (m *MemoryMiddleware) OnEvent(ctx context.Context, state *api.State, e api.Event) error {
+	if e.Type == api.EventApproval {
+		m.dropTurn(e.TurnID)
+	}
+	return nil
+}
+
+// AfterTurn drops the turn's cached retrieval.
+func (m *MemoryMiddleware) AfterTurn(ctx context.Context, state *api.State, summary api.TurnSummary) error {
+	m.dropTurn(summary.TurnID)
+	return nil
+}
+
+func (m *MemoryMiddleware) dropTurn(turnID string) {
+	m.mu.Lock()
+	delete(m.turns, turnID)
+	m.mu.Unlock()
+}
+
+// listLines is the fallback for plain readers: project then user entries, capped at 20.
+func (m *MemoryMiddleware) listLines(ctx context.Context) []string {
 	var memoryLines []string
 
 	// Get project memory
@@ -113,23 +244,32 @@ func (m *MemoryMiddleware) BeforeTurn(ctx context.Context, state *api.State) err
 		}
 	}
 
-	if len(memoryLines) == 0 {
-		return nil
-	}
-
 	// Limit injection size
 	if len(memoryLines) > 20 {
 		memoryLines = memoryLines[:20]
 	}
+	return memoryLines
+}
 
-	memoryBlock := fmt.Sprintf(`
---- MEMORY ---
-%s
---- END MEMORY ---
-`, strings.Join(memoryLines, "\n"))
-
-	state.SystemPrompt = state.SystemPrompt + memoryBlock
-	return nil
+// lastUserContent returns the text of the latest user message, including its text
+// parts and the paths of attached files.
+func lastUserContent(messages []api.LLMMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		texts := []string{messages[i].Content}
+		for _, p := range messages[i].Parts {
+			switch p.Type {
+			case api.PartText:
+				texts = append(texts, p.Text)
+			case api.PartFile, api.PartImage:
+				texts = append(texts, p.Path)
+			}
+		}
+		return strings.TrimSpace(strings.Join(texts, "\n"))
+	}
+	return ""
 }
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -168,27 +308,66 @@ func (m *PlanningMiddleware) BeforeTurn(ctx context.Context, state *api.State) e
 		return nil
 	}
 
-	// Build progress summary
-	total := len(plan.Items)
-	done := 0
-	running := 0
-	for _, item := range plan.Items {
-		switch item.Status {
-		case api.PlanDone:
-			done++
-		case api.PlanRunning:
-			running++
+	state.SystemPrompt = state.SystemPrompt + planBlock(plan)
+	return nil
+}
+
+// planBlock renders progress plus the current actionable item and its context,
+// so the model knows what to do next without calling read_todos.
+func planBlock(p *api.PlanPayload) string {
+	counts := planpkg.Counts(p)
+	var b strings.Builder
+	b.WriteString("\n--- PLAN PROGRESS ---\n")
+	fmt.Fprintf(&b, "Total: %d | Done: %d | Running: %d | Pending: %d", len(p.Items), counts[api.PlanDone], counts[api.PlanRunning], counts[api.PlanPending])
+	if n := counts[api.PlanBlocked] + counts[api.PlanErrored]; n > 0 {
+		fmt.Fprintf(&b, " | Blocked/Errored: %d", n)
+	}
+	b.WriteString("\n")
+
+	cur := planpkg.Next(p)
+	if cur == nil {
+		if counts[api.PlanPending]+counts[api.PlanRunning] == 0 {
+			b.WriteString("All open items are finished.\n")
+		} else {
+			b.WriteString("No item is actionable: remaining items wait on blocked or unfinished dependencies.\n")
+		}
+	} else {
+		fmt.Fprintf(&b, "Current item: #%d %s [%s]\n", cur.ID, cur.Text, cur.Status)
+		if anc := planpkg.Ancestors(p, cur.ID); len(anc) > 0 {
+			parts := make([]string, 0, len(anc))
+			for i := len(anc) - 1; i >= 0; i-- {
+				parts = append(parts, fmt.Sprintf("#%d %s", anc[i].ID, anc[i].Text))
+			}
+			fmt.Fprintf(&b, "Part of: %s\n", strings.Join(parts, " > "))
+		}
+		for _, dep := range cur.DependsOn {
+			if d := planpkg.Find(p, dep); d != nil {
+				fmt.Fprintf(&b, "Depends on: #%d %s [%s]\n", d.ID, d.Text, d.Status)
+			}
+		}
+		if cur.Notes != "" {
+			fmt.Fprintf(&b, "Notes: %s\n", truncate(cur.Notes, 500))
 		}
 	}
 
-	progressBlock := fmt.Sprintf(`
---- PLAN PROGRESS ---
-Total: %d | Done: %d | Running: %d
---- END PLAN ---
-`, total, done, running)
-
-	state.SystemPrompt = state.SystemPrompt + progressBlock
-	return nil
+	var blocked []string
+	for _, it := range p.Items {
+		if it.Status == api.PlanBlocked || it.Status == api.PlanErrored {
+			line := fmt.Sprintf("#%d %s [%s]", it.ID, it.Text, it.Status)
+			if it.Notes != "" {
+				line += ": " + truncate(it.Notes, 120)
+			}
+			blocked = append(blocked, line)
+		}
+	}
+	if len(blocked) > 0 {
+		b.WriteString("Blocked: " + strings.Join(blocked, "; ") + "\n")
+	}
+	if cur != nil {
+		b.WriteString("Mark the current item running/done with write_todos (mode=patch) as you progress.\n")
+	}
+	b.WriteString("--- END PLAN ---\n")
+	return b.String()
 }
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/memory"
 )
 
 type stubSkillIndex struct {
@@ -50,3 +51,105 @@ func TestSkillsMiddleware_AppendsExecutionRules(t *testing.T) {
 		t.Fatalf("missing execution rules: %q", state.SystemPrompt)
 	}
 }
+
+type countingRetriever struct {
+	retrieves, marks int
+	query            memory.Query
+}
+
+func (r *countingRetriever) List(ctx context.Context, source api.MemorySource) ([]api.MemoryEntry, error) {
+	return nil, nil
+}
+
+func (r *countingRetriever) Retrieve(ctx context.Context, q memory.Query) ([]memory.ScoredEntry, error) {
+	r.retrieves++
+	r.query = q
+	return []memory.ScoredEntry{{Entry: api.MemoryEntry{ID: "m1", Source: api.MemorySourceProject, Content: "use make native"}}}, nil
+}
+
+func (r *countingRetriever) MarkUsed(ctx context.Context, ids []string) error {
+	r.marks++
+	return nil
+}
+
+func TestMemoryMiddleware_RetrievesOncePerTurn(t *testing.T) {
+	ctx := context.Background()
+	r := &countingRetriever{}
+	mw := NewMemoryMiddleware(r)
+
+	for i := 0; i < 3; i++ {
+		state := &api.State{
+			SessionID:    "s1",
+			TurnID:       "t1",
+			SystemPrompt: "BASE",
+			Messages:     []api.LLMMessage{{Role: "user", Content: "how do I build?"}},
+		}
+		if err := mw.BeforeTurn(ctx, state); err != nil {
+			t.Fatalf("BeforeTurn error: %v", err)
+		}
+		if !strings.Contains(state.SystemPrompt, "use make native") {
+			t.Fatalf("expected memory injected on call %d: %q", i, state.SystemPrompt)
+		}
+	}
+	if r.retrieves != 1 || r.marks != 1 {
+		t.Fatalf("expected one retrieval and one usage mark, got %d and %d", r.retrieves, r.marks)
+	}
+	if r.query.MaxChars != memoryEntryChars {
+		t.Fatalf("expected the budget to count the injected %d chars, got %d", memoryEntryChars, r.query.MaxChars)
+	}
+
+	state := &api.State{SessionID: "s1", TurnID: "t1"}
+	if err := mw.AfterTurn(ctx, state, api.TurnSummary{SessionID: "s1", TurnID: "t1"}); err != nil {
+		t.Fatalf("AfterTurn error: %v", err)
+	}
+	if len(mw.turns) != 0 {
+		t.Fatalf("expected the turn cache cleared, got %v", mw.turns)
+	}
+}
+
+func TestMemoryMiddleware_DropsSuspendedAndStaleTurns(t *testing.T) {
+	ctx := context.Background()
+	r := &countingRetriever{}
+	mw := NewMemoryMiddleware(r)
+	before := func(turnID string) {
+		state := &api.State{SessionID: "s1", TurnID: turnID, Messages: []api.LLMMessage{{Role: "user", Content: "how do I build?"}}}
+		if err := mw.BeforeTurn(ctx, state); err != nil {
+			t.Fatalf("BeforeTurn error: %v", err)
+		}
+	}
+
+	// A turn suspended on an approval ranks again when it resumes.
+	before("t1")
+	_ = mw.OnEvent(ctx, nil, api.Event{Type: api.EventApproval, TurnID: "t1"})
+	if len(mw.turns) != 0 {
+		t.Fatalf("expected the suspended turn dropped, got %v", mw.turns)
+	}
+	before("t1")
+	if r.retrieves != 2 {
+		t.Fatalf("expected the resumed turn ranked again, got %d retrievals", r.retrieves)
+	}
+
+	// A turn that never finished is dropped when the session's next turn starts.
+	before("t2")
+	if _, ok := mw.turns["t1"]; ok || len(mw.turns) != 1 {
+		t.Fatalf("expected only the new turn cached, got %v", mw.turns)
+	}
+}
+
+func TestMemoryMiddleware_QueriesAttachmentOnlyMessages(t *testing.T) {
+	r := &countingRetriever{}
+	mw := NewMemoryMiddleware(r)
+	state := &api.State{SessionID: "s1", TurnID: "t1", Messages: []api.LLMMessage{{
+		Role: "user",
+		Parts: []api.ContentPart{
+			{Type: api.PartText, Text: "why does this fail?"},
+			{Type: api.PartFile, Path: "Makefile", Text: "native:\n\tgo build"},
+		},
+	}}}
+	if err := mw.BeforeTurn(context.Background(), state); err != nil {
+		t.Fatalf("BeforeTurn error: %v", err)
+	}
+	if r.query.Text != "why does this fail?\nMakefile" {
+		t.Fatalf("expected the parts in the query, got %q", r.query.Text)
+	}
+}
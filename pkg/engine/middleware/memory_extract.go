@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/memory"
+	"AgentEngine/pkg/logger"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Memory Extraction Middleware
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// MemoryExtractor proposes memory candidates from a finished turn's transcript.
+type MemoryExtractor interface {
+	ExtractMemories(ctx context.Context, transcript []api.LLMMessage, existing []api.MemoryEntry) ([]api.MemoryCandidate, error)
+}
+
+// DefaultMaxMemoryCandidates caps proposals per turn.
+const DefaultMaxMemoryCandidates = 5
+
+// MemoryExtractionMiddleware asks the extractor for new memories after each completed
+// turn and emits them as a reviewable EventMemoryProposal. Nothing is stored until the
+// user accepts or edits a candidate.
+type MemoryExtractionMiddleware struct {
+	BaseMiddleware
+	Extractor          MemoryExtractor
+	Reader             MemoryReader
+	DuplicateThreshold float64 // 0 = memory.DefaultDuplicateThreshold
+	MaxCandidates      int     // 0 = DefaultMaxMemoryCandidates
+}
+
+// NewMemoryExtractionMiddleware creates a memory extraction middleware.
+func NewMemoryExtractionMiddleware(extractor MemoryExtractor, reader MemoryReader) *MemoryExtractionMiddleware {
+	return &MemoryExtractionMiddleware{
+		BaseMiddleware: NewBaseMiddleware("memory_extraction"),
+		Extractor:      extractor,
+		Reader:         reader,
+	}
+}
+
+// AfterTurn proposes memories for turns that completed normally. Autopilot
+// continuation turns are skipped: they only work through the plan.
+func (m *MemoryExtractionMiddleware) AfterTurn(ctx context.Context, state *api.State, summary api.TurnSummary) error {
+	if m.Extractor == nil || state == nil || state.Emit == nil || summary.Outcome != api.TurnDone || summary.Continuation {
+		return nil
+	}
+
+	transcript := turnTranscript(state.Messages, summary.AssistantText)
+	if len(transcript) == 0 {
+		return nil
+	}
+
+	var existing []api.MemoryEntry
+	if m.Reader != nil {
+		for _, src := range []api.MemorySource{api.MemorySourceUser, api.MemorySourceProject} {
+			entries, err := m.Reader.List(ctx, src)
+			if err != nil {
+				continue
+			}
+			existing = append(existing, entries...)
+		}
+	}
+
+	candidates, err := m.Extractor.ExtractMemories(ctx, transcript, existing)
+	if err != nil {
+		logger.Warn("Memory", "Memory extraction failed", map[string]interface{}{
+			"session_id": summary.SessionID,
+			"error":      err.Error(),
+		})
+		return nil
+	}
+
+	candidates = m.filter(candidates, existing)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	state.Emit(ctx, api.Event{
+		Type: api.EventMemoryProposal,
+		MemoryProposal: &api.MemoryProposalPayload{
+			RequestID:  fmt.Sprintf("memreq_%d", time.Now().UnixNano()),
+			Candidates: candidates,
+		},
+	})
+	return nil
+}
+
+// filter normalizes candidates, drops near-duplicates and caps the batch.
+func (m *MemoryExtractionMiddleware) filter(candidates []api.MemoryCandidate, existing []api.MemoryEntry) []api.MemoryCandidate {
+	threshold := m.DuplicateThreshold
+	if threshold <= 0 {
+		threshold = memory.DefaultDuplicateThreshold
+	}
+	limit := m.MaxCandidates
+	if limit <= 0 {
+		limit = DefaultMaxMemoryCandidates
+	}
+
+	seen := make([]string, 0, len(existing)+len(candidates))
+	for _, e := range existing {
+		seen = append(seen, e.Content)
+	}
+
+	var out []api.MemoryCandidate
+	for _, c := range candidates {
+		c.Content = strings.TrimSpace(c.Content)
+		if c.Content == "" {
+			continue
+		}
+		t, err := memory.ParseType(string(c.Type))
+		if err != nil {
+			continue
+		}
+		c.Type = t
+		if c.Source != api.MemorySourceUser && c.Source != api.MemorySourceProject {
+			c.Source = api.MemorySourceProject
+			if c.Type == api.MemoryPreference {
+				c.Source = api.MemorySourceUser
+			}
+		}
+		if isDuplicateMemory(c.Content, seen, threshold) {
+			continue
+		}
+		seen = append(seen, c.Content)
+
+		c.CandidateID = fmt.Sprintf("cand_%d", len(out)+1)
+		out = append(out, c)
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+func isDuplicateMemory(content string, seen []string, threshold float64) bool {
+	norm := strings.ToLower(content)
+	for _, s := range seen {
+		if strings.ToLower(strings.TrimSpace(s)) == norm {
+			return true
+		}
+		if memory.Similarity(content, s) >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// turnTranscript returns the messages of the latest turn (from the last user message),
+// appending the final assistant text if the session has not recorded it yet.
+func turnTranscript(messages []api.LLMMessage, assistantText string) []api.LLMMessage {
+	start := -1
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	out := append([]api.LLMMessage(nil), messages[start:]...)
+
+	text := strings.TrimSpace(assistantText)
+	if text != "" {
+		last := out[len(out)-1]
+		if last.Role != "assistant" || strings.TrimSpace(last.Content) != text {
+			out = append(out, api.LLMMessage{Role: "assistant", Content: text})
+		}
+	}
+	return out
+}
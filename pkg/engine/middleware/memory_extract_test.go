@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+type stubExtractor struct {
+	candidates []api.MemoryCandidate
+	transcript []api.LLMMessage
+}
+
+func (s *stubExtractor) ExtractMemories(ctx context.Context, transcript []api.LLMMessage, existing []api.MemoryEntry) ([]api.MemoryCandidate, error) {
+	s.transcript = transcript
+	return s.candidates, nil
+}
+
+type stubMemoryReader map[api.MemorySource][]api.MemoryEntry
+
+func (s stubMemoryReader) List(ctx context.Context, source api.MemorySource) ([]api.MemoryEntry, error) {
+	return s[source], nil
+}
+
+func TestMemoryExtractionMiddleware_ProposesDedupedCandidates(t *testing.T) {
+	extractor := &stubExtractor{candidates: []api.MemoryCandidate{
+		{Type: api.MemoryFact, Content: "Postgres runs on port 5433 in docker compose"},
+		{Type: api.MemoryPreference, Content: "Reply in Chinese"},
+		{Type: api.MemoryDecision, Content: "Use sqlc instead of gorm for the data layer"},
+		{Type: api.MemoryDecision, Content: "use sqlc instead of gorm for the data layer"},
+		{Type: "todo", Content: "finish chapter 3"},
+		{Type: "Lesson", Content: "Run migrations before the integration tests"},
+	}}
+	reader := stubMemoryReader{
+		api.MemorySourceProject: {{ID: "db", Type: api.MemoryFact, Source: api.MemorySourceProject, Content: "Postgres runs on port 5433 in docker compose."}},
+	}
+	m := NewMemoryExtractionMiddleware(extractor, reader)
+
+	var emitted []api.Event
+	state := &api.State{
+		Messages: []api.LLMMessage{
+			{Role: "user", Content: "old question"},
+			{Role: "assistant", Content: "old answer"},
+			{Role: "user", Content: "set up the data layer"},
+		},
+		Emit: func(ctx context.Context, e api.Event) { emitted = append(emitted, e) },
+	}
+	summary := api.TurnSummary{Outcome: api.TurnDone, AssistantText: "Done, using sqlc."}
+	if err := m.AfterTurn(context.Background(), state, summary); err != nil {
+		t.Fatalf("AfterTurn error: %v", err)
+	}
+
+	if len(extractor.transcript) != 2 || extractor.transcript[1].Content != "Done, using sqlc." {
+		t.Fatalf("expected current turn transcript with final reply, got %+v", extractor.transcript)
+	}
+	if len(emitted) != 1 || emitted[0].Type != api.EventMemoryProposal || emitted[0].MemoryProposal == nil {
+		t.Fatalf("expected one memory proposal event, got %+v", emitted)
+	}
+	got := emitted[0].MemoryProposal.Candidates
+	if len(got) != 3 {
+		t.Fatalf("expected 3 candidates after dedup, got %+v", got)
+	}
+	if got[0].Type != api.MemoryPreference || got[0].Source != api.MemorySourceUser || got[0].CandidateID != "cand_1" {
+		t.Fatalf("unexpected first candidate: %+v", got[0])
+	}
+	if got[1].Type != api.MemoryDecision || got[1].Source != api.MemorySourceProject {
+		t.Fatalf("unexpected second candidate: %+v", got[1])
+	}
+	if got[2].Type != api.MemoryLesson {
+		t.Fatalf("expected the type normalized, got %+v", got[2])
+	}
+
+	// Canceled or failed turns propose nothing.
+	emitted = nil
+	summary.Outcome = api.TurnCanceled
+	_ = m.AfterTurn(context.Background(), state, summary)
+	if len(emitted) != 0 {
+		t.Fatalf("expected no proposal for canceled turn, got %+v", emitted)
+	}
+
+	// So do autopilot continuation turns.
+	summary.Outcome, summary.Continuation = api.TurnDone, true
+	_ = m.AfterTurn(context.Background(), state, summary)
+	if len(emitted) != 0 {
+		t.Fatalf("expected no proposal for continuation turn, got %+v", emitted)
+	}
+}
@@ -0,0 +1,307 @@
+// Package plan provides tree, dependency and transition rules for api.PlanPayload.
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Structure
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// ValidStatus reports whether s is a known plan item status.
+func ValidStatus(s api.PlanItemStatus) bool {
+	switch s {
+	case api.PlanPending, api.PlanRunning, api.PlanDone, api.PlanBlocked, api.PlanErrored:
+		return true
+	}
+	return false
+}
+
+// Validate checks structural integrity: unique positive IDs, known statuses,
+// existing parents/dependencies, and no parent or dependency cycles.
+func Validate(p *api.PlanPayload) error {
+	if p == nil {
+		return nil
+	}
+	byID := make(map[int]*api.PlanItem, len(p.Items))
+	for i := range p.Items {
+		it := &p.Items[i]
+		if it.ID <= 0 {
+			return fmt.Errorf("item %q: id must be a positive integer", it.Text)
+		}
+		if byID[it.ID] != nil {
+			return fmt.Errorf("duplicate item ID: %d", it.ID)
+		}
+		if !ValidStatus(it.Status) {
+			return fmt.Errorf("item %d: invalid status %q", it.ID, it.Status)
+		}
+		byID[it.ID] = it
+	}
+
+	for _, it := range p.Items {
+		if it.ParentID != 0 {
+			if it.ParentID == it.ID {
+				return fmt.Errorf("item %d: cannot be its own parent", it.ID)
+			}
+			if byID[it.ParentID] == nil {
+				return fmt.Errorf("item %d: parent %d does not exist", it.ID, it.ParentID)
+			}
+		}
+		for _, dep := range it.DependsOn {
+			if dep == it.ID {
+				return fmt.Errorf("item %d: cannot depend on itself", it.ID)
+			}
+			if byID[dep] == nil {
+				return fmt.Errorf("item %d: dependency %d does not exist", it.ID, dep)
+			}
+		}
+	}
+
+	// Parent chains must terminate.
+	for _, it := range p.Items {
+		seen := map[int]bool{it.ID: true}
+		for cur := it.ParentID; cur != 0; cur = byID[cur].ParentID {
+			if seen[cur] {
+				return fmt.Errorf("item %d: parent cycle", it.ID)
+			}
+			seen[cur] = true
+		}
+	}
+
+	// Dependency graph must be acyclic.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	mark := make(map[int]int, len(p.Items))
+	var visit func(id int) error
+	visit = func(id int) error {
+		switch mark[id] {
+		case visiting:
+			return fmt.Errorf("dependency cycle through item %d", id)
+		case visited:
+			return nil
+		}
+		mark[id] = visiting
+		for _, dep := range byID[id].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		mark[id] = visited
+		return nil
+	}
+	for _, it := range p.Items {
+		if err := visit(it.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckTransitions validates status changes from prev to next (prev may be nil).
+// Only items whose status changed (or that are new) are checked:
+//   - an item cannot be running or done until every dependency is done;
+//   - an item cannot be done while any of its sub-items is unfinished.
+func CheckTransitions(prev, next *api.PlanPayload) error {
+	if next == nil {
+		return nil
+	}
+	before := make(map[int]api.PlanItemStatus)
+	if prev != nil {
+		for _, it := range prev.Items {
+			before[it.ID] = it.Status
+		}
+	}
+	byID := index(next)
+
+	var errs []string
+	for _, it := range next.Items {
+		if old, ok := before[it.ID]; ok && old == it.Status {
+			continue
+		}
+		if it.Status != api.PlanRunning && it.Status != api.PlanDone {
+			continue
+		}
+		for _, dep := range it.DependsOn {
+			if d := byID[dep]; d != nil && d.Status != api.PlanDone {
+				errs = append(errs, fmt.Sprintf("item %d cannot be %s: dependency %d is %s", it.ID, it.Status, dep, d.Status))
+			}
+		}
+		if it.Status == api.PlanDone {
+			for _, c := range Children(next, it.ID) {
+				if c.Status != api.PlanDone {
+					errs = append(errs, fmt.Sprintf("item %d cannot be done: sub-item %d is %s", it.ID, c.ID, c.Status))
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("illegal transition: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Children returns the direct sub-items of id (0 = top-level items), in plan order.
+func Children(p *api.PlanPayload, id int) []api.PlanItem {
+	if p == nil {
+		return nil
+	}
+	var out []api.PlanItem
+	for _, it := range p.Items {
+		if it.ParentID == id {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// Walk visits items depth-first in plan order, passing each item's depth (0 = top level).
+// Items whose parent is missing are treated as top-level.
+func Walk(p *api.PlanPayload, fn func(it api.PlanItem, depth int)) {
+	if p == nil {
+		return
+	}
+	byID := index(p)
+	var visit func(parent, depth int)
+	visit = func(parent, depth int) {
+		for _, it := range p.Items {
+			if it.ParentID != parent || it.ID == parent {
+				continue
+			}
+			fn(it, depth)
+			visit(it.ID, depth+1)
+		}
+	}
+	visit(0, 0)
+	for _, it := range p.Items {
+		if it.ParentID != 0 && byID[it.ParentID] == nil {
+			fn(it, 0)
+			visit(it.ID, 1)
+		}
+	}
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Progress
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Next returns the item to work on: the first running leaf in tree order, otherwise
+// the first pending leaf whose dependencies are all done. Parents are never actionable
+// while they have unfinished sub-items. Returns nil when nothing is actionable.
+func Next(p *api.PlanPayload) *api.PlanItem {
+	if p == nil {
+		return nil
+	}
+	byID := index(p)
+	var running, pending *api.PlanItem
+	Walk(p, func(it api.PlanItem, _ int) {
+		if !Ready(p, byID[it.ID]) {
+			return
+		}
+		switch {
+		case it.Status == api.PlanRunning && running == nil:
+			running = byID[it.ID]
+		case it.Status == api.PlanPending && pending == nil:
+			pending = byID[it.ID]
+		}
+	})
+	if running != nil {
+		return running
+	}
+	return pending
+}
+
+// Ready reports whether it is open (pending/running), its dependencies are done,
+// and it has no unfinished sub-items.
+func Ready(p *api.PlanPayload, it *api.PlanItem) bool {
+	if it == nil || (it.Status != api.PlanPending && it.Status != api.PlanRunning) {
+		return false
+	}
+	byID := index(p)
+	for _, dep := range it.DependsOn {
+		if d := byID[dep]; d != nil && d.Status != api.PlanDone {
+			return false
+		}
+	}
+	for _, c := range Children(p, it.ID) {
+		if c.Status != api.PlanDone {
+			return false
+		}
+	}
+	return true
+}
+
+// Counts returns the number of items per status.
+func Counts(p *api.PlanPayload) map[api.PlanItemStatus]int {
+	out := make(map[api.PlanItemStatus]int)
+	if p == nil {
+		return out
+	}
+	for _, it := range p.Items {
+		out[it.Status]++
+	}
+	return out
+}
+
+// Ancestors returns the parent chain of id, nearest first.
+func Ancestors(p *api.PlanPayload, id int) []api.PlanItem {
+	byID := index(p)
+	var out []api.PlanItem
+	seen := map[int]bool{}
+	for it := byID[id]; it != nil && it.ParentID != 0 && !seen[it.ParentID]; it = byID[it.ParentID] {
+		seen[it.ParentID] = true
+		if parent := byID[it.ParentID]; parent != nil {
+			out = append(out, *parent)
+		}
+	}
+	return out
+}
+
+// Find returns the item with the given id, or nil.
+func Find(p *api.PlanPayload, id int) *api.PlanItem {
+	return index(p)[id]
+}
+
+// NextID returns one more than the highest item ID.
+func NextID(p *api.PlanPayload) int {
+	maxID := 0
+	if p != nil {
+		for _, it := range p.Items {
+			if it.ID > maxID {
+				maxID = it.ID
+			}
+		}
+	}
+	return maxID + 1
+}
+
+// AddEvidence appends tool call IDs to an item's evidence, skipping duplicates.
+func AddEvidence(it *api.PlanItem, ids ...string) {
+	seen := make(map[string]bool, len(it.Evidence))
+	for _, id := range it.Evidence {
+		seen[id] = true
+	}
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			it.Evidence = append(it.Evidence, id)
+		}
+	}
+}
+
+func index(p *api.PlanPayload) map[int]*api.PlanItem {
+	if p == nil {
+		return nil
+	}
+	out := make(map[int]*api.PlanItem, len(p.Items))
+	for i := range p.Items {
+		out[p.Items[i].ID] = &p.Items[i]
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func samplePlan() *api.PlanPayload {
+	return &api.PlanPayload{PlanID: "plan_s", Items: []api.PlanItem{
+		{ID: 1, Text: "outline", Status: api.PlanDone},
+		{ID: 2, Text: "write book", Status: api.PlanPending, DependsOn: []int{1}},
+		{ID: 3, Text: "chapter 1", Status: api.PlanPending, ParentID: 2},
+		{ID: 4, Text: "chapter 2", Status: api.PlanPending, ParentID: 2, DependsOn: []int{3}},
+		{ID: 5, Text: "publish", Status: api.PlanPending, DependsOn: []int{2}},
+	}}
+}
+
+func TestValidate_RejectsCyclesAndDanglingRefs(t *testing.T) {
+	if err := Validate(samplePlan()); err != nil {
+		t.Fatalf("expected valid plan, got %v", err)
+	}
+
+	p := samplePlan()
+	p.Items[0].DependsOn = []int{5}
+	if err := Validate(p); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected dependency cycle error, got %v", err)
+	}
+
+	p = samplePlan()
+	p.Items[1].ParentID = 3
+	if err := Validate(p); err == nil || !strings.Contains(err.Error(), "parent cycle") {
+		t.Fatalf("expected parent cycle error, got %v", err)
+	}
+
+	p = samplePlan()
+	p.Items[4].DependsOn = []int{42}
+	if err := Validate(p); err == nil {
+		t.Fatalf("expected missing dependency error")
+	}
+}
+
+func TestCheckTransitions_EnforcesDependenciesAndSubItems(t *testing.T) {
+	prev := samplePlan()
+
+	// Dependency not done yet.
+	next := samplePlan()
+	next.Items[3].Status = api.PlanDone
+	if err := CheckTransitions(prev, next); err == nil || !strings.Contains(err.Error(), "dependency 3") {
+		t.Fatalf("expected dependency error, got %v", err)
+	}
+
+	// Parent cannot finish before its sub-items.
+	next = samplePlan()
+	next.Items[1].Status = api.PlanDone
+	if err := CheckTransitions(prev, next); err == nil || !strings.Contains(err.Error(), "sub-item") {
+		t.Fatalf("expected sub-item error, got %v", err)
+	}
+
+	// Blocked dependency blocks start.
+	prev.Items[2].Status = api.PlanBlocked
+	next = samplePlan()
+	next.Items[2].Status = api.PlanBlocked
+	next.Items[3].Status = api.PlanRunning
+	if err := CheckTransitions(prev, next); err == nil {
+		t.Fatalf("expected error when dependency is blocked")
+	}
+
+	// Legal: start the first chapter.
+	prev = samplePlan()
+	next = samplePlan()
+	next.Items[2].Status = api.PlanRunning
+	if err := CheckTransitions(prev, next); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNext_PicksActionableLeaf(t *testing.T) {
+	p := samplePlan()
+	if it := Next(p); it == nil || it.ID != 3 {
+		t.Fatalf("expected chapter 1, got %+v", it)
+	}
+
+	p.Items[2].Status = api.PlanDone
+	p.Items[3].Status = api.PlanRunning
+	if it := Next(p); it == nil || it.ID != 4 {
+		t.Fatalf("expected running chapter 2, got %+v", it)
+	}
+
+	p.Items[3].Status = api.PlanDone
+	if it := Next(p); it == nil || it.ID != 2 {
+		t.Fatalf("expected parent once sub-items are done, got %+v", it)
+	}
+
+	p.Items[1].Status = api.PlanBlocked
+	if it := Next(p); it != nil {
+		t.Fatalf("expected nothing actionable behind a blocked item, got %+v", it)
+	}
+}
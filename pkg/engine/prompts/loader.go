@@ -78,6 +78,9 @@ func (l *Loader) ClearCache() {
 const (
 	CompressSummary   = "compress_summary"
 	CompressInjection = "compress_injection"
+	MemoryExtract     = "memory_extract"
+	PlanContinue      = "plan_continue"
+	ToolCalling       = "tool_calling"
 )
 
 // DefaultLoader is a loader with no project root (uses embedded prompts only).
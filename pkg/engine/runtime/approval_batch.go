@@ -0,0 +1,215 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/tools"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Batch Approvals
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// When one assistant message makes several tool calls, the turn runs them in order
+// until the first call that needs approval. That call and everything after it are
+// queued as one batch: the user decides each call needing approval in a single
+// prompt, and Resume runs the queue in the original order.
+
+// rejectedToolResult is recorded for tool calls the user rejected.
+const rejectedToolResult = "rejected by user"
+
+// suspendForApproval emits one approval for the queued calls and saves them as the
+// session's pending state, so the batch survives a restart.
+func (r *TurnRunner) suspendForApproval(ctx context.Context, batch []api.ToolCallPayload) error {
+	var items []api.ToolCallPayload
+	for _, call := range batch {
+		if call.NeedApproval {
+			items = append(items, call)
+		}
+	}
+	first := items[0]
+	requestID := generateRequestID()
+	approval := &api.ApprovalPayload{
+		RequestID:  requestID,
+		ToolCallID: first.ToolCallID,
+		ToolCall:   first,
+		Mode:       r.cfg.ApprovalMode,
+	}
+	if len(items) > 1 {
+		approval.Items = items
+	}
+	r.emit(ctx, api.Event{Type: api.EventApproval, Approval: approval})
+
+	r.session.Pending = &api.PendingApproval{
+		TurnID:    r.turnID,
+		RequestID: requestID,
+		ToolCall:  first,
+		Preview:   first.Preview,
+		CreatedAt: time.Now(),
+		Seq:       r.seq,
+	}
+	if len(batch) > 1 {
+		r.session.Pending.Batch = batch
+	}
+	r.tick()
+	usage := r.usage
+	r.session.Pending.Usage = &usage
+	return r.saveSession(ctx)
+}
+
+// checkDecision verifies that the decision's tool call IDs belong to the pending
+// approval.
+func checkDecision(decision api.Decision, pending *api.PendingApproval) error {
+	if decision.RequestID != pending.RequestID {
+		return fmt.Errorf("%s: request ID mismatch", api.ErrApprovalMismatch)
+	}
+	asked := make(map[string]bool)
+	for _, call := range pending.Calls() {
+		if call.NeedApproval {
+			asked[call.ToolCallID] = true
+		}
+	}
+	if decision.ToolCallID != "" && !asked[decision.ToolCallID] {
+		return fmt.Errorf("%s: tool call ID mismatch", api.ErrApprovalMismatch)
+	}
+	for _, item := range decision.Items {
+		if !asked[item.ToolCallID] {
+			return fmt.Errorf("%s: tool call ID mismatch: %s", api.ErrApprovalMismatch, item.ToolCallID)
+		}
+	}
+	return nil
+}
+
+// decisionFor returns the decision for one call of the pending approval.
+func decisionFor(decision api.Decision, pending *api.PendingApproval, toolCallID string) api.ItemDecision {
+	for _, item := range decision.Items {
+		if item.ToolCallID == toolCallID {
+			return item
+		}
+	}
+	target := decision.ToolCallID
+	if target == "" {
+		target = pending.ToolCall.ToolCallID
+	}
+	if decision.Kind == api.DecisionModify && toolCallID != target {
+		return api.ItemDecision{ToolCallID: toolCallID, Kind: api.DecisionApprove}
+	}
+	return api.ItemDecision{ToolCallID: toolCallID, Kind: decision.Kind, ModifiedArgs: decision.ModifiedArgs, Note: decision.Note}
+}
+
+// rejectsAll reports whether the user rejected every call needing approval.
+func rejectsAll(decision api.Decision, pending *api.PendingApproval) bool {
+	for _, call := range pending.Calls() {
+		if !call.NeedApproval {
+			continue
+		}
+		if decisionFor(decision, pending, call.ToolCallID).Kind != api.DecisionReject {
+			return false
+		}
+	}
+	return true
+}
+
+// runToolCall executes a tool call that passed policy checks, emits its result and
+// appends it to the conversation.
+func (r *TurnRunner) runToolCall(ctx context.Context, tool Tool, call api.ToolCallPayload, args, execArgs api.Args) {
+	r.recordToolCall()
+	result := r.executeTool(ctx, call.ToolName, tool, execArgs)
+
+	// Apply engine-side effects for certain system tools.
+	if call.ToolName == "activate_skill" && result.Status == "success" {
+		if name, ok := args["name"].(string); ok && name != "" {
+			r.session.ActiveSkill = name
+		}
+	}
+
+	emitted := result
+	emitted.Parts = eventParts(result.Parts)
+	r.emit(ctx, api.Event{
+		Type: api.EventToolResult,
+		ToolResult: &api.ToolResultPayload{
+			ToolCallID: call.ToolCallID,
+			ToolName:   call.ToolName,
+			Result:     emitted,
+		},
+	})
+	r.session.Messages = append(r.session.Messages, api.LLMMessage{
+		Role:       "tool",
+		Content:    result.Content,
+		Parts:      result.Parts,
+		ToolCallID: call.ToolCallID,
+	})
+}
+
+// skipToolCall records a queued call that did not run (rejected, unknown or denied)
+// so the model sees why.
+func (r *TurnRunner) skipToolCall(ctx context.Context, call api.ToolCallPayload, reason string) {
+	r.emit(ctx, api.Event{
+		Type: api.EventToolResult,
+		ToolResult: &api.ToolResultPayload{
+			ToolCallID: call.ToolCallID,
+			ToolName:   call.ToolName,
+			Result:     api.ToolResult{Status: "error", Error: reason},
+		},
+	})
+	r.session.Messages = append(r.session.Messages, api.LLMMessage{
+		Role:       "tool",
+		Content:    "Skipped: " + reason,
+		ToolCallID: call.ToolCallID,
+	})
+}
+
+// noteModifiedArgs tells the model, in the result of a call the user modified, which
+// arguments changed and passes on the user's note. The call's own message keeps the
+// model's arguments.
+func (r *TurnRunner) noteModifiedArgs(call api.ToolCallPayload, args api.Args, note string) {
+	if len(r.session.Messages) == 0 {
+		return
+	}
+	msg := &r.session.Messages[len(r.session.Messages)-1]
+	if msg.Role != "tool" || msg.ToolCallID != call.ToolCallID {
+		return
+	}
+	text := "[The user edited this call's arguments before it ran"
+	if changed := changedArgs(call.Args, args); len(changed) > 0 {
+		text += "; changed: " + strings.Join(changed, ", ")
+	}
+	text += "."
+	if note = strings.TrimSpace(note); note != "" {
+		text += " Note from the user: " + note
+	}
+	msg.Content = text + "]\n" + msg.Content
+}
+
+// changedArgs returns the sorted names of arguments that differ between before and after.
+func changedArgs(before, after api.Args) []string {
+	var names []string
+	for name, v := range after {
+		if old, ok := before[name]; !ok || !reflect.DeepEqual(old, v) {
+			names = append(names, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateToolArgs checks arguments against a tool's schema, e.g. ones the user edited
+// before approving a call.
+func (e *Engine) ValidateToolArgs(toolName string, args api.Args) error {
+	tool, ok := e.cfg.Tools.Get(toolName)
+	if !ok {
+		return fmt.Errorf("%s: %s", api.ErrToolNotFound, toolName)
+	}
+	return tools.ValidateArgs(tool.Schema(), args)
+}
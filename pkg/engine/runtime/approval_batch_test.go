@@ -0,0 +1,260 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/policy"
+	"AgentEngine/pkg/engine/tools"
+)
+
+// batchLLM writes two files and lists the directory in one message, then answers.
+type batchLLM struct{ requests []LLMRequest }
+
+func (l *batchLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	l.requests = append(l.requests, req)
+	if len(l.requests) == 1 {
+		return &chunkStream{chunks: []LLMChunk{
+			{ToolCall: &api.LLMToolCall{ID: "c1", Name: "write_file", Args: `{"path":"a.txt","content":"a"}`}},
+			{ToolCall: &api.LLMToolCall{ID: "c2", Name: "ls", Args: `{"path":"."}`}},
+			{ToolCall: &api.LLMToolCall{ID: "c3", Name: "write_file", Args: `{"path":"b.txt","content":"b"}`}},
+			{FinishReason: "tool_calls"},
+		}}, nil
+	}
+	return &staticStream{content: "done"}, nil
+}
+
+func newBatchEngine(t *testing.T, ws string, llm LLM) *Engine {
+	t.Helper()
+	reg := tools.NewRegistry()
+	reg.MustRegister(tools.NewWriteFileTool(ws))
+	reg.MustRegister(tools.NewLsTool(ws))
+	eng, err := NewEngine(EngineConfig{
+		LLM:           llm,
+		Tools:         reg,
+		Policy:        policy.NewDefaultPolicy(),
+		WorkspaceRoot: ws,
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	return eng
+}
+
+func TestEngine_BatchApprovalDecidesEachCallAndSurvivesRestart(t *testing.T) {
+	ws := t.TempDir()
+	ctx := context.Background()
+	eng := newBatchEngine(t, ws, &batchLLM{})
+	sid, err := eng.StartSession(ctx, api.StartOptions{ApprovalMode: api.ModeAuto})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	stream, err := eng.Send(ctx, sid, "write both")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	var approvals []*api.ApprovalPayload
+	for _, e := range collectEvents(t, stream) {
+		if e.Type == api.EventApproval {
+			approvals = append(approvals, e.Approval)
+		}
+		if e.Type == api.EventToolResult {
+			t.Fatalf("expected nothing to run before the approval, got %+v", e.ToolResult)
+		}
+	}
+	if len(approvals) != 1 || len(approvals[0].Items) != 2 {
+		t.Fatalf("expected one approval with 2 items, got %+v", approvals)
+	}
+	approval := approvals[0]
+
+	// A new engine on the same workspace resumes from the saved batch.
+	llm := &batchLLM{requests: make([]LLMRequest, 1)}
+	eng = newBatchEngine(t, ws, llm)
+	stream, err = eng.Resume(ctx, sid, api.Decision{
+		Kind:      api.DecisionApprove,
+		RequestID: approval.RequestID,
+		Items:     []api.ItemDecision{{ToolCallID: "c3", Kind: api.DecisionReject}},
+	})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	events := collectEvents(t, stream)
+	if last := events[len(events)-1]; last.Type != api.EventDone || last.Done.Reason != "completed" {
+		t.Fatalf("expected completed turn, got %+v", last)
+	}
+
+	if _, err := os.Stat(filepath.Join(ws, "a.txt")); err != nil {
+		t.Fatalf("expected approved write to run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "b.txt")); err == nil {
+		t.Fatal("expected rejected write to be skipped")
+	}
+	sess, _ := eng.sessionStore.Get(ctx, sid)
+	if sess.Pending != nil {
+		t.Fatalf("expected pending cleared, got %+v", sess.Pending)
+	}
+	var order []string
+	for _, m := range sess.Messages {
+		if m.Role == "tool" {
+			order = append(order, m.ToolCallID)
+			if m.ToolCallID == "c3" && m.Content != "Skipped: "+rejectedToolResult {
+				t.Fatalf("expected rejection recorded, got %q", m.Content)
+			}
+		}
+	}
+	if len(order) != 3 || order[0] != "c1" || order[1] != "c2" || order[2] != "c3" {
+		t.Fatalf("expected tool results in call order, got %v", order)
+	}
+	if len(llm.requests) != 2 {
+		t.Fatalf("expected the turn to continue after the batch, got %d requests", len(llm.requests)-1)
+	}
+}
+
+func TestEngine_BatchApprovalRejectAllEndsTurn(t *testing.T) {
+	ws := t.TempDir()
+	ctx := context.Background()
+	eng := newBatchEngine(t, ws, &batchLLM{})
+	sid, _ := eng.StartSession(ctx, api.StartOptions{ApprovalMode: api.ModeAuto})
+	stream, err := eng.Send(ctx, sid, "write both")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	var requestID string
+	for _, e := range collectEvents(t, stream) {
+		if e.Type == api.EventApproval {
+			requestID = e.Approval.RequestID
+		}
+	}
+
+	if _, err := eng.Resume(ctx, sid, api.Decision{
+		Kind:      api.DecisionReject,
+		RequestID: requestID,
+		Items:     []api.ItemDecision{{ToolCallID: "c2", Kind: api.DecisionApprove}},
+	}); err == nil {
+		t.Fatal("expected a decision for a call without approval to be refused")
+	}
+
+	stream, err = eng.Resume(ctx, sid, api.Decision{Kind: api.DecisionReject, RequestID: requestID})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	events := collectEvents(t, stream)
+	if last := events[len(events)-1]; last.Done == nil || last.Done.Reason != "rejected" {
+		t.Fatalf("expected rejected turn, got %+v", last)
+	}
+	sess, _ := eng.sessionStore.Get(ctx, sid)
+	answered := 0
+	for _, m := range sess.Messages {
+		if m.Role == "tool" {
+			answered++
+		}
+	}
+	if answered != 3 {
+		t.Fatalf("expected every call answered after rejecting, got %d tool messages", answered)
+	}
+}
+
+func TestEngine_ModifiedArgsAreValidatedAndNotedForTheModel(t *testing.T) {
+	ws := t.TempDir()
+	ctx := context.Background()
+	eng := newBatchEngine(t, ws, &batchLLM{})
+	sid, _ := eng.StartSession(ctx, api.StartOptions{ApprovalMode: api.ModeAuto})
+	stream, err := eng.Send(ctx, sid, "write both")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	var requestID string
+	for _, e := range collectEvents(t, stream) {
+		if e.Type == api.EventApproval {
+			requestID = e.Approval.RequestID
+		}
+	}
+
+	stream, err = eng.Resume(ctx, sid, api.Decision{
+		Kind:      api.DecisionApprove,
+		RequestID: requestID,
+		Items: []api.ItemDecision{
+			{ToolCallID: "c1", Kind: api.DecisionModify, ModifiedArgs: api.Args{"path": "a.txt", "content": "edited"}, Note: "keep it short"},
+			{ToolCallID: "c3", Kind: api.DecisionModify, ModifiedArgs: api.Args{"path": "b.txt", "contents": "typo"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	collectEvents(t, stream)
+
+	if data, err := os.ReadFile(filepath.Join(ws, "a.txt")); err != nil || string(data) != "edited" {
+		t.Fatalf("expected the modified content written, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "b.txt")); err == nil {
+		t.Fatal("expected the call with invalid modified args skipped")
+	}
+
+	sess, _ := eng.sessionStore.Get(ctx, sid)
+	results := map[string]string{}
+	for _, m := range sess.Messages {
+		if m.Role == "tool" {
+			results[m.ToolCallID] = m.Content
+		}
+	}
+	if c := results["c1"]; !strings.Contains(c, "changed: content") || !strings.Contains(c, "Note from the user: keep it short") {
+		t.Fatalf("expected the edit noted for the model, got %q", c)
+	}
+	if c := results["c3"]; !strings.Contains(c, api.ErrToolArgsInvalid) || !strings.Contains(c, `missing required argument "content"`) {
+		t.Fatalf("expected the invalid args reported, got %q", c)
+	}
+}
+
+func TestEngine_ToolResultEventsOmitImageBytes(t *testing.T) {
+	ws := t.TempDir()
+	ctx := context.Background()
+	png := []byte("\x89PNG\r\n\x1a\nfake image")
+	if err := os.WriteFile(filepath.Join(ws, "shot.png"), png, 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reg := tools.NewRegistry()
+	reg.MustRegister(tools.NewReadFileTool(ws))
+	eng, err := NewEngine(EngineConfig{
+		LLM:           &scriptedLLM{toolCall: &api.LLMToolCall{ID: "c1", Name: "read_file", Args: `{"path":"shot.png"}`}},
+		Tools:         reg,
+		Policy:        policy.NewDefaultPolicy(),
+		WorkspaceRoot: ws,
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	sid, err := eng.StartSession(ctx, api.StartOptions{ApprovalMode: api.ModeFullAuto})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	stream, err := eng.Send(ctx, sid, "look at the screenshot")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var parts []api.ContentPart
+	for _, e := range collectEvents(t, stream) {
+		if e.Type == api.EventToolResult {
+			parts = e.ToolResult.Result.Parts
+		}
+	}
+	if len(parts) != 1 || parts[0].Data != nil || parts[0].BlobRef == "" || parts[0].MIMEType != "image/png" || parts[0].Path != "shot.png" {
+		t.Fatalf("expected the image referenced without its bytes, got %+v", parts)
+	}
+
+	// The conversation keeps the image for the model.
+	session, err := eng.sessionStore.Get(ctx, sid)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	for _, m := range session.Messages {
+		if m.Role == "tool" && (len(m.Parts) != 1 || string(m.Parts[0].Data) != string(png)) {
+			t.Fatalf("expected the session to keep the image bytes, got %+v", m.Parts)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/store"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Attach
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// attachPoll is how often an attached stream checks the event log of a turn running
+// in another process.
+const attachPoll = 200 * time.Millisecond
+
+// Attach streams a session's current turn to an extra subscriber, such as a second
+// terminal. Events of the turn after fromSeq are replayed from the event log, then
+// live events follow. A turn running in another process is followed through the
+// event log. The stream ends when the turn finishes or suspends on an approval; for
+// an idle session it replays the last turn.
+func (e *Engine) Attach(ctx context.Context, sessionID string, fromSeq int64) (api.EventStream, error) {
+	if _, err := e.sessionStore.Get(ctx, sessionID); err != nil {
+		if err == store.ErrNotFound {
+			return nil, fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
+		}
+		return nil, err
+	}
+
+	s := &attachStream{e: e, sessionID: sessionID, lastSeq: fromSeq}
+
+	// Subscribe before reading the log so no event falls in between; duplicates are
+	// skipped by Seq.
+	e.turnsMu.Lock()
+	runner := e.activeTurns[sessionID]
+	e.turnsMu.Unlock()
+	if runner != nil {
+		s.live, s.turnID = runner.Subscribe(0)
+	}
+
+	var logged []api.Event
+	if follower, ok := e.eventLog.(store.EventFollower); ok {
+		events, offset, err := follower.ReadFrom(ctx, sessionID, 0)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		logged = events
+		s.follower, s.offset = follower, offset
+	} else if stream, err := e.eventLog.Stream(ctx, sessionID); err == nil {
+		for {
+			ev, err := stream.Recv(ctx)
+			if err != nil {
+				break
+			}
+			logged = append(logged, ev)
+		}
+		stream.Close()
+	}
+
+	if s.turnID == "" {
+		for i := len(logged) - 1; i >= 0 && s.turnID == ""; i-- {
+			s.turnID = logged[i].TurnID
+		}
+	}
+	for _, ev := range logged {
+		if ev.TurnID == s.turnID && ev.Seq > fromSeq {
+			s.replay = append(s.replay, ev)
+		}
+	}
+	return s, nil
+}
+
+// attachStream replays logged events, then follows the live hub or the event log.
+type attachStream struct {
+	e         *Engine
+	sessionID string
+	turnID    string
+	lastSeq   int64
+	replay    []api.Event
+	ended     bool
+
+	live api.EventStream
+
+	follower store.EventFollower
+	offset   int64
+	pending  []api.Event
+}
+
+func (s *attachStream) Recv(ctx context.Context) (api.Event, error) {
+	for {
+		if s.ended {
+			return api.Event{}, io.EOF
+		}
+		ev, err := s.next(ctx)
+		if err != nil {
+			return api.Event{}, err
+		}
+		if ev.TurnID != s.turnID || ev.Seq <= s.lastSeq {
+			continue
+		}
+		s.lastSeq = ev.Seq
+		s.ended = ev.Type == api.EventDone || ev.Type == api.EventApproval
+		return ev, nil
+	}
+}
+
+// next returns the next candidate event, in replay, live, then log order.
+func (s *attachStream) next(ctx context.Context) (api.Event, error) {
+	if len(s.replay) > 0 {
+		ev := s.replay[0]
+		s.replay = s.replay[1:]
+		return ev, nil
+	}
+	if s.live != nil {
+		return s.live.Recv(ctx)
+	}
+	for len(s.pending) == 0 {
+		if s.follower == nil || !s.e.sessionLocked(s.sessionID) {
+			// Nothing is running the turn; whatever was logged has been replayed.
+			if s.follower != nil {
+				s.pending, s.offset, _ = s.follower.ReadFrom(ctx, s.sessionID, s.offset)
+			}
+			if len(s.pending) == 0 {
+				return api.Event{}, io.EOF
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return api.Event{}, ctx.Err()
+		case <-time.After(attachPoll):
+		}
+		events, offset, err := s.follower.ReadFrom(ctx, s.sessionID, s.offset)
+		if err != nil {
+			return api.Event{}, err
+		}
+		s.pending, s.offset = events, offset
+	}
+	ev := s.pending[0]
+	s.pending = s.pending[1:]
+	return ev, nil
+}
+
+func (s *attachStream) Close() error {
+	s.ended = true
+	if s.live != nil {
+		return s.live.Close()
+	}
+	return nil
+}
+
+// sessionLocked reports whether a live process holds the session's lock.
+func (e *Engine) sessionLocked(sessionID string) bool {
+	locker, ok := e.sessionStore.(store.SessionLocker)
+	if !ok {
+		return false
+	}
+	holder, stale, err := locker.InspectLock(sessionID)
+	return err == nil && holder != nil && !stale
+}
@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestEngine_AttachReplaysAndFollowsRunningTurn(t *testing.T) {
+	llm := &controlLLM{block: true, started: make(chan struct{})}
+	eng, sid := newControlEngine(t, llm)
+	ctx := context.Background()
+
+	stream, err := eng.Send(ctx, sid, "explain")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	done := make(chan []api.Event)
+	go func() { done <- collectEvents(t, stream) }()
+	<-llm.started
+
+	attached, err := eng.Attach(ctx, sid, 0)
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if err := eng.Cancel(sid); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	primary := <-done
+	watched := collectEvents(t, attached)
+
+	// The attached stream sees the whole turn once: replayed deltas, then the rest live.
+	if len(watched) != len(primary) {
+		t.Fatalf("expected %d events attached, got %d: %+v", len(primary), len(watched), watched)
+	}
+	for i := range watched {
+		if watched[i].Seq != primary[i].Seq || watched[i].Type != primary[i].Type {
+			t.Fatalf("event %d differs: %+v vs %+v", i, watched[i], primary[i])
+		}
+	}
+
+	// An idle session replays its last turn after fromSeq.
+	replayed := collectEvents(t, mustAttach(t, eng, sid, primary[0].Seq))
+	if len(replayed) != len(primary)-1 || replayed[len(replayed)-1].Type != api.EventDone {
+		t.Fatalf("expected the last turn replayed after seq %d, got %+v", primary[0].Seq, replayed)
+	}
+}
+
+func mustAttach(t *testing.T, eng *Engine, sid string, fromSeq int64) api.EventStream {
+	t.Helper()
+	stream, err := eng.Attach(context.Background(), sid, fromSeq)
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	return stream
+}
@@ -0,0 +1,347 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	planpkg "AgentEngine/pkg/engine/plan"
+	"AgentEngine/pkg/engine/prompts"
+	"AgentEngine/pkg/engine/store"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Autopilot (run until plan done)
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+const (
+	defaultAutopilotMaxTurns    = 20
+	defaultAutopilotMaxDuration = 30 * time.Minute
+
+	// autopilotStallTurns stops the run after this many turns in a row leave the plan unchanged.
+	autopilotStallTurns = 3
+)
+
+// autopilotGrantTools are the approvals the autopilot grants on its own: plan bookkeeping.
+// Anything else stops the run and is handed to the client.
+var autopilotGrantTools = map[string]bool{
+	"read_todos":  true,
+	"write_todos": true,
+}
+
+// ungrantedTool returns the first tool in the approval the autopilot does not grant.
+func ungrantedTool(approval api.ApprovalPayload) string {
+	for _, call := range approval.Calls() {
+		if !autopilotGrantTools[call.ToolName] {
+			return call.ToolName
+		}
+	}
+	return ""
+}
+
+// approvalToolNames lists the tools an approval covers.
+func approvalToolNames(approval api.ApprovalPayload) string {
+	var names []string
+	for _, call := range approval.Calls() {
+		names = append(names, call.ToolName)
+	}
+	return strings.Join(names, ", ")
+}
+
+func setAutopilotMetadata(metadata map[string]string, limits api.AutopilotLimits) {
+	metadata["until_plan_done"] = "true"
+	if limits.MaxTurns > 0 {
+		metadata["autopilot_max_turns"] = strconv.Itoa(limits.MaxTurns)
+	}
+	if limits.MaxDuration > 0 {
+		metadata["autopilot_max_duration"] = limits.MaxDuration.String()
+	}
+	if limits.TokenBudget > 0 {
+		metadata["autopilot_token_budget"] = strconv.Itoa(limits.TokenBudget)
+	}
+}
+
+// autopilotLimits reports whether the session runs until its plan is done, with defaults applied.
+func (e *Engine) autopilotLimits(ctx context.Context, sessionID string) (api.AutopilotLimits, bool) {
+	session, err := e.sessionStore.Get(ctx, sessionID)
+	if err != nil || session.Metadata["until_plan_done"] != "true" {
+		return api.AutopilotLimits{}, false
+	}
+	limits := api.AutopilotLimits{
+		MaxTurns:    defaultAutopilotMaxTurns,
+		MaxDuration: defaultAutopilotMaxDuration,
+	}
+	if n, err := strconv.Atoi(session.Metadata["autopilot_max_turns"]); err == nil && n > 0 {
+		limits.MaxTurns = n
+	}
+	if d, err := time.ParseDuration(session.Metadata["autopilot_max_duration"]); err == nil && d > 0 {
+		limits.MaxDuration = d
+	}
+	if n, err := strconv.Atoi(session.Metadata["autopilot_token_budget"]); err == nil && n > 0 {
+		limits.TokenBudget = n
+	}
+	return limits, true
+}
+
+// autopilot drives continuation turns for one Send/Resume call and merges them into a
+// single stream. Intermediate Done events are held back; the run ends with a
+// progress report followed by the last turn's Done (or the approval it could not grant).
+type autopilot struct {
+	e         *Engine
+	sessionID string
+	limits    api.AutopilotLimits
+	out       *store.ChannelEventStream
+
+	start     time.Time
+	startUsed int // session tokens before the run
+	turns     int
+	tokens    int
+	stalled   int
+	lastPlan  string
+	last      api.Event // last forwarded event; synthetic events continue its turn and seq
+}
+
+func (e *Engine) runAutopilot(ctx context.Context, sessionID string, limits api.AutopilotLimits, first func() (api.EventStream, error)) (api.EventStream, error) {
+	stream, err := first()
+	if err != nil {
+		return nil, err
+	}
+	a := &autopilot{
+		e:         e,
+		sessionID: sessionID,
+		limits:    limits,
+		out:       store.NewChannelEventStream(100),
+		start:     time.Now(),
+	}
+	if p := a.loadPlan(ctx); p != nil {
+		a.lastPlan = planSignature(p)
+	}
+	if session, err := e.sessionStore.Get(ctx, sessionID); err == nil {
+		a.startUsed = sessionUsage(session).Tokens()
+	}
+	go a.run(ctx, stream)
+	return a.out, nil
+}
+
+func (a *autopilot) run(ctx context.Context, stream api.EventStream) {
+	defer a.out.Close()
+
+	for {
+		done, approval, err := a.drain(ctx, stream)
+		stream.Close()
+		if err != nil {
+			a.fail(ctx, api.ErrStoreError, err.Error())
+			return
+		}
+
+		if approval != nil {
+			pending := approval.Approval
+			if name := ungrantedTool(*pending); name != "" {
+				a.report(ctx, api.StopApprovalRequired, fmt.Sprintf("%s needs approval", name))
+				approval.Seq = a.last.Seq + 1
+				a.forward(*approval)
+				return
+			}
+			a.note(fmt.Sprintf("🤖 Autopilot approved %s", approvalToolNames(*pending)))
+			turnCtx := ctx
+			if a.turns > 0 {
+				turnCtx = withContinuation(ctx)
+			}
+			stream, err = a.e.resume(turnCtx, a.sessionID, api.Decision{
+				Kind:       api.DecisionApprove,
+				RequestID:  pending.RequestID,
+				ToolCallID: pending.ToolCallID,
+			})
+			if err != nil {
+				a.fail(ctx, api.ErrTurnInProgress, err.Error())
+				return
+			}
+			continue
+		}
+
+		a.turns++
+		a.tokens = a.usedTokens(ctx)
+		if done == nil {
+			a.report(ctx, api.StopTurnEnded, "turn ended without completing")
+			return
+		}
+		if done.Done == nil || done.Done.Reason != "completed" {
+			reason := ""
+			if done.Done != nil {
+				reason = done.Done.Reason
+			}
+			a.report(ctx, api.StopTurnEnded, "last turn "+reason)
+			a.forward(*done)
+			return
+		}
+
+		next, reason, msg := a.check(ctx)
+		if reason != "" {
+			a.report(ctx, reason, msg)
+			a.forward(*done)
+			return
+		}
+
+		a.note(fmt.Sprintf("🤖 Autopilot turn %d/%d: #%d %s", a.turns+1, a.limits.MaxTurns, next.ID, next.Text))
+		stream, err = a.e.send(withContinuation(ctx), a.sessionID, continuationMessage(next))
+		if err != nil {
+			a.fail(ctx, api.ErrTurnInProgress, err.Error())
+			return
+		}
+	}
+}
+
+// drain forwards one turn's events. It returns the held Done event, or the approval
+// the turn suspended on (the stream ends right after it).
+func (a *autopilot) drain(ctx context.Context, stream api.EventStream) (*api.Event, *api.Event, error) {
+	var approval *api.Event
+	for {
+		ev, err := stream.Recv(ctx)
+		if err == io.EOF {
+			return nil, approval, nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, nil
+			}
+			return nil, nil, err
+		}
+		switch ev.Type {
+		case api.EventDone:
+			return &ev, nil, nil
+		case api.EventApproval:
+			// Held until the turn suspends so the pending state is saved before deciding.
+			if ev.Approval != nil {
+				approval = &ev
+			}
+			continue
+		}
+		a.forward(ev)
+	}
+}
+
+// check decides whether another continuation turn should run. It returns the item to
+// work on, or a stop reason with a short explanation.
+func (a *autopilot) check(ctx context.Context) (*api.PlanItem, string, string) {
+	p := a.loadPlan(ctx)
+	if p == nil || len(p.Items) == 0 {
+		return nil, api.StopNoPlan, "no plan to continue"
+	}
+	counts := planpkg.Counts(p)
+	switch {
+	case counts[api.PlanBlocked] > 0:
+		return nil, api.StopPlanBlocked, fmt.Sprintf("%d item(s) blocked", counts[api.PlanBlocked])
+	case counts[api.PlanErrored] > 0:
+		return nil, api.StopPlanErrored, fmt.Sprintf("%d item(s) errored", counts[api.PlanErrored])
+	case counts[api.PlanPending]+counts[api.PlanRunning] == 0:
+		return nil, api.StopPlanDone, "all items done"
+	}
+	next := planpkg.Next(p)
+	if next == nil {
+		return nil, api.StopPlanStalled, "open items remain but none is ready"
+	}
+
+	sig := planSignature(p)
+	if sig == a.lastPlan {
+		a.stalled++
+	} else {
+		a.stalled = 0
+	}
+	a.lastPlan = sig
+	if a.stalled >= autopilotStallTurns {
+		return nil, api.StopNoProgress, fmt.Sprintf("plan unchanged for %d turns", a.stalled)
+	}
+
+	switch {
+	case a.turns >= a.limits.MaxTurns:
+		return nil, api.StopMaxTurns, fmt.Sprintf("reached %d turns", a.limits.MaxTurns)
+	case time.Since(a.start) >= a.limits.MaxDuration:
+		return nil, api.StopMaxDuration, fmt.Sprintf("ran longer than %s", a.limits.MaxDuration)
+	case a.limits.TokenBudget > 0 && a.tokens >= a.limits.TokenBudget:
+		return nil, api.StopTokenBudget, fmt.Sprintf("used ~%d of %d tokens", a.tokens, a.limits.TokenBudget)
+	}
+	return next, "", ""
+}
+
+func (a *autopilot) loadPlan(ctx context.Context) *api.PlanPayload {
+	p, err := a.e.planStore.Get(ctx, "plan_"+a.sessionID)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// usedTokens returns the estimated tokens the session used since the run started.
+func (a *autopilot) usedTokens(ctx context.Context) int {
+	session, err := a.e.sessionStore.Get(ctx, a.sessionID)
+	if err != nil {
+		return a.tokens
+	}
+	return sessionUsage(session).Tokens() - a.startUsed
+}
+
+func (a *autopilot) report(ctx context.Context, reason, message string) {
+	a.forward(api.Event{
+		Type: api.EventProgressReport,
+		ProgressReport: &api.ProgressReportPayload{
+			Reason:          reason,
+			Turns:           a.turns,
+			ElapsedMs:       time.Since(a.start).Milliseconds(),
+			EstimatedTokens: a.tokens,
+			Plan:            a.loadPlan(ctx),
+			Message:         message,
+		},
+	})
+}
+
+func (a *autopilot) note(message string) {
+	a.forward(api.Event{Type: api.EventThinking, Thinking: &api.ThinkingPayload{Message: message}})
+}
+
+func (a *autopilot) fail(ctx context.Context, code, message string) {
+	a.report(ctx, api.StopTurnEnded, message)
+	a.forward(api.Event{Type: api.EventError, Error: &api.ErrorPayload{Code: code, Message: message}})
+	a.forward(api.Event{Type: api.EventDone, Done: &api.DonePayload{Reason: "error"}})
+}
+
+// forward sends ev to the merged stream, filling envelope fields for synthetic events.
+func (a *autopilot) forward(ev api.Event) {
+	if ev.TurnID == "" {
+		ev.Version = a.last.Version
+		ev.SessionID = a.sessionID
+		ev.TurnID = a.last.TurnID
+		ev.Seq = a.last.Seq + 1
+		ev.Ts = time.Now()
+	}
+	a.last = ev
+	_ = a.out.Send(ev)
+}
+
+// continuationKey marks the context of turns autopilot started to continue the plan.
+type continuationKey struct{}
+
+func withContinuation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, continuationKey{}, true)
+}
+
+func isContinuation(ctx context.Context) bool {
+	v, _ := ctx.Value(continuationKey{}).(bool)
+	return v
+}
+
+func continuationMessage(next *api.PlanItem) string {
+	return fmt.Sprintf("%s\n\nNext item: #%d %s", prompts.DefaultLoader.Get(prompts.PlanContinue), next.ID, next.Text)
+}
+
+// planSignature captures item statuses so unchanged plans can be detected.
+func planSignature(p *api.PlanPayload) string {
+	var b strings.Builder
+	for _, it := range p.Items {
+		fmt.Fprintf(&b, "%d:%s:%d;", it.ID, it.Status, len(it.Evidence))
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package runtime
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+	planpkg "AgentEngine/pkg/engine/plan"
+	"AgentEngine/pkg/engine/policy"
+	"AgentEngine/pkg/engine/store"
+	"AgentEngine/pkg/engine/tools"
+)
+
+// planWorkerLLM finishes the next actionable plan item on every request.
+type planWorkerLLM struct {
+	plans    store.PlanStore
+	planID   string
+	idle     bool
+	requests []LLMRequest
+}
+
+func (l *planWorkerLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	l.requests = append(l.requests, req)
+	if !l.idle {
+		p, err := l.plans.Get(ctx, l.planID)
+		if err != nil {
+			return nil, err
+		}
+		if next := planpkg.Next(p); next != nil {
+			next.Status = api.PlanDone
+			if err := l.plans.Put(ctx, l.planID, p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &staticStream{content: "worked"}, nil
+}
+
+func newAutopilotEngine(t *testing.T, llm *planWorkerLLM, limits api.AutopilotLimits) (*Engine, string) {
+	t.Helper()
+	ws := t.TempDir()
+	eng, err := NewEngine(EngineConfig{
+		LLM:           llm,
+		Tools:         tools.NewRegistry(),
+		Policy:        policy.NewDefaultPolicy(),
+		WorkspaceRoot: ws,
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	ctx := context.Background()
+	sid, err := eng.StartSession(ctx, api.StartOptions{ApprovalMode: api.ModeFullAuto, UntilPlanDone: true, Autopilot: limits})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	llm.plans = eng.planStore
+	llm.planID = "plan_" + sid
+	plan := &api.PlanPayload{PlanID: llm.planID, Items: []api.PlanItem{
+		{ID: 1, Text: "outline", Status: api.PlanPending},
+		{ID: 2, Text: "draft", Status: api.PlanPending, DependsOn: []int{1}},
+		{ID: 3, Text: "review", Status: api.PlanPending, DependsOn: []int{2}},
+	}}
+	if err := eng.planStore.Put(ctx, llm.planID, plan); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	return eng, sid
+}
+
+func collectEvents(t *testing.T, stream api.EventStream) []api.Event {
+	t.Helper()
+	defer stream.Close()
+	var out []api.Event
+	for {
+		e, err := stream.Recv(context.Background())
+		if err != nil {
+			return out
+		}
+		out = append(out, e)
+	}
+}
+
+// summaryRecorder records the summaries middleware sees after each turn.
+type summaryRecorder struct{ summaries []api.TurnSummary }
+
+func (m *summaryRecorder) Name() string { return "summary_recorder" }
+func (m *summaryRecorder) BeforeTurn(ctx context.Context, state *api.State) error {
+	return nil
+}
+func (m *summaryRecorder) OnEvent(ctx context.Context, state *api.State, e api.Event) error {
+	return nil
+}
+func (m *summaryRecorder) AfterTurn(ctx context.Context, state *api.State, summary api.TurnSummary) error {
+	m.summaries = append(m.summaries, summary)
+	return nil
+}
+
+func TestEngine_UntilPlanDone_RunsContinuationTurns(t *testing.T) {
+	llm := &planWorkerLLM{}
+	eng, sid := newAutopilotEngine(t, llm, api.AutopilotLimits{})
+	rec := &summaryRecorder{}
+	eng.cfg.Middlewares = append(eng.cfg.Middlewares, rec)
+
+	stream, err := eng.Send(context.Background(), sid, "write the book")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	events := collectEvents(t, stream)
+
+	if len(llm.requests) != 3 {
+		t.Fatalf("expected 3 turns, got %d", len(llm.requests))
+	}
+	last := llm.requests[2].Messages[len(llm.requests[2].Messages)-1]
+	if !strings.Contains(last.Content, "Next item: #3 review") {
+		t.Fatalf("expected continuation message for item 3, got %q", last.Content)
+	}
+	if len(rec.summaries) != 3 || rec.summaries[0].Continuation || !rec.summaries[1].Continuation || !rec.summaries[2].Continuation {
+		t.Fatalf("expected only the later turns marked as continuations, got %+v", rec.summaries)
+	}
+
+	var dones int
+	var report *api.ProgressReportPayload
+	for _, e := range events {
+		switch e.Type {
+		case api.EventDone:
+			dones++
+		case api.EventProgressReport:
+			report = e.ProgressReport
+		}
+	}
+	if dones != 1 || events[len(events)-1].Type != api.EventDone {
+		t.Fatalf("expected a single trailing Done, got %d", dones)
+	}
+	if report == nil || report.Reason != api.StopPlanDone || report.Turns != 3 || report.EstimatedTokens == 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestEngine_UntilPlanDone_StopsAtLimits(t *testing.T) {
+	llm := &planWorkerLLM{}
+	eng, sid := newAutopilotEngine(t, llm, api.AutopilotLimits{MaxTurns: 2})
+	stream, err := eng.Send(context.Background(), sid, "write the book")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	events := collectEvents(t, stream)
+	report := events[len(events)-2].ProgressReport
+	if report == nil || report.Reason != api.StopMaxTurns || len(llm.requests) != 2 {
+		t.Fatalf("expected max_turns after 2 turns, got %+v (%d requests)", report, len(llm.requests))
+	}
+
+	// A plan that never changes stops as no progress.
+	idle := &planWorkerLLM{idle: true}
+	eng, sid = newAutopilotEngine(t, idle, api.AutopilotLimits{})
+	stream, err = eng.Send(context.Background(), sid, "write the book")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	events = collectEvents(t, stream)
+	report = events[len(events)-2].ProgressReport
+	if report == nil || report.Reason != api.StopNoProgress {
+		t.Fatalf("expected no_progress, got %+v", report)
+	}
+}
@@ -37,6 +37,34 @@ type EngineConfig struct {
 
 	// Filter historical tool_calls/tool messages before sending to LLM
 	FilterHistoryTools bool
+
+	// Keep model reasoning on assistant messages after the turn (see TurnRunnerConfig).
+	KeepReasoning bool
+
+	// Optional runner for skill lifecycle hooks (frontmatter "hooks").
+	HookRunner SkillHookRunner
+
+	// Optional embedding-based skill routing (combined with keyword scoring).
+	SemanticRouter *SemanticSkillRouter
+
+	// Optional store for accepted memory proposals (see ResolveMemoryProposal).
+	MemoryWriter MemoryWriter
+
+	// Optional models per role (summarizer, memory-extractor, ...) and for skills that
+	// request one; LLM and Model are the main model.
+	Models *ModelSet
+
+	// Turn guards (see TurnRunnerConfig).
+	Model         string
+	Prices        PriceTable
+	Limits        Limits
+	SessionLimits Limits
+	LoopThreshold int
+}
+
+// MemoryWriter persists reviewed memory entries.
+type MemoryWriter interface {
+	Add(ctx context.Context, entry api.MemoryEntry) error
 }
 
 // Engine implements api.Engine interface.
@@ -47,8 +75,9 @@ type Engine struct {
 	planStore    store.PlanStore
 	eventLog     store.EventLog
 
-	// Track active turns per session
+	// Track active turns per session, with their session locks
 	activeTurns map[string]*TurnRunner
+	leases      map[string]store.Lease
 	turnsMu     sync.Mutex
 }
 
@@ -88,6 +117,7 @@ func NewEngine(cfg EngineConfig) (*Engine, error) {
 		planStore:    planStore,
 		eventLog:     eventLog,
 		activeTurns:  make(map[string]*TurnRunner),
+		leases:       make(map[string]store.Lease),
 	}, nil
 }
 
@@ -111,6 +141,9 @@ func (e *Engine) StartSession(ctx context.Context, opts api.StartOptions) (strin
 		metadata["skill_source"] = "cli"
 		metadata["skill_last_reason"] = "start_options"
 	}
+	if opts.UntilPlanDone {
+		setAutopilotMetadata(metadata, opts.Autopilot)
+	}
 
 	session := &api.Session{
 		SessionID:   sessionID,
@@ -138,13 +171,24 @@ func (e *Engine) GetSession(ctx context.Context, sessionID string) (api.SessionI
 		return api.SessionInfo{}, err
 	}
 
+	return sessionInfo(session), nil
+}
+
+func sessionInfo(session *api.Session) api.SessionInfo {
+	mode := api.ApprovalMode(session.Metadata["approval_mode"])
+	if mode == "" {
+		mode = api.ModeAuto
+	}
 	return api.SessionInfo{
 		SessionID:    session.SessionID,
 		CreatedAt:    session.CreatedAt,
 		UpdatedAt:    session.UpdatedAt,
 		MessageCount: len(session.Messages),
 		ActiveSkill:  session.ActiveSkill,
-	}, nil
+		ApprovalMode: mode,
+		Model:        session.Metadata["model"],
+		Usage:        sessionUsage(session),
+	}
 }
 
 // ListSessions lists all sessions.
@@ -193,7 +237,8 @@ func (e *Engine) CompressSession(ctx context.Context, sessionID string, keepTurn
 		MaxMessages:   20,
 		ForceCompress: true, // Manual compress should always work
 	}
-	if err := CompressHistory(ctx, e.cfg.LLM, session, cfg); err != nil {
+	summarizer, _ := e.ModelFor(RoleSummarizer)
+	if err := CompressHistory(ctx, summarizer, session, cfg); err != nil {
 		return nil, err
 	}
 
@@ -211,12 +256,115 @@ func (e *Engine) CompressSession(ctx context.Context, sessionID string, keepTurn
 	}, nil
 }
 
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Memory Proposals
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// PendingMemoryProposal returns the session's unresolved memory proposal, if any.
+func (e *Engine) PendingMemoryProposal(ctx context.Context, sessionID string) (*api.MemoryProposalPayload, error) {
+	session, err := e.sessionStore.Get(ctx, sessionID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
+		}
+		return nil, err
+	}
+	return session.PendingMemory, nil
+}
+
+// ResolveMemoryProposal applies the user's review of a pending memory proposal.
+// Accepted and edited candidates are stored; discarded or unreviewed ones are dropped.
+// It returns the stored entries.
+func (e *Engine) ResolveMemoryProposal(ctx context.Context, sessionID, requestID string, reviews []api.MemoryReview) ([]api.MemoryEntry, error) {
+	e.turnsMu.Lock()
+	defer e.turnsMu.Unlock()
+	if _, exists := e.activeTurns[sessionID]; exists {
+		return nil, fmt.Errorf("%s: %s", api.ErrTurnInProgress, sessionID)
+	}
+
+	session, err := e.sessionStore.Get(ctx, sessionID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
+		}
+		return nil, err
+	}
+	proposal := session.PendingMemory
+	if proposal == nil {
+		return nil, fmt.Errorf("%s: no pending memory proposal", api.ErrNoPendingApproval)
+	}
+	if requestID != proposal.RequestID {
+		return nil, fmt.Errorf("%s: expected %s, got %s", api.ErrApprovalMismatch, proposal.RequestID, requestID)
+	}
+
+	byID := make(map[string]api.MemoryReview, len(reviews))
+	for _, rv := range reviews {
+		byID[rv.CandidateID] = rv
+	}
+
+	var stored []api.MemoryEntry
+	for _, c := range proposal.Candidates {
+		rv, ok := byID[c.CandidateID]
+		if !ok || (rv.Kind != api.MemoryReviewAccept && rv.Kind != api.MemoryReviewEdit) {
+			continue
+		}
+		entry := api.MemoryEntry{
+			Type:    c.Type,
+			Source:  c.Source,
+			Content: c.Content,
+			Tags:    c.Tags,
+		}
+		if rv.Kind == api.MemoryReviewEdit {
+			if rv.Content != "" {
+				entry.Content = rv.Content
+			}
+			if rv.Type != "" {
+				entry.Type = rv.Type
+			}
+			if rv.Tags != nil {
+				entry.Tags = rv.Tags
+			}
+		}
+		if e.cfg.MemoryWriter == nil {
+			return stored, fmt.Errorf("%s: memory store not configured", api.ErrStoreError)
+		}
+		entry.ID = fmt.Sprintf("mem_%d", time.Now().UnixNano())
+		if err := e.cfg.MemoryWriter.Add(ctx, entry); err != nil {
+			return stored, fmt.Errorf("%s: %v", api.ErrStoreError, err)
+		}
+		stored = append(stored, entry)
+	}
+
+	session.PendingMemory = nil
+	session.UpdatedAt = time.Now()
+	if err := e.sessionStore.Put(ctx, sessionID, session); err != nil {
+		return stored, fmt.Errorf("failed to save session: %w", err)
+	}
+	return stored, nil
+}
+
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // Turn Execution
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 
-// Send triggers a turn with a user message.
+// Send triggers a turn with a user message. Sessions started with UntilPlanDone
+// keep running continuation turns on the same stream (see runAutopilot).
 func (e *Engine) Send(ctx context.Context, sessionID, message string) (api.EventStream, error) {
+	return e.SendParts(ctx, sessionID, message, nil)
+}
+
+// SendParts is Send with attachments (e.g. images) on the user message.
+func (e *Engine) SendParts(ctx context.Context, sessionID, message string, parts []api.ContentPart) (api.EventStream, error) {
+	if limits, ok := e.autopilotLimits(ctx, sessionID); ok {
+		return e.runAutopilot(ctx, sessionID, limits, func() (api.EventStream, error) {
+			return e.send(ctx, sessionID, message, parts...)
+		})
+	}
+	return e.send(ctx, sessionID, message, parts...)
+}
+
+// send runs exactly one turn.
+func (e *Engine) send(ctx context.Context, sessionID, message string, parts ...api.ContentPart) (api.EventStream, error) {
 	// Check for existing active turn
 	e.turnsMu.Lock()
 	if _, exists := e.activeTurns[sessionID]; exists {
@@ -224,10 +372,18 @@ func (e *Engine) Send(ctx context.Context, sessionID, message string) (api.Event
 		return nil, fmt.Errorf("%s: %s", api.ErrTurnInProgress, sessionID)
 	}
 
+	// Lock the session against turns in other processes
+	lease, err := e.lockSession(sessionID)
+	if err != nil {
+		e.turnsMu.Unlock()
+		return nil, err
+	}
+
 	// Load session
 	session, err := e.sessionStore.Get(ctx, sessionID)
 	if err != nil {
 		e.turnsMu.Unlock()
+		releaseLease(lease)
 		if err == store.ErrNotFound {
 			return nil, fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
 		}
@@ -237,47 +393,28 @@ func (e *Engine) Send(ctx context.Context, sessionID, message string) (api.Event
 	// Check for pending approval
 	if session.Pending != nil {
 		e.turnsMu.Unlock()
+		releaseLease(lease)
 		return nil, fmt.Errorf("%s: pending approval exists", api.ErrTurnInProgress)
 	}
 
-	approvalMode := api.ModeAuto
-	emitThinking := false
-	if session.Metadata != nil {
-		if v := session.Metadata["approval_mode"]; v != "" {
-			approvalMode = api.ApprovalMode(v)
-		}
-		if session.Metadata["emit_thinking"] == "true" {
-			emitThinking = true
-		}
+	// Close tool calls left open by a crashed process
+	if err := e.repairSession(ctx, session); err != nil {
+		e.turnsMu.Unlock()
+		releaseLease(lease)
+		return nil, err
 	}
 
 	// Create turn runner
-	runner := NewTurnRunner(TurnRunnerConfig{
-		LLM:                   e.cfg.LLM,
-		Tools:                 e.cfg.Tools,
-		Policy:                e.cfg.Policy,
-		SessionStore:          e.sessionStore,
-		PlanStore:             e.planStore,
-		EventLog:              e.eventLog,
-		Middlewares:           e.cfg.Middlewares,
-		WorkspaceRoot:         e.cfg.WorkspaceRoot,
-		SkillIndex:            e.cfg.SkillIndex,
-		ApprovalMode:          approvalMode,
-		EmitThinking:          emitThinking,
-		AutoCompressThreshold: e.cfg.AutoCompressThreshold,
-		CompressKeepTurns:     e.cfg.CompressKeepTurns,
-		FilterHistoryTools:    e.cfg.FilterHistoryTools,
-	})
+	runner := e.newTurnRunner(session)
 
 	e.activeTurns[sessionID] = runner
+	e.leases[sessionID] = lease
 	e.turnsMu.Unlock()
 
 	// Start turn
-	stream, err := runner.Run(ctx, session, message)
+	stream, err := runner.Run(ctx, session, message, parts...)
 	if err != nil {
-		e.turnsMu.Lock()
-		delete(e.activeTurns, sessionID)
-		e.turnsMu.Unlock()
+		e.releaseTurn(sessionID, runner)
 		return nil, err
 	}
 
@@ -285,15 +422,23 @@ func (e *Engine) Send(ctx context.Context, sessionID, message string) (api.Event
 	return &cleanupEventStream{
 		EventStream: stream,
 		onClose: func() {
-			e.turnsMu.Lock()
-			delete(e.activeTurns, sessionID)
-			e.turnsMu.Unlock()
+			e.releaseTurn(sessionID, runner)
 		},
 	}, nil
 }
 
 // Resume continues from a pending approval.
 func (e *Engine) Resume(ctx context.Context, sessionID string, decision api.Decision) (api.EventStream, error) {
+	if limits, ok := e.autopilotLimits(ctx, sessionID); ok {
+		return e.runAutopilot(ctx, sessionID, limits, func() (api.EventStream, error) {
+			return e.resume(ctx, sessionID, decision)
+		})
+	}
+	return e.resume(ctx, sessionID, decision)
+}
+
+// resume continues one suspended turn.
+func (e *Engine) resume(ctx context.Context, sessionID string, decision api.Decision) (api.EventStream, error) {
 	// Check for existing active turn
 	e.turnsMu.Lock()
 	if _, exists := e.activeTurns[sessionID]; exists {
@@ -301,13 +446,20 @@ func (e *Engine) Resume(ctx context.Context, sessionID string, decision api.Deci
 		return nil, fmt.Errorf("%s: %s", api.ErrTurnInProgress, sessionID)
 	}
 
+	// Lock the session against turns in other processes
+	lease, err := e.lockSession(sessionID)
+	if err != nil {
+		e.turnsMu.Unlock()
+		return nil, err
+	}
+
 	// Load session with retry for pending state sync
 	var session *api.Session
-	var err error
 	for i := 0; i < 3; i++ {
 		session, err = e.sessionStore.Get(ctx, sessionID)
 		if err != nil {
 			e.turnsMu.Unlock()
+			releaseLease(lease)
 			if err == store.ErrNotFound {
 				return nil, fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
 			}
@@ -325,9 +477,45 @@ func (e *Engine) Resume(ctx context.Context, sessionID string, decision api.Deci
 	// Verify pending approval exists
 	if session.Pending == nil {
 		e.turnsMu.Unlock()
+		releaseLease(lease)
 		return nil, fmt.Errorf("%s: %s", api.ErrNoPendingApproval, sessionID)
 	}
 
+	// Close tool calls left open by a crashed process (the pending ones stay open)
+	if err := e.repairSession(ctx, session); err != nil {
+		e.turnsMu.Unlock()
+		releaseLease(lease)
+		return nil, err
+	}
+
+	// Create turn runner
+	runner := e.newTurnRunner(session)
+
+	e.activeTurns[sessionID] = runner
+	e.leases[sessionID] = lease
+	e.turnsMu.Unlock()
+
+	// Resume turn
+	stream, err := runner.Resume(ctx, session, decision)
+	if err != nil {
+		e.releaseTurn(sessionID, runner)
+		return nil, err
+	}
+
+	return &cleanupEventStream{
+		EventStream: stream,
+		onClose: func() {
+			e.releaseTurn(sessionID, runner)
+		},
+	}, nil
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Helpers
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// newTurnRunner builds a turn runner from engine config and per-session settings.
+func (e *Engine) newTurnRunner(session *api.Session) *TurnRunner {
 	approvalMode := api.ModeAuto
 	emitThinking := false
 	if session.Metadata != nil {
@@ -339,8 +527,7 @@ func (e *Engine) Resume(ctx context.Context, sessionID string, decision api.Deci
 		}
 	}
 
-	// Create turn runner
-	runner := NewTurnRunner(TurnRunnerConfig{
+	return NewTurnRunner(TurnRunnerConfig{
 		LLM:                   e.cfg.LLM,
 		Tools:                 e.cfg.Tools,
 		Policy:                e.cfg.Policy,
@@ -355,34 +542,18 @@ func (e *Engine) Resume(ctx context.Context, sessionID string, decision api.Deci
 		AutoCompressThreshold: e.cfg.AutoCompressThreshold,
 		CompressKeepTurns:     e.cfg.CompressKeepTurns,
 		FilterHistoryTools:    e.cfg.FilterHistoryTools,
+		KeepReasoning:         e.cfg.KeepReasoning,
+		HookRunner:            e.cfg.HookRunner,
+		SemanticRouter:        e.cfg.SemanticRouter,
+		Models:                e.cfg.Models,
+		Model:                 e.cfg.Model,
+		Prices:                e.cfg.Prices,
+		Limits:                e.cfg.Limits,
+		SessionLimits:         e.cfg.SessionLimits,
+		LoopThreshold:         e.cfg.LoopThreshold,
 	})
-
-	e.activeTurns[sessionID] = runner
-	e.turnsMu.Unlock()
-
-	// Resume turn
-	stream, err := runner.Resume(ctx, session, decision)
-	if err != nil {
-		e.turnsMu.Lock()
-		delete(e.activeTurns, sessionID)
-		e.turnsMu.Unlock()
-		return nil, err
-	}
-
-	return &cleanupEventStream{
-		EventStream: stream,
-		onClose: func() {
-			e.turnsMu.Lock()
-			delete(e.activeTurns, sessionID)
-			e.turnsMu.Unlock()
-		},
-	}, nil
 }
 
-// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
-// Helpers
-// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
-
 func generateSessionID() string {
 	return fmt.Sprintf("session_%d", time.Now().UnixNano())
 }
@@ -0,0 +1,97 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Limits
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Limits bounds the work of a turn or a whole session. Zero values disable a guard.
+type Limits struct {
+	MaxLLMCalls  int
+	MaxToolCalls int
+	MaxTokens    int           // prompt + completion tokens
+	MaxCost      float64       // estimated USD, priced with TurnRunnerConfig.Prices
+	MaxDuration  time.Duration // active time; approval waits are not counted
+}
+
+// limitError ends a turn with a limit code (see api.IsLimitCode).
+type limitError struct {
+	code    string
+	message string
+}
+
+func (e *limitError) Error() string { return e.code + ": " + e.message }
+
+// check compares usage against the limits. Before an LLM call the LLM-call cap applies;
+// before a tool call (beforeTool) the tool-call cap applies instead.
+func (l Limits) check(scope string, u api.Usage, beforeTool bool) *limitError {
+	switch {
+	case !beforeTool && l.MaxLLMCalls > 0 && u.LLMCalls >= l.MaxLLMCalls:
+		return &limitError{api.ErrLimitLLMCalls, fmt.Sprintf("%s reached %d LLM calls", scope, l.MaxLLMCalls)}
+	case beforeTool && l.MaxToolCalls > 0 && u.ToolCalls >= l.MaxToolCalls:
+		return &limitError{api.ErrLimitToolCalls, fmt.Sprintf("%s reached %d tool calls", scope, l.MaxToolCalls)}
+	case l.MaxTokens > 0 && u.Tokens() >= l.MaxTokens:
+		return &limitError{api.ErrLimitTokens, fmt.Sprintf("%s used ~%d of %d tokens", scope, u.Tokens(), l.MaxTokens)}
+	case l.MaxCost > 0 && u.Cost >= l.MaxCost:
+		return &limitError{api.ErrLimitCost, fmt.Sprintf("%s cost ~$%.4f of $%.4f", scope, u.Cost, l.MaxCost)}
+	case l.MaxDuration > 0 && u.Duration >= l.MaxDuration:
+		return &limitError{api.ErrLimitDuration, fmt.Sprintf("%s ran %s of %s", scope, u.Duration.Round(time.Second), l.MaxDuration)}
+	}
+	return nil
+}
+
+// checkLimits enforces turn limits, then session limits.
+func (r *TurnRunner) checkLimits(beforeTool bool) *limitError {
+	r.tick()
+	if err := r.cfg.Limits.check("turn", r.usage, beforeTool); err != nil {
+		return err
+	}
+	return r.cfg.SessionLimits.check("session", sessionUsage(r.session), beforeTool)
+}
+
+// detectToolLoop counts identical consecutive tool calls (name + args) and reports a loop
+// once LoopThreshold is reached.
+func (r *TurnRunner) detectToolLoop(name string, args api.Args) *limitError {
+	if r.cfg.LoopThreshold <= 0 {
+		return nil
+	}
+	b, _ := json.Marshal(args) // map keys are sorted, so equal args marshal identically
+	sig := name + " " + string(b)
+	if sig == r.lastToolSig {
+		r.toolRepeats++
+	} else {
+		r.lastToolSig, r.toolRepeats = sig, 1
+	}
+	if r.toolRepeats >= r.cfg.LoopThreshold {
+		return &limitError{api.ErrToolLoop, fmt.Sprintf("%s called %d times in a row with the same arguments", name, r.toolRepeats)}
+	}
+	return nil
+}
+
+// skipToolCalls answers tool calls that will not run, keeping the history valid for the next request.
+func (r *TurnRunner) skipToolCalls(calls []api.LLMToolCall, reason string) {
+	for _, tc := range calls {
+		r.session.Messages = append(r.session.Messages, api.LLMMessage{
+			Role:       "tool",
+			Content:    "Skipped: " + reason,
+			ToolCallID: tc.ID,
+		})
+	}
+}
+
+func (r *TurnRunner) emitLimit(ctx context.Context, le *limitError) {
+	r.turnError = &api.ErrorPayload{Code: le.code, Message: le.message}
+	r.emit(ctx, api.Event{
+		Type:  api.EventError,
+		Error: &api.ErrorPayload{Code: le.code, Message: le.message},
+	})
+	r.emitDone(ctx, le.code)
+}
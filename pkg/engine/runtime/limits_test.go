@@ -0,0 +1,160 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/policy"
+	"AgentEngine/pkg/engine/store"
+	"AgentEngine/pkg/engine/tools"
+)
+
+// loopingLLM calls ls on every request; varyArgs changes the path each time.
+type loopingLLM struct {
+	varyArgs bool
+	calls    int
+}
+
+func (l *loopingLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	l.calls++
+	args := `{"path":"."}`
+	if l.varyArgs {
+		args = fmt.Sprintf(`{"path":"dir%d"}`, l.calls)
+	}
+	return &toolCallStream{tc: &api.LLMToolCall{ID: fmt.Sprintf("call_%d", l.calls), Name: "ls", Args: args}}, nil
+}
+
+func runLimitedTurn(t *testing.T, llm LLM, cfg TurnRunnerConfig) (*api.Session, *api.ErrorPayload, string) {
+	t.Helper()
+	ws := t.TempDir()
+	sessionStore, err := store.NewFileSessionStore(ws)
+	if err != nil {
+		t.Fatalf("session store: %v", err)
+	}
+	planStore, err := store.NewFilePlanStore(ws)
+	if err != nil {
+		t.Fatalf("plan store: %v", err)
+	}
+	reg := tools.NewRegistry()
+	reg.MustRegister(tools.NewLsTool(ws))
+
+	cfg.LLM = llm
+	cfg.Tools = reg
+	cfg.Policy = policy.NewDefaultPolicy()
+	cfg.SessionStore = sessionStore
+	cfg.PlanStore = planStore
+	cfg.WorkspaceRoot = ws
+	if cfg.ApprovalMode == "" {
+		cfg.ApprovalMode = api.ModeFullAuto
+	}
+
+	sess := &api.Session{SessionID: "s1", Metadata: map[string]string{}}
+	stream, err := NewTurnRunner(cfg).Run(context.Background(), sess, "look around")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var errPayload *api.ErrorPayload
+	reason := ""
+	for _, e := range collectEvents(t, stream) {
+		switch e.Type {
+		case api.EventError:
+			errPayload = e.Error
+		case api.EventDone:
+			reason = e.Done.Reason
+		}
+	}
+	return sess, errPayload, reason
+}
+
+func TestTurnRunner_LoopDetectionStopsRepeatedToolCalls(t *testing.T) {
+	llm := &loopingLLM{}
+	sess, errPayload, reason := runLimitedTurn(t, llm, TurnRunnerConfig{LoopThreshold: 3})
+
+	if errPayload == nil || errPayload.Code != api.ErrToolLoop || reason != api.ErrToolLoop {
+		t.Fatalf("expected tool_loop, got %+v reason=%q", errPayload, reason)
+	}
+	if llm.calls != 3 {
+		t.Fatalf("expected 3 LLM calls, got %d", llm.calls)
+	}
+	// The skipped call still gets a tool message so the history stays valid.
+	last := sess.Messages[len(sess.Messages)-1]
+	if last.Role != "tool" || last.ToolCallID != "call_3" || !strings.HasPrefix(last.Content, "Skipped:") {
+		t.Fatalf("expected skipped tool message, got %+v", last)
+	}
+	if u := sessionUsage(sess); u.LLMCalls != 3 || u.ToolCalls != 2 || u.Tokens() == 0 {
+		t.Fatalf("unexpected session usage: %+v", u)
+	}
+}
+
+// repeatLLM lists a directory once, then twice more with the same arguments, in one message.
+type repeatLLM struct{}
+
+func (repeatLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	return &chunkStream{chunks: []LLMChunk{
+		{ToolCall: &api.LLMToolCall{ID: "c1", Name: "ls", Args: `{"path":"src"}`}},
+		{ToolCall: &api.LLMToolCall{ID: "c2", Name: "ls", Args: `{"path":"."}`}},
+		{ToolCall: &api.LLMToolCall{ID: "c3", Name: "ls", Args: `{"path":"."}`}},
+		{FinishReason: "tool_calls"},
+	}}, nil
+}
+
+func TestTurnRunner_LoopDetectionAnswersCallsQueuedForApproval(t *testing.T) {
+	sess, errPayload, _ := runLimitedTurn(t, repeatLLM{}, TurnRunnerConfig{LoopThreshold: 2, ApprovalMode: api.ModeSuggest})
+	if errPayload == nil || errPayload.Code != api.ErrToolLoop {
+		t.Fatalf("expected tool_loop, got %+v", errPayload)
+	}
+	if sess.Pending != nil {
+		t.Fatalf("expected no approval left pending, got %+v", sess.Pending)
+	}
+
+	var answered []string
+	for _, m := range sess.Messages {
+		if m.Role == "tool" && strings.HasPrefix(m.Content, "Skipped:") {
+			answered = append(answered, m.ToolCallID)
+		}
+	}
+	if strings.Join(answered, ",") != "c1,c2,c3" {
+		t.Fatalf("expected every call answered in order, got %v", answered)
+	}
+}
+
+func TestTurnRunner_LimitsEndTurnWithDistinctCodes(t *testing.T) {
+	_, errPayload, reason := runLimitedTurn(t, &loopingLLM{varyArgs: true}, TurnRunnerConfig{
+		Limits: Limits{MaxLLMCalls: 4},
+	})
+	if errPayload == nil || errPayload.Code != api.ErrLimitLLMCalls || reason != api.ErrLimitLLMCalls {
+		t.Fatalf("expected limit_llm_calls, got %+v reason=%q", errPayload, reason)
+	}
+
+	_, errPayload, reason = runLimitedTurn(t, &loopingLLM{varyArgs: true}, TurnRunnerConfig{
+		Limits: Limits{MaxToolCalls: 2},
+	})
+	if errPayload == nil || errPayload.Code != api.ErrLimitToolCalls || reason != api.ErrLimitToolCalls {
+		t.Fatalf("expected limit_tool_calls, got %+v reason=%q", errPayload, reason)
+	}
+
+	_, errPayload, _ = runLimitedTurn(t, &loopingLLM{varyArgs: true}, TurnRunnerConfig{
+		Model:         "gpt-4o",
+		Prices:        DefaultPriceTable(),
+		SessionLimits: Limits{MaxCost: 0.0001},
+	})
+	if errPayload == nil || errPayload.Code != api.ErrLimitCost || !strings.Contains(errPayload.Message, "session") {
+		t.Fatalf("expected session limit_cost, got %+v", errPayload)
+	}
+}
+
+func TestPriceTable_PrefixLookup(t *testing.T) {
+	prices := DefaultPriceTable()
+	if p, ok := prices.Lookup("gpt-4o-mini-2024-07-18"); !ok || p != prices["gpt-4o-mini"] {
+		t.Fatalf("expected gpt-4o-mini price, got %+v %v", p, ok)
+	}
+	if got := prices.Cost("gpt-4o", api.Usage{PromptTokens: 1_000_000}); got != 2.5 {
+		t.Fatalf("expected $2.50, got %v", got)
+	}
+	if got := prices.Cost("unknown-model", api.Usage{PromptTokens: 1000, CompletionTokens: 1000}); got != 0 {
+		t.Fatalf("expected unknown model to cost 0, got %v", got)
+	}
+}
@@ -0,0 +1,283 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/logger"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// llama.cpp
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// LlamaCppLLM implements the runtime LLM interface with llama.cpp server's native API:
+// /apply-template renders the chat with the model's own template and /completion
+// generates. With tools, the reply is constrained by a JSON schema (compiled to a grammar
+// by the server) to either {"content": ...} or {"tool_calls": [...]}, so tool calls
+// parse reliably even on models without tool support.
+type LlamaCppLLM struct {
+	baseURL    string
+	apiKey     string
+	model      string // informational; the server serves a single model
+	httpClient *http.Client
+
+	sampling
+}
+
+// NewLlamaCppLLM creates a client for a llama.cpp server (default http://localhost:8080).
+func NewLlamaCppLLM(baseURL, apiKey, model string) *LlamaCppLLM {
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	if model == "" {
+		model = "llama.cpp"
+	}
+	return &LlamaCppLLM{
+		baseURL:    strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/v1"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: 24 * time.Hour},
+		sampling:   defaultSampling(),
+	}
+}
+
+// Model returns the configured model name.
+func (c *LlamaCppLLM) Model() string { return c.model }
+
+// llamaCppToolRule is appended to the tool prompt; the schema enforces it.
+const llamaCppToolRule = "\nYour reply must be a JSON object: either {\"tool_calls\": [...]} or {\"content\": \"<your answer>\"}."
+
+func (c *LlamaCppLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	rule := ""
+	if len(req.Tools) > 0 {
+		rule = llamaCppToolRule
+	}
+	prompt, err := c.applyTemplate(ctx, promptToolRequest(req, rule).Messages)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]any{
+		"prompt":       prompt,
+		"stream":       true,
+		"cache_prompt": true,
+	}
+	if c.temperature != nil {
+		payload["temperature"] = *c.temperature
+	}
+	if c.topP != nil {
+		payload["top_p"] = *c.topP
+	}
+	if n := c.maxTokensFor(req); n > 0 {
+		payload["n_predict"] = n
+	}
+	if len(req.Tools) > 0 {
+		payload["json_schema"] = llamaCppReplySchema(req.Tools)
+	}
+
+	logger.Info("LLM", "Sending request to llama.cpp", map[string]interface{}{
+		"url":           c.baseURL + "/completion",
+		"message_count": len(req.Messages),
+		"tool_count":    len(req.Tools),
+	})
+	resp, err := postLLMJSON(ctx, c.httpClient, c.baseURL+"/completion", c.apiKey, payload)
+	if err != nil {
+		return nil, err
+	}
+	s := &llamaCppStream{body: resp.Body, reader: bufio.NewReader(resp.Body)}
+	if len(req.Tools) > 0 {
+		s.known = toolNameSet(req.Tools)
+	}
+	return s, nil
+}
+
+// applyTemplate renders messages into a prompt with the model's chat template.
+func (c *LlamaCppLLM) applyTemplate(ctx context.Context, messages []api.LLMMessage) (string, error) {
+	type msg struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	msgs := make([]msg, 0, len(messages))
+	for _, m := range messages {
+		msgs = append(msgs, msg{Role: m.Role, Content: messageText(m)}) // images become placeholders
+	}
+	resp, err := postLLMJSON(ctx, c.httpClient, c.baseURL+"/apply-template", c.apiKey, map[string]any{"messages": msgs})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("invalid /apply-template response: %w", err)
+	}
+	return out.Prompt, nil
+}
+
+// llamaCppReplySchema constrains replies to a text answer or calls to the given tools.
+func llamaCppReplySchema(tools []api.ToolSchema) map[string]any {
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	return map[string]any{
+		"anyOf": []any{
+			map[string]any{
+				"type":                 "object",
+				"properties":           map[string]any{"content": map[string]any{"type": "string"}},
+				"required":             []string{"content"},
+				"additionalProperties": false,
+			},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tool_calls": map[string]any{
+						"type":     "array",
+						"minItems": 1,
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"name":      map[string]any{"enum": names},
+								"arguments": map[string]any{"type": "object"},
+							},
+							"required": []string{"name", "arguments"},
+						},
+					},
+				},
+				"required":             []string{"tool_calls"},
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
+type llamaCppChunk struct {
+	Content         string `json:"content"`
+	Stop            bool   `json:"stop"`
+	StopType        string `json:"stop_type,omitempty"`     // "eos", "word" or "limit"
+	StoppedLimit    bool   `json:"stopped_limit,omitempty"` // older servers
+	TokensEvaluated int    `json:"tokens_evaluated,omitempty"`
+	TokensPredicted int    `json:"tokens_predicted,omitempty"`
+	Error           *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// llamaCppStream reads /completion server-sent events. With tools (known != nil) the JSON
+// reply is buffered and turned into text and tool calls when generation stops.
+type llamaCppStream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+	known  map[string]bool
+	reply  strings.Builder
+	queue  []LLMChunk
+	done   bool
+	think  thinkSplitter // <think> blocks from reasoning models (text replies only)
+}
+
+func (s *llamaCppStream) Recv(ctx context.Context) (LLMChunk, error) {
+	for {
+		if len(s.queue) > 0 {
+			ch := s.queue[0]
+			s.queue = s.queue[1:]
+			return ch, nil
+		}
+		if s.done {
+			return LLMChunk{}, io.EOF
+		}
+		if err := ctx.Err(); err != nil {
+			return LLMChunk{}, err
+		}
+
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return LLMChunk{}, io.ErrUnexpectedEOF
+			}
+			return LLMChunk{}, err
+		}
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var chunk llamaCppChunk
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			logger.Error("LLM", "Failed to unmarshal llama.cpp chunk", map[string]interface{}{
+				"error": err.Error(),
+			})
+			continue
+		}
+		if chunk.Error != nil {
+			s.done = true
+			return LLMChunk{}, fmt.Errorf("LLM stream error: %s", chunk.Error.Message)
+		}
+
+		if chunk.Content != "" {
+			if s.known == nil {
+				if text, reasoning := s.think.split(chunk.Content); text != "" || reasoning != "" {
+					s.queue = append(s.queue, LLMChunk{Delta: text, Reasoning: reasoning})
+				}
+			} else {
+				s.reply.WriteString(chunk.Content)
+			}
+		}
+		if chunk.Stop {
+			s.finish(chunk)
+		}
+	}
+}
+
+func (s *llamaCppStream) finish(last llamaCppChunk) {
+	s.done = true
+	end := LLMChunk{
+		FinishReason: "stop",
+		Usage:        &LLMUsage{PromptTokens: last.TokensEvaluated, CompletionTokens: last.TokensPredicted},
+	}
+	if last.StopType == "limit" || last.StoppedLimit {
+		end.FinishReason = "length"
+	}
+	end.Delta, end.Reasoning = s.think.flush()
+
+	if s.known != nil {
+		var reply promptToolReply
+		raw := s.reply.String()
+		if err := json.Unmarshal([]byte(raw), &reply); err != nil {
+			// Unconstrained or cut-off output: fall back to text parsing.
+			text, calls := parsePromptToolCalls(raw, s.known)
+			reply = promptToolReply{Content: text}
+			for _, c := range calls {
+				reply.ToolCalls = append(reply.ToolCalls, promptToolCall{Name: c.Name, Arguments: json.RawMessage(c.Args)})
+			}
+		}
+		if reply.Content != "" {
+			s.queue = append(s.queue, LLMChunk{Delta: reply.Content})
+		}
+		for _, c := range reply.ToolCalls {
+			if !s.known[c.Name] {
+				continue
+			}
+			s.queue = append(s.queue, LLMChunk{ToolCall: &api.LLMToolCall{
+				ID:   newToolCallID(),
+				Name: c.Name,
+				Args: string(rawToolArgs(string(c.Arguments))),
+			}})
+			end.FinishReason = "tool_calls"
+		}
+	}
+	s.queue = append(s.queue, end)
+}
+
+func (s *llamaCppStream) Close() error {
+	s.done = true
+	return s.body.Close()
+}
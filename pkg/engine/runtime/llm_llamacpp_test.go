@@ -0,0 +1,64 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestLlamaCppStream_ConstrainedToolCall(t *testing.T) {
+	var templated []map[string]string
+	var completion map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apply-template":
+			var body struct {
+				Messages []map[string]string `json:"messages"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			templated = body.Messages
+			fmt.Fprint(w, `{"prompt":"<rendered>"}`)
+		case "/completion":
+			_ = json.NewDecoder(r.Body).Decode(&completion)
+			for _, part := range []string{`{"tool_calls": [{"name": "ls", `, `"arguments": {"path": "docs"}}]}`} {
+				b, _ := json.Marshal(map[string]any{"content": part, "stop": false})
+				fmt.Fprintf(w, "data: %s\n\n", b)
+			}
+			fmt.Fprint(w, "data: {\"content\":\"\",\"stop\":true,\"stop_type\":\"eos\",\"tokens_evaluated\":30,\"tokens_predicted\":12}\n\n")
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	stream, err := NewLlamaCppLLM(srv.URL, "", "").Stream(context.Background(), LLMRequest{
+		Tools:    []api.ToolSchema{lsSchema},
+		Messages: []api.LLMMessage{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "list docs"}},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	chunks, err := recvAll(t, stream)
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+
+	if len(chunks) != 2 || chunks[0].ToolCall == nil || chunks[0].ToolCall.Args != `{"path": "docs"}` {
+		t.Fatalf("unexpected chunks: %+v", chunks)
+	}
+	if end := chunks[1]; end.FinishReason != "tool_calls" || end.Usage.PromptTokens != 30 || end.Usage.CompletionTokens != 12 {
+		t.Fatalf("unexpected finish chunk: %+v", end)
+	}
+	if completion["prompt"] != "<rendered>" || completion["json_schema"] == nil {
+		t.Fatalf("expected templated prompt and json_schema, got %v", completion)
+	}
+	if !strings.Contains(templated[0]["content"], "- ls: List files") {
+		t.Fatalf("expected tools in the system prompt, got %q", templated[0]["content"])
+	}
+}
@@ -0,0 +1,329 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/logger"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Sampling
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// sampling holds client-side sampling settings; nil leaves the server default.
+type sampling struct {
+	temperature *float64
+	topP        *float64
+	maxTokens   int // caps LLMRequest.MaxTokens (0 = no cap)
+}
+
+func defaultSampling() sampling {
+	t := 0.1
+	return sampling{temperature: &t}
+}
+
+// SetTemperature sets the sampling temperature (default 0.1).
+func (s *sampling) SetTemperature(t float64) { s.temperature = &t }
+
+// SetTopP sets nucleus sampling (default: server default).
+func (s *sampling) SetTopP(p float64) { s.topP = &p }
+
+// SetMaxTokens caps the completion length of every request (0 = no cap).
+func (s *sampling) SetMaxTokens(n int) { s.maxTokens = n }
+
+// maxTokensFor returns the completion limit for req (0 = none).
+func (s *sampling) maxTokensFor(req LLMRequest) int {
+	n := req.MaxTokens
+	if s.maxTokens > 0 && (n <= 0 || n > s.maxTokens) {
+		n = s.maxTokens
+	}
+	return n
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Ollama
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// OllamaLLM implements the runtime LLM interface with Ollama's native /api/chat endpoint,
+// which streams tool calls as structured JSON instead of relying on an OpenAI shim.
+type OllamaLLM struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+
+	sampling
+}
+
+// NewOllamaLLM creates a client for an Ollama server (default http://localhost:11434).
+// A trailing /v1 (the OpenAI-compatible prefix) is dropped.
+func NewOllamaLLM(baseURL, model string) *OllamaLLM {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	if model == "" {
+		model = "llama3.1"
+	}
+	return &OllamaLLM{
+		baseURL:    strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/v1"),
+		model:      model,
+		httpClient: &http.Client{Timeout: 24 * time.Hour},
+		sampling:   defaultSampling(),
+	}
+}
+
+// Model returns the configured model name.
+func (c *OllamaLLM) Model() string { return c.model }
+
+func (c *OllamaLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	payload := ollamaChatRequest{
+		Model:    c.model,
+		Messages: toOllamaMessages(req.Messages),
+		Stream:   true,
+		Options:  map[string]any{},
+	}
+	if len(req.Tools) > 0 {
+		payload.Tools = toOpenAITools(req.Tools)
+	}
+	if c.temperature != nil {
+		payload.Options["temperature"] = *c.temperature
+	}
+	if c.topP != nil {
+		payload.Options["top_p"] = *c.topP
+	}
+	if n := c.maxTokensFor(req); n > 0 {
+		payload.Options["num_predict"] = n
+	}
+
+	logger.Info("LLM", "Sending request to Ollama", map[string]interface{}{
+		"url":           c.baseURL + "/api/chat",
+		"model":         c.model,
+		"message_count": len(payload.Messages),
+		"tool_count":    len(payload.Tools),
+	})
+	resp, err := postLLMJSON(ctx, c.httpClient, c.baseURL+"/api/chat", "", payload)
+	if err != nil {
+		return nil, err
+	}
+	return &ollamaStream{body: resp.Body, reader: bufio.NewReader(resp.Body)}, nil
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []openAITool    `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"` // for tool results
+	Images    [][]byte         `json:"images,omitempty"`    // base64-encoded in JSON
+	Thinking  string           `json:"thinking,omitempty"`  // reasoning (models run with think)
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"` // a JSON object, not a string
+	} `json:"function"`
+}
+
+type ollamaChatChunk struct {
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason,omitempty"`
+	PromptEvalCount int           `json:"prompt_eval_count,omitempty"`
+	EvalCount       int           `json:"eval_count,omitempty"`
+	Error           string        `json:"error,omitempty"`
+}
+
+func toOllamaMessages(messages []api.LLMMessage) []ollamaMessage {
+	names := make(map[string]string) // tool call ID -> tool name
+	messages = liftToolImages(replayReasoning(messages))
+	out := make([]ollamaMessage, 0, len(messages))
+	for _, msg := range messages {
+		m := ollamaMessage{Role: msg.Role, Content: msg.Content, Thinking: msg.Reasoning}
+		if len(msg.Parts) > 0 {
+			text := msg
+			text.Parts = nil
+			for _, p := range msg.Parts {
+				if p.Type == api.PartImage && len(p.Data) > 0 {
+					m.Images = append(m.Images, p.Data)
+				} else {
+					text.Parts = append(text.Parts, p)
+				}
+			}
+			m.Content = messageText(text)
+		}
+		for _, tc := range msg.ToolCalls {
+			names[tc.ID] = tc.Name
+			var call ollamaToolCall
+			call.Function.Name = tc.Name
+			call.Function.Arguments = rawToolArgs(tc.Args)
+			m.ToolCalls = append(m.ToolCalls, call)
+		}
+		if msg.Role == "tool" {
+			m.ToolName = names[msg.ToolCallID]
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// ollamaStream reads Ollama's newline-delimited JSON stream.
+type ollamaStream struct {
+	body     io.ReadCloser
+	reader   *bufio.Reader
+	queue    []LLMChunk
+	done     bool
+	sawTools bool
+	think    thinkSplitter // <think> blocks when the server does not separate them
+}
+
+func (s *ollamaStream) Recv(ctx context.Context) (LLMChunk, error) {
+	for {
+		if len(s.queue) > 0 {
+			ch := s.queue[0]
+			s.queue = s.queue[1:]
+			return ch, nil
+		}
+		if s.done {
+			return LLMChunk{}, io.EOF
+		}
+		if err := ctx.Err(); err != nil {
+			return LLMChunk{}, err
+		}
+
+		line, err := s.reader.ReadBytes('\n')
+		if err != nil && !(err == io.EOF && len(bytes.TrimSpace(line)) > 0) {
+			if err == io.EOF {
+				// The final chunk always has done=true; anything else is a dropped connection.
+				return LLMChunk{}, io.ErrUnexpectedEOF
+			}
+			return LLMChunk{}, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			logger.Error("LLM", "Failed to unmarshal Ollama chunk", map[string]interface{}{
+				"error": err.Error(),
+			})
+			continue
+		}
+		if chunk.Error != "" {
+			s.done = true
+			return LLMChunk{}, fmt.Errorf("LLM stream error: %s", chunk.Error)
+		}
+
+		text, inline := s.think.split(chunk.Message.Content)
+		if reasoning := chunk.Message.Thinking + inline; reasoning != "" || text != "" {
+			s.queue = append(s.queue, LLMChunk{Delta: text, Reasoning: reasoning})
+		}
+		for _, tc := range chunk.Message.ToolCalls {
+			s.sawTools = true
+			s.queue = append(s.queue, LLMChunk{ToolCall: &api.LLMToolCall{
+				ID:   newToolCallID(),
+				Name: tc.Function.Name,
+				Args: string(rawToolArgs(string(tc.Function.Arguments))),
+			}})
+		}
+		if chunk.Done {
+			s.done = true
+			finish := chunk.DoneReason
+			if s.sawTools {
+				finish = "tool_calls"
+			} else if finish == "" {
+				finish = "stop"
+			}
+			text, reasoning := s.think.flush()
+			s.queue = append(s.queue, LLMChunk{
+				Delta:        text,
+				Reasoning:    reasoning,
+				FinishReason: finish,
+				Usage:        &LLMUsage{PromptTokens: chunk.PromptEvalCount, CompletionTokens: chunk.EvalCount},
+			})
+		}
+	}
+}
+
+func (s *ollamaStream) Close() error {
+	s.done = true
+	return s.body.Close()
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Helpers shared by the local backends
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// postLLMJSON posts payload and returns the response, or an *LLMStatusError for non-200
+// responses so ResilientLLM can classify it.
+func postLLMJSON(ctx context.Context, client *http.Client, url, apiKey string, payload any) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		logger.Error("LLM", "HTTP request failed", map[string]interface{}{
+			"error": err.Error(),
+			"url":   url,
+		})
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(resp.Body)
+		logger.Error("LLM", "LLM API returned error", map[string]interface{}{
+			"status_code": resp.StatusCode,
+			"error":       strings.TrimSpace(string(raw)),
+			"url":         url,
+		})
+		return nil, &LLMStatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Message:    strings.TrimSpace(string(raw)),
+		}
+	}
+	return resp, nil
+}
+
+// rawToolArgs returns tool arguments as a JSON object, using {} for empty or invalid input.
+func rawToolArgs(args string) json.RawMessage {
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, "{") && json.Valid([]byte(args)) {
+		return json.RawMessage(args)
+	}
+	return json.RawMessage("{}")
+}
+
+var toolCallSeq atomic.Int64
+
+// newToolCallID generates an ID for backends that do not assign tool call IDs.
+func newToolCallID() string {
+	return fmt.Sprintf("call_%d_%d", time.Now().UnixNano(), toolCallSeq.Add(1))
+}
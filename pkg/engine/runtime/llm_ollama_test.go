@@ -0,0 +1,106 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// recvAll reads a stream to the end.
+func recvAll(t *testing.T, stream LLMStream) ([]LLMChunk, error) {
+	t.Helper()
+	defer stream.Close()
+	var chunks []LLMChunk
+	for {
+		ch, err := stream.Recv(context.Background())
+		if err == io.EOF {
+			return chunks, nil
+		}
+		if err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, ch)
+	}
+}
+
+var lsSchema = api.ToolSchema{Name: "ls", Description: "List files", Parameters: map[string]any{"type": "object"}}
+
+func TestOllamaStream_MapsToolCallsAndUsage(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		for _, line := range []string{
+			`{"message":{"role":"assistant","content":"Let me look."},"done":false}`,
+			`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"ls","arguments":{"path":"src"}}}]},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":42,"eval_count":9}`,
+		} {
+			fmt.Fprintln(w, line)
+		}
+	}))
+	defer srv.Close()
+
+	llm := NewOllamaLLM(srv.URL+"/v1", "qwen2.5")
+	llm.SetMaxTokens(256)
+	stream, err := llm.Stream(context.Background(), LLMRequest{
+		Tools: []api.ToolSchema{lsSchema},
+		Messages: []api.LLMMessage{
+			{Role: "user", Content: "list src"},
+			{Role: "assistant", ToolCalls: []api.LLMToolCall{{ID: "c1", Name: "ls", Args: `{"path":"."}`}}},
+			{Role: "tool", ToolCallID: "c1", Content: "src/"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	chunks, err := recvAll(t, stream)
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+
+	if len(chunks) != 3 || chunks[0].Delta != "Let me look." {
+		t.Fatalf("unexpected chunks: %+v", chunks)
+	}
+	if tc := chunks[1].ToolCall; tc == nil || tc.Name != "ls" || tc.Args != `{"path":"src"}` || tc.ID == "" {
+		t.Fatalf("unexpected tool call: %+v", chunks[1].ToolCall)
+	}
+	if end := chunks[2]; end.FinishReason != "tool_calls" || end.Usage == nil || end.Usage.PromptTokens != 42 || end.Usage.CompletionTokens != 9 {
+		t.Fatalf("unexpected finish chunk: %+v", end)
+	}
+
+	// History goes out in Ollama's shape: arguments as objects, tool results named.
+	msgs := sent["messages"].([]any)
+	call := msgs[1].(map[string]any)["tool_calls"].([]any)[0].(map[string]any)["function"].(map[string]any)
+	if args, ok := call["arguments"].(map[string]any); !ok || args["path"] != "." {
+		t.Fatalf("expected object arguments, got %v", call["arguments"])
+	}
+	if msgs[2].(map[string]any)["tool_name"] != "ls" {
+		t.Fatalf("expected tool_name on tool result, got %v", msgs[2])
+	}
+	if opts := sent["options"].(map[string]any); opts["num_predict"] != float64(256) || opts["temperature"] != 0.1 {
+		t.Fatalf("unexpected options: %v", opts)
+	}
+}
+
+func TestOllamaStream_DroppedStreamIsAnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"Hel"},"done":false}`)
+	}))
+	defer srv.Close()
+
+	stream, err := NewOllamaLLM(srv.URL, "qwen2.5").Stream(context.Background(), LLMRequest{})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if _, err := recvAll(t, stream); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected unexpected EOF, got %v", err)
+	}
+}
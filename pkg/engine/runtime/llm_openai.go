@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +24,11 @@ type OpenAILLM struct {
 	apiKey     string
 	model      string
 	httpClient *http.Client
+
+	// streamUsage asks for a final usage chunk (stream_options.include_usage).
+	streamUsage bool
+
+	sampling
 }
 
 // NewOpenAILLMFromEnv builds an OpenAI-compatible client from environment variables.
@@ -53,24 +59,34 @@ func NewOpenAILLM(baseURL, apiKey, model string) *OpenAILLM {
 		model = "gpt-4o-mini"
 	}
 	return &OpenAILLM{
-		baseURL: baseURL,
-		apiKey:  apiKey,
-		model:   model,
+		baseURL:     baseURL,
+		apiKey:      apiKey,
+		model:       model,
+		streamUsage: true,
+		sampling:    defaultSampling(),
 		httpClient: &http.Client{
 			Timeout: 24 * time.Hour, // Long timeout for streaming long content
 		},
 	}
 }
 
+// Model returns the configured model name.
+func (c *OpenAILLM) Model() string { return c.model }
+
+// SetStreamUsage toggles stream_options.include_usage, for gateways that reject it.
+func (c *OpenAILLM) SetStreamUsage(enabled bool) { c.streamUsage = enabled }
+
 func (c *OpenAILLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
 	payload := openAIChatCompletionRequest{
 		Model:       c.model,
 		Messages:    toOpenAIMessages(req.Messages),
 		Stream:      true,
-		Temperature: 0.1,
+		Temperature: c.temperature,
+		TopP:        c.topP,
 	}
-	if req.MaxTokens > 0 {
-		payload.MaxTokens = req.MaxTokens
+	payload.MaxTokens = c.maxTokensFor(req)
+	if c.streamUsage {
+		payload.StreamOpts = map[string]any{"include_usage": true}
 	}
 	if len(req.Tools) > 0 {
 		payload.Tools = toOpenAITools(req.Tools)
@@ -141,7 +157,11 @@ func (c *OpenAILLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, erro
 			})
 		}
 
-		return nil, fmt.Errorf("LLM API error (status %d): %s", resp.StatusCode, errMsg)
+		return nil, &LLMStatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Message:    errMsg,
+		}
 	}
 
 	logger.Info("LLM", "LLM API request successful, starting stream", map[string]interface{}{
@@ -151,11 +171,38 @@ func (c *OpenAILLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, erro
 	return newOpenAIStream(resp.Body), nil
 }
 
+// LLMStatusError is a non-200 response from the LLM API.
+type LLMStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header; 0 when absent
+	Message    string
+}
+
+func (e *LLMStatusError) Error() string {
+	return fmt.Sprintf("LLM API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
 type openAIChatCompletionRequest struct {
 	Model       string            `json:"model"`
 	Messages    []openAIChatMsg   `json:"messages"`
 	MaxTokens   int               `json:"max_tokens,omitempty"`
-	Temperature float64           `json:"temperature,omitempty"`
+	Temperature *float64          `json:"temperature,omitempty"`
+	TopP        *float64          `json:"top_p,omitempty"`
 	Stream      bool              `json:"stream"`
 	Tools       []openAITool      `json:"tools,omitempty"`
 	ToolChoice  string            `json:"tool_choice,omitempty"`
@@ -177,12 +224,25 @@ type openAIFunc struct {
 
 type openAIChatMsg struct {
 	Role    string `json:"role"`
-	Content string `json:"content"` // removed omitempty to avoid null/undefined
+	Content any    `json:"content"` // string, or []openAIContentPart with images; never null
+
+	// ReasoningContent replays reasoning behind tool calls (DeepSeek, Kimi; see replayReasoning).
+	ReasoningContent string `json:"reasoning_content,omitempty"`
 
 	ToolCallID string           `json:"tool_call_id,omitempty"`
 	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
 }
 
+type openAIContentPart struct {
+	Type     string          `json:"type"` // "text" | "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"` // data: URL
+}
+
 type openAIToolCall struct {
 	Index    int            `json:"index"`
 	ID       string         `json:"id"`
@@ -198,11 +258,15 @@ type openAIFuncCall struct {
 type openAIStreamChunk struct {
 	Choices []struct {
 		Delta struct {
-			Content   string           `json:"content,omitempty"`
-			ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+			Content          string           `json:"content,omitempty"`
+			ReasoningContent string           `json:"reasoning_content,omitempty"` // DeepSeek, vLLM, llama.cpp
+			Reasoning        string           `json:"reasoning,omitempty"`         // OpenRouter, Ollama
+			ToolCalls        []openAIToolCall `json:"tool_calls,omitempty"`
 		} `json:"delta"`
 		FinishReason string `json:"finish_reason,omitempty"`
 	} `json:"choices"`
+	// Usage arrives in a final chunk with empty choices when include_usage is set.
+	Usage *openAIUsage `json:"usage,omitempty"`
 	// Error response from API (e.g., stream read error)
 	Error *struct {
 		Message string `json:"message"`
@@ -210,6 +274,22 @@ type openAIStreamChunk struct {
 	} `json:"error,omitempty"`
 }
 
+type openAIUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	PromptTokensDetails *struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details,omitempty"`
+}
+
+func (u *openAIUsage) toLLMUsage() *LLMUsage {
+	out := &LLMUsage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
+	if u.PromptTokensDetails != nil {
+		out.CachedTokens = u.PromptTokensDetails.CachedTokens
+	}
+	return out
+}
+
 func toOpenAITools(tools []api.ToolSchema) []openAITool {
 	out := make([]openAITool, 0, len(tools))
 	for _, t := range tools {
@@ -226,6 +306,7 @@ func toOpenAITools(tools []api.ToolSchema) []openAITool {
 }
 
 func toOpenAIMessages(messages []api.LLMMessage) []openAIChatMsg {
+	messages = liftToolImages(replayReasoning(messages))
 	out := make([]openAIChatMsg, 0, len(messages))
 	for _, msg := range messages {
 		// Ensure content is never null - use empty string if no content
@@ -236,8 +317,14 @@ func toOpenAIMessages(messages []api.LLMMessage) []openAIChatMsg {
 		}
 
 		m := openAIChatMsg{
-			Role:    msg.Role,
-			Content: content,
+			Role:             msg.Role,
+			Content:          content,
+			ReasoningContent: msg.Reasoning,
+		}
+		if msg.HasImages() {
+			m.Content = toOpenAIContentParts(msg)
+		} else if len(msg.Parts) > 0 {
+			m.Content = messageText(msg)
 		}
 		if msg.Role == "tool" {
 			m.ToolCallID = msg.ToolCallID
@@ -259,6 +346,26 @@ func toOpenAIMessages(messages []api.LLMMessage) []openAIChatMsg {
 	return out
 }
 
+// toOpenAIContentParts renders a message with images as a content array.
+func toOpenAIContentParts(msg api.LLMMessage) []openAIContentPart {
+	var parts []openAIContentPart
+	if msg.Content != "" {
+		parts = append(parts, openAIContentPart{Type: "text", Text: msg.Content})
+	}
+	for _, p := range msg.Parts {
+		if p.Type != api.PartImage {
+			parts = append(parts, openAIContentPart{Type: "text", Text: partText(p)})
+			continue
+		}
+		if len(p.Data) == 0 {
+			parts = append(parts, openAIContentPart{Type: "text", Text: fmt.Sprintf("[Image unavailable: %s]", partName(p))})
+			continue
+		}
+		parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: dataURL(p)}})
+	}
+	return parts
+}
+
 type openAIStream struct {
 	body   io.ReadCloser
 	reader *bufio.Reader
@@ -267,7 +374,14 @@ type openAIStream struct {
 	queue []LLMChunk
 	done  bool
 
+	// finish is held back until the usage chunk (or end of stream) arrives,
+	// so usage can ride on the finish chunk.
+	finish *LLMChunk
+	usage  *LLMUsage
+
 	toolBuilders map[int]*openAIToolCallBuilder
+
+	think thinkSplitter // <think> blocks inline in content
 }
 
 type openAIToolCallBuilder struct {
@@ -309,10 +423,7 @@ func (s *openAIStream) Recv(ctx context.Context) (LLMChunk, error) {
 		line, err := s.reader.ReadString('\n')
 		if err != nil {
 			if err == io.EOF {
-				s.mu.Lock()
-				s.done = true
-				s.mu.Unlock()
-				return LLMChunk{}, io.EOF
+				return s.end()
 			}
 			return LLMChunk{}, err
 		}
@@ -324,10 +435,7 @@ func (s *openAIStream) Recv(ctx context.Context) (LLMChunk, error) {
 
 		data := strings.TrimPrefix(line, "data: ")
 		if data == "[DONE]" {
-			s.mu.Lock()
-			s.done = true
-			s.mu.Unlock()
-			return LLMChunk{}, io.EOF
+			return s.end()
 		}
 
 		var chunk openAIStreamChunk
@@ -354,7 +462,20 @@ func (s *openAIStream) Recv(ctx context.Context) (LLMChunk, error) {
 			return LLMChunk{}, fmt.Errorf("LLM stream error: %s", chunk.Error.Message)
 		}
 
+		if chunk.Usage != nil {
+			s.mu.Lock()
+			s.usage = chunk.Usage.toLLMUsage()
+			held := s.finish != nil
+			s.mu.Unlock()
+			if held {
+				return s.end()
+			}
+		}
+
 		if len(chunk.Choices) == 0 {
+			if chunk.Usage != nil {
+				continue
+			}
 			logger.Info("LLM", "Empty chunk received", map[string]interface{}{
 				"service": "agent-engine",
 			})
@@ -394,9 +515,11 @@ func (s *openAIStream) Recv(ctx context.Context) (LLMChunk, error) {
 			}
 		}
 
-		// Text delta.
-		if delta.Content != "" {
-			return LLMChunk{Delta: delta.Content}, nil
+		// Text and reasoning deltas.
+		reasoning := delta.ReasoningContent + delta.Reasoning
+		text, inline := s.think.split(delta.Content)
+		if text != "" || reasoning+inline != "" {
+			return LLMChunk{Delta: text, Reasoning: reasoning + inline}, nil
 		}
 
 		if finish != "" {
@@ -434,7 +557,16 @@ func (s *openAIStream) Recv(ctx context.Context) (LLMChunk, error) {
 				s.toolBuilders = make(map[int]*openAIToolCallBuilder)
 			}
 
-			s.queue = append(s.queue, LLMChunk{FinishReason: finish})
+			s.finish = &LLMChunk{FinishReason: finish}
+			s.finish.Delta, s.finish.Reasoning = s.think.flush()
+			if s.usage == nil && len(s.queue) == 0 {
+				// Keep reading: the usage chunk follows the finish chunk.
+				s.mu.Unlock()
+				continue
+			}
+			if s.usage != nil {
+				s.finishLocked()
+			}
 			ch := s.queue[0]
 			s.queue = s.queue[1:]
 			s.mu.Unlock()
@@ -442,6 +574,29 @@ func (s *openAIStream) Recv(ctx context.Context) (LLMChunk, error) {
 		}
 	}
 }
+
+// end marks the stream done and flushes a held finish chunk (with usage, if any).
+func (s *openAIStream) end() (LLMChunk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	s.finishLocked()
+	if len(s.queue) == 0 {
+		return LLMChunk{}, io.EOF
+	}
+	ch := s.queue[0]
+	s.queue = s.queue[1:]
+	return ch, nil
+}
+
+func (s *openAIStream) finishLocked() {
+	if s.finish == nil {
+		return
+	}
+	s.finish.Usage = s.usage
+	s.queue = append(s.queue, *s.finish)
+	s.finish = nil
+}
 func (s *openAIStream) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
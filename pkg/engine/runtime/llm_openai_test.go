@@ -0,0 +1,154 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestOpenAIStream_AttachesUsageToFinishChunk(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, data := range []string{
+			`{"choices":[{"delta":{"content":"Hel"}}]}`,
+			`{"choices":[{"delta":{"content":"lo"}}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":120,"completion_tokens":7,"prompt_tokens_details":{"cached_tokens":100}}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+	}))
+	defer srv.Close()
+
+	llm := NewOpenAILLM(srv.URL, "test-key", "gpt-4o-mini")
+	stream, err := llm.Stream(context.Background(), LLMRequest{Messages: []api.LLMMessage{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	defer stream.Close()
+
+	if opts, _ := sent["stream_options"].(map[string]any); opts["include_usage"] != true {
+		t.Fatalf("expected stream_options.include_usage, got %v", sent["stream_options"])
+	}
+
+	var text string
+	var finish LLMChunk
+	for {
+		ch, err := stream.Recv(context.Background())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		text += ch.Delta
+		if ch.FinishReason != "" {
+			finish = ch
+		}
+	}
+	if text != "Hello" || finish.FinishReason != "stop" {
+		t.Fatalf("unexpected stream: text=%q finish=%+v", text, finish)
+	}
+	want := LLMUsage{PromptTokens: 120, CompletionTokens: 7, CachedTokens: 100}
+	if finish.Usage == nil || *finish.Usage != want {
+		t.Fatalf("expected usage %+v on finish chunk, got %+v", want, finish.Usage)
+	}
+}
+
+func TestOpenAIStream_SendsSamplingSettings(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	llm := NewOpenAILLM(srv.URL, "test-key", "gpt-4o-mini")
+	llm.SetTemperature(0)
+	llm.SetMaxTokens(500)
+	stream, err := llm.Stream(context.Background(), LLMRequest{MaxTokens: 2000})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	stream.Close()
+
+	// Temperature 0 must be sent explicitly; max_tokens is capped by the client setting.
+	if sent["temperature"] != float64(0) || sent["max_tokens"] != float64(500) {
+		t.Fatalf("unexpected sampling settings: temperature=%v max_tokens=%v", sent["temperature"], sent["max_tokens"])
+	}
+	if _, ok := sent["top_p"]; ok {
+		t.Fatalf("expected top_p to be omitted, got %v", sent["top_p"])
+	}
+}
+
+func TestToOpenAIMessages_SendsImagesAsContentParts(t *testing.T) {
+	png := api.ImagePart("image/png", []byte{0x89, 'P', 'N', 'G'}, "shot.png")
+	msgs := toOpenAIMessages([]api.LLMMessage{
+		{Role: "user", Content: "what is wrong?", Parts: []api.ContentPart{png}},
+		{Role: "assistant", ToolCalls: []api.LLMToolCall{{ID: "c1", Name: "read_file", Args: `{"path":"ui.png"}`}}},
+		{Role: "tool", ToolCallID: "c1", Content: "Image: ui.png", Parts: []api.ContentPart{png}},
+		{Role: "assistant", Content: "done"},
+	})
+	b, _ := json.Marshal(msgs)
+	var got []map[string]any
+	_ = json.Unmarshal(b, &got)
+
+	if len(got) != 5 {
+		t.Fatalf("expected tool image lifted into a user message, got %s", b)
+	}
+	parts, ok := got[0]["content"].([]any)
+	if !ok || len(parts) != 2 {
+		t.Fatalf("expected text and image parts, got %s", b)
+	}
+	image := parts[1].(map[string]any)["image_url"].(map[string]any)
+	if image["url"] != "data:image/png;base64,iVBORw==" {
+		t.Fatalf("unexpected image url %v", image["url"])
+	}
+	if got[2]["role"] != "tool" || got[2]["content"] != "Image: ui.png" {
+		t.Fatalf("expected text-only tool message, got %v", got[2])
+	}
+	if got[3]["role"] != "user" || got[4]["content"] != "done" {
+		t.Fatalf("expected lifted images before the next assistant message, got %s", b)
+	}
+}
+
+func TestSessionUsageRecords_SplitByDaySkillAndModel(t *testing.T) {
+	r := NewTurnRunner(TurnRunnerConfig{Model: "gpt-4o", Prices: DefaultPriceTable()})
+	r.session = &api.Session{SessionID: "s1", ActiveSkill: "novel"}
+	r.recordLLMCall(LLMRequest{}, "", nil, &LLMUsage{PromptTokens: 1000, CompletionTokens: 100, CachedTokens: 400})
+	r.session.ActiveSkill = ""
+	r.recordToolCall()
+
+	total := sessionUsage(r.session)
+	if total.LLMCalls != 1 || total.ToolCalls != 1 || total.CachedTokens != 400 || total.EstimatedCalls != 0 {
+		t.Fatalf("unexpected total: %+v", total)
+	}
+	// 600 uncached * 2.50 + 400 cached * 1.25 + 100 output * 10.00, per million.
+	if want := 0.003; total.Cost < want-1e-9 || total.Cost > want+1e-9 {
+		t.Fatalf("expected cost %v, got %v", want, total.Cost)
+	}
+
+	recs := SessionUsageRecords(r.session)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %+v", recs)
+	}
+	skills := map[string]bool{}
+	for _, rec := range recs {
+		skills[rec.Skill] = true
+		if rec.SessionID != "s1" || rec.Model != "gpt-4o" || rec.Day == "" {
+			t.Fatalf("unexpected record: %+v", rec)
+		}
+	}
+	if !skills["novel"] || !skills[""] {
+		t.Fatalf("expected records for skill and no skill, got %+v", recs)
+	}
+}
@@ -0,0 +1,119 @@
+package runtime
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/store"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Content Parts
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// imageTokenEstimate is a rough per-image token cost for budgeting (a mid-size
+// screenshot at high detail).
+const imageTokenEstimate = 800
+
+// liftToolImages moves images out of tool messages, which chat APIs only accept as
+// text, into a user message after the run of tool results.
+func liftToolImages(messages []api.LLMMessage) []api.LLMMessage {
+	var out []api.LLMMessage
+	var lifted []api.ContentPart
+	flush := func() {
+		if len(lifted) > 0 {
+			out = append(out, api.LLMMessage{Role: "user", Content: "Images returned by tools:", Parts: lifted})
+			lifted = nil
+		}
+	}
+	for i, m := range messages {
+		if m.Role != "tool" || len(m.Parts) == 0 {
+			if m.Role != "tool" {
+				flush()
+			}
+			if out != nil {
+				out = append(out, m)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]api.LLMMessage, 0, len(messages)+1), messages[:i]...)
+		}
+		text := m
+		text.Parts = nil
+		for _, p := range m.Parts {
+			if p.Type == api.PartImage {
+				lifted = append(lifted, p)
+			} else {
+				text.Parts = append(text.Parts, p)
+			}
+		}
+		text.Content = messageText(text)
+		text.Parts = nil
+		out = append(out, text)
+	}
+	if out == nil {
+		return messages
+	}
+	flush()
+	return out
+}
+
+// eventParts returns parts for an event: inline data is replaced by its blob ref, so
+// images do not end up base64-encoded in the event log and NDJSON output. The bytes
+// stay on the session message, where the session store keeps them as a blob.
+func eventParts(parts []api.ContentPart) []api.ContentPart {
+	if len(parts) == 0 {
+		return parts
+	}
+	out := append([]api.ContentPart(nil), parts...)
+	for i := range out {
+		if len(out[i].Data) > 0 {
+			out[i].BlobRef = store.BlobRef(out[i].Data)
+			out[i].Data = nil
+		}
+	}
+	return out
+}
+
+// messageText renders a message as plain text, with placeholders for images, for
+// backends and messages that cannot carry them.
+func messageText(m api.LLMMessage) string {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	texts := []string{m.Content}
+	for _, p := range m.Parts {
+		if p.Type == api.PartImage {
+			texts = append(texts, fmt.Sprintf("[Image: %s]", partName(p)))
+		} else {
+			texts = append(texts, partText(p))
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
+
+// partText renders a text or file part.
+func partText(p api.ContentPart) string {
+	if p.Type == api.PartFile {
+		if p.Text == "" {
+			return fmt.Sprintf("[Attached file: %s]", p.Path)
+		}
+		return fmt.Sprintf("Attached file %s:\n%s", p.Path, p.Text)
+	}
+	return p.Text
+}
+
+func partName(p api.ContentPart) string {
+	if p.Path != "" {
+		return p.Path
+	}
+	return p.MIMEType
+}
+
+// dataURL encodes an image part as a data: URL.
+func dataURL(p api.ContentPart) string {
+	return "data:" + p.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+}
@@ -0,0 +1,226 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/prompts"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Prompt-based Tool Calling
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// PromptToolLLM adds tool calling to models without native tool support: tool schemas go
+// into the system prompt, tool history is rewritten as text, and JSON tool calls
+// ({"tool_calls": [{"name": ..., "arguments": {...}}]}) are parsed out of the reply.
+type PromptToolLLM struct {
+	llm LLM
+}
+
+// NewPromptToolLLM wraps llm with prompt-based tool calling.
+func NewPromptToolLLM(llm LLM) *PromptToolLLM {
+	return &PromptToolLLM{llm: llm}
+}
+
+func (p *PromptToolLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	stream, err := p.llm.Stream(ctx, promptToolRequest(req, ""))
+	if err != nil || len(req.Tools) == 0 {
+		return stream, err
+	}
+	return &promptToolStream{inner: stream, known: toolNameSet(req.Tools)}, nil
+}
+
+// promptToolCall and promptToolReply are the JSON shapes models are asked to produce.
+type promptToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type promptToolReply struct {
+	Content   string           `json:"content,omitempty"`
+	ToolCalls []promptToolCall `json:"tool_calls,omitempty"`
+}
+
+// promptToolRequest moves tool schemas into the system prompt (followed by extra) and
+// rewrites tool calls and results in the history as plain messages.
+func promptToolRequest(req LLMRequest, extra string) LLMRequest {
+	out := LLMRequest{MaxTokens: req.MaxTokens, Messages: flattenToolMessages(req.Messages)}
+	if len(req.Tools) == 0 {
+		return out
+	}
+
+	section := toolPrompt(req.Tools) + extra
+	if len(out.Messages) > 0 && out.Messages[0].Role == "system" {
+		out.Messages[0].Content += "\n\n" + section
+	} else {
+		out.Messages = append([]api.LLMMessage{{Role: "system", Content: section}}, out.Messages...)
+	}
+	return out
+}
+
+func toolPrompt(tools []api.ToolSchema) string {
+	var sb strings.Builder
+	intro := prompts.DefaultLoader.Get(prompts.ToolCalling)
+	if intro == "" {
+		intro = `To call tools, reply with only {"tool_calls": [{"name": "<tool>", "arguments": {...}}]}.`
+	}
+	sb.WriteString(intro)
+	sb.WriteString("\n")
+	for _, t := range tools {
+		params, _ := json.Marshal(t.Parameters)
+		sb.WriteString(fmt.Sprintf("\n- %s: %s\n  parameters: %s\n", t.Name, t.Description, params))
+	}
+	return sb.String()
+}
+
+func flattenToolMessages(messages []api.LLMMessage) []api.LLMMessage {
+	names := make(map[string]string) // tool call ID -> tool name
+	out := make([]api.LLMMessage, 0, len(messages))
+	for _, m := range messages {
+		switch {
+		case m.Role == "assistant" && len(m.ToolCalls) > 0:
+			var reply promptToolReply
+			for _, tc := range m.ToolCalls {
+				names[tc.ID] = tc.Name
+				reply.ToolCalls = append(reply.ToolCalls, promptToolCall{Name: tc.Name, Arguments: rawToolArgs(tc.Args)})
+			}
+			b, _ := json.Marshal(reply)
+			out = append(out, api.LLMMessage{Role: "assistant", Content: strings.TrimSpace(m.Content + "\n" + string(b))})
+		case m.Role == "tool":
+			// Tool results become user messages, which can carry images.
+			out = append(out, api.LLMMessage{Role: "user", Content: fmt.Sprintf("Tool result (%s):\n%s", names[m.ToolCallID], m.Content), Parts: m.Parts})
+		default:
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func toolNameSet(tools []api.ToolSchema) map[string]bool {
+	known := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		known[t.Name] = true
+	}
+	return known
+}
+
+// parsePromptToolCalls finds the first JSON object in text that calls known tools, either
+// {"tool_calls": [...]} or a single {"name": ..., "arguments": ...}. It returns the text
+// around it (without code fences) and the calls; without calls, text is returned as is.
+func parsePromptToolCalls(text string, known map[string]bool) (string, []api.LLMToolCall) {
+	for i := strings.IndexByte(text, '{'); i >= 0; {
+		dec := json.NewDecoder(strings.NewReader(text[i:]))
+		var obj struct {
+			promptToolReply
+			promptToolCall
+		}
+		if err := dec.Decode(&obj); err == nil {
+			calls := obj.ToolCalls
+			if len(calls) == 0 && obj.Name != "" {
+				calls = []promptToolCall{obj.promptToolCall}
+			}
+			var out []api.LLMToolCall
+			for _, c := range calls {
+				if known[c.Name] {
+					out = append(out, api.LLMToolCall{ID: newToolCallID(), Name: c.Name, Args: string(rawToolArgs(string(c.Arguments)))})
+				}
+			}
+			if len(out) > 0 {
+				before := strings.TrimSpace(text[:i])
+				before = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(before, "```json"), "```"))
+				after := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text[i+int(dec.InputOffset()):]), "```"))
+				return strings.TrimSpace(before + "\n" + after), out
+			}
+		}
+		next := strings.IndexByte(text[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return text, nil
+}
+
+// promptToolStream streams text until something that may start a tool call appears,
+// then buffers the rest and parses tool calls when the reply ends.
+type promptToolStream struct {
+	inner   LLMStream
+	known   map[string]bool
+	held    strings.Builder
+	holding bool
+	queue   []LLMChunk
+	done    bool
+}
+
+func (s *promptToolStream) Recv(ctx context.Context) (LLMChunk, error) {
+	for {
+		if len(s.queue) > 0 {
+			ch := s.queue[0]
+			s.queue = s.queue[1:]
+			return ch, nil
+		}
+		if s.done {
+			return LLMChunk{}, io.EOF
+		}
+
+		ch, err := s.inner.Recv(ctx)
+		if err == io.EOF {
+			s.flush(LLMChunk{FinishReason: "stop"})
+			continue
+		}
+		if err != nil {
+			return LLMChunk{}, err
+		}
+		if ch.Reasoning != "" {
+			s.queue = append(s.queue, LLMChunk{Reasoning: ch.Reasoning})
+		}
+		if ch.Delta != "" {
+			if text := s.hold(ch.Delta); text != "" {
+				s.queue = append(s.queue, LLMChunk{Delta: text})
+			}
+		}
+		if ch.FinishReason != "" {
+			s.flush(ch)
+		}
+	}
+}
+
+func (s *promptToolStream) hold(delta string) string {
+	if s.holding {
+		s.held.WriteString(delta)
+		return ""
+	}
+	i := strings.IndexAny(delta, "{`")
+	if i < 0 {
+		return delta
+	}
+	s.holding = true
+	s.held.WriteString(delta[i:])
+	return delta[:i]
+}
+
+// flush emits the held text and any parsed tool calls, then the finish chunk.
+func (s *promptToolStream) flush(finish LLMChunk) {
+	s.done = true
+	text, calls := parsePromptToolCalls(s.held.String(), s.known)
+	if text != "" {
+		s.queue = append(s.queue, LLMChunk{Delta: text})
+	}
+	for i := range calls {
+		s.queue = append(s.queue, LLMChunk{ToolCall: &calls[i]})
+	}
+	end := LLMChunk{FinishReason: finish.FinishReason, Usage: finish.Usage}
+	if len(calls) > 0 {
+		end.FinishReason = "tool_calls"
+	}
+	s.queue = append(s.queue, end)
+}
+
+func (s *promptToolStream) Close() error {
+	return s.inner.Close()
+}
@@ -0,0 +1,107 @@
+package runtime
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// textLLM replies with fixed text in small deltas and records the request.
+type textLLM struct {
+	reply string
+	req   LLMRequest
+}
+
+func (l *textLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	l.req = req
+	var chunks []LLMChunk
+	for s := l.reply; s != ""; {
+		n := min(len(s), 7)
+		chunks = append(chunks, LLMChunk{Delta: s[:n]})
+		s = s[n:]
+	}
+	chunks = append(chunks, LLMChunk{FinishReason: "stop"})
+	return &chunkStream{chunks: chunks}, nil
+}
+
+type chunkStream struct{ chunks []LLMChunk }
+
+func (s *chunkStream) Recv(ctx context.Context) (LLMChunk, error) {
+	if len(s.chunks) == 0 {
+		return LLMChunk{}, io.EOF
+	}
+	ch := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return ch, nil
+}
+
+func (s *chunkStream) Close() error { return nil }
+
+func TestPromptToolLLM_ParsesToolCallsFromText(t *testing.T) {
+	inner := &textLLM{reply: "Checking the folder.\n```json\n{\"tool_calls\": [{\"name\": \"ls\", \"arguments\": {\"path\": \"src\"}}]}\n```"}
+	stream, err := NewPromptToolLLM(inner).Stream(context.Background(), LLMRequest{
+		Tools: []api.ToolSchema{lsSchema},
+		Messages: []api.LLMMessage{
+			{Role: "user", Content: "what is in src?"},
+			{Role: "assistant", ToolCalls: []api.LLMToolCall{{ID: "c1", Name: "ls", Args: `{"path":"."}`}}},
+			{Role: "tool", ToolCallID: "c1", Content: "src/"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	chunks, err := recvAll(t, stream)
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+
+	var text string
+	var calls []*api.LLMToolCall
+	for _, ch := range chunks {
+		text += ch.Delta
+		if ch.ToolCall != nil {
+			calls = append(calls, ch.ToolCall)
+		}
+	}
+	if strings.TrimSpace(text) != "Checking the folder." {
+		t.Fatalf("expected tool JSON to be removed from text, got %q", text)
+	}
+	if len(calls) != 1 || calls[0].Name != "ls" || calls[0].Args != `{"path": "src"}` {
+		t.Fatalf("unexpected tool calls: %+v", calls)
+	}
+	if last := chunks[len(chunks)-1]; last.FinishReason != "tool_calls" {
+		t.Fatalf("expected tool_calls finish, got %+v", last)
+	}
+
+	// Tools moved into the prompt; tool history became plain messages.
+	req := inner.req
+	if len(req.Tools) != 0 || req.Messages[0].Role != "system" || !strings.Contains(req.Messages[0].Content, "- ls: List files") {
+		t.Fatalf("expected tools in a system prompt, got %+v", req)
+	}
+	if req.Messages[2].Role != "assistant" || !strings.Contains(req.Messages[2].Content, `"tool_calls"`) ||
+		req.Messages[3].Role != "user" || !strings.HasPrefix(req.Messages[3].Content, "Tool result (ls)") {
+		t.Fatalf("unexpected flattened history: %+v", req.Messages)
+	}
+}
+
+func TestPromptToolLLM_PlainTextPassesThrough(t *testing.T) {
+	inner := &textLLM{reply: "Use a map {key: value} here."}
+	stream, _ := NewPromptToolLLM(inner).Stream(context.Background(), LLMRequest{Tools: []api.ToolSchema{lsSchema}})
+	chunks, err := recvAll(t, stream)
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	var text string
+	for _, ch := range chunks {
+		text += ch.Delta
+		if ch.ToolCall != nil {
+			t.Fatalf("unexpected tool call: %+v", ch.ToolCall)
+		}
+	}
+	if text != inner.reply || chunks[len(chunks)-1].FinishReason != "stop" {
+		t.Fatalf("expected text unchanged, got %q", text)
+	}
+}
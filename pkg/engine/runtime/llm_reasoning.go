@@ -0,0 +1,116 @@
+package runtime
+
+import (
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Reasoning
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Reasoning models stream their reasoning either in a separate field (reasoning_content,
+// Ollama's thinking) or inline between <think> tags; backends turn both into
+// LLMChunk.Reasoning.
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// thinkSplitter separates <think>...</think> blocks from streamed text. Tags may be
+// split across deltas, so a trailing partial tag is held until the next delta.
+type thinkSplitter struct {
+	inThink bool
+	pending string
+}
+
+// split returns the text and reasoning in delta.
+func (s *thinkSplitter) split(delta string) (text, reasoning string) {
+	buf := s.pending + delta
+	s.pending = ""
+	var t, r strings.Builder
+	for buf != "" {
+		tag, out := thinkOpen, &t
+		if s.inThink {
+			tag, out = thinkClose, &r
+		}
+		i := strings.Index(buf, tag)
+		if i < 0 {
+			keep := partialTagSuffix(buf, tag)
+			out.WriteString(buf[:len(buf)-keep])
+			s.pending = buf[len(buf)-keep:]
+			break
+		}
+		out.WriteString(buf[:i])
+		buf = buf[i+len(tag):]
+		if s.inThink {
+			// The answer usually starts after a blank line.
+			buf = strings.TrimLeft(buf, "\n")
+		}
+		s.inThink = !s.inThink
+	}
+	return t.String(), r.String()
+}
+
+// flush returns whatever is held back at the end of the stream.
+func (s *thinkSplitter) flush() (text, reasoning string) {
+	held := s.pending
+	s.pending = ""
+	if s.inThink {
+		return "", held
+	}
+	return held, ""
+}
+
+// partialTagSuffix returns the length of the longest suffix of s that is a proper
+// prefix of tag.
+func partialTagSuffix(s, tag string) int {
+	for n := len(tag) - 1; n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// replayReasoning keeps reasoning only where it must be sent back: assistant tool calls
+// of the current turn (after the last user message). Providers such as DeepSeek and Kimi
+// require it while a tool loop is running and reject or ignore it for earlier turns.
+// messages is not modified.
+func replayReasoning(messages []api.LLMMessage) []api.LLMMessage {
+	lastUser := -1
+	for i, m := range messages {
+		if m.Role == "user" {
+			lastUser = i
+		}
+	}
+	var out []api.LLMMessage
+	for i, m := range messages {
+		if m.Reasoning == "" || (i > lastUser && m.Role == "assistant" && len(m.ToolCalls) > 0) {
+			continue
+		}
+		if out == nil {
+			out = append([]api.LLMMessage(nil), messages...)
+		}
+		out[i].Reasoning = ""
+	}
+	if out == nil {
+		return messages
+	}
+	return out
+}
+
+// dropTurnReasoning clears reasoning from the current turn's messages once the turn is
+// done (unless TurnRunnerConfig.KeepReasoning is set). It reports whether any was set.
+func dropTurnReasoning(messages []api.LLMMessage) bool {
+	dropped := false
+	for i := len(messages) - 1; i >= 0 && messages[i].Role != "user"; i-- {
+		if messages[i].Reasoning != "" {
+			messages[i].Reasoning = ""
+			dropped = true
+		}
+	}
+	return dropped
+}
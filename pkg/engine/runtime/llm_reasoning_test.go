@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestThinkSplitter_SplitsTagsAcrossDeltas(t *testing.T) {
+	var s thinkSplitter
+	var text, reasoning string
+	for _, d := range []string{"<thi", "nk>plan it", "</th", "ink>\n\nThe answer <", "b>"} {
+		tx, r := s.split(d)
+		text += tx
+		reasoning += r
+	}
+	tx, r := s.flush()
+	text += tx
+	reasoning += r
+	if reasoning != "plan it" || text != "The answer <b>" {
+		t.Fatalf("unexpected split: text=%q reasoning=%q", text, reasoning)
+	}
+}
+
+func TestOpenAIStream_ParsesReasoningContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"reasoning_content\":\"Let me think.\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"42\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	stream, err := NewOpenAILLM(srv.URL, "k", "deepseek-reasoner").Stream(context.Background(), LLMRequest{})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	chunks, err := recvAll(t, stream)
+	if err != nil || len(chunks) < 2 || chunks[0].Reasoning != "Let me think." || chunks[1].Delta != "42" {
+		t.Fatalf("expected reasoning then text, got %+v", chunks)
+	}
+}
+
+func TestToOpenAIMessages_ReplaysReasoningOnlyForCurrentToolCalls(t *testing.T) {
+	call := []api.LLMToolCall{{ID: "c1", Name: "ls", Args: `{}`}}
+	msgs := toOpenAIMessages([]api.LLMMessage{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", Content: "old", Reasoning: "old thoughts"},
+		{Role: "user", Content: "second"},
+		{Role: "assistant", ToolCalls: call, Reasoning: "need a listing"},
+		{Role: "tool", ToolCallID: "c1", Content: "a.txt"},
+	})
+	if msgs[1].ReasoningContent != "" || msgs[3].ReasoningContent != "need a listing" {
+		b, _ := json.Marshal(msgs)
+		t.Fatalf("unexpected reasoning replay: %s", b)
+	}
+}
+
+// reasoningLLM thinks, calls ls once, then thinks and answers.
+type reasoningLLM struct{ requests []LLMRequest }
+
+func (l *reasoningLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	l.requests = append(l.requests, req)
+	if len(l.requests) == 1 {
+		return &chunkStream{chunks: []LLMChunk{
+			{Reasoning: "I should look first."},
+			{ToolCall: &api.LLMToolCall{ID: "c1", Name: "ls", Args: `{"path":"."}`}},
+			{FinishReason: "tool_calls"},
+		}}, nil
+	}
+	return &chunkStream{chunks: []LLMChunk{{Reasoning: "Empty dir."}, {Delta: "Nothing here."}, {FinishReason: "stop"}}}, nil
+}
+
+func TestTurnRunner_ReasoningKeptForToolLoopThenDropped(t *testing.T) {
+	llm := &reasoningLLM{}
+	sess, errPayload, _ := runLimitedTurn(t, llm, TurnRunnerConfig{})
+	if errPayload != nil {
+		t.Fatalf("unexpected error: %+v", errPayload)
+	}
+	if len(llm.requests) != 2 {
+		t.Fatalf("expected 2 LLM requests, got %d", len(llm.requests))
+	}
+	replayed := false
+	for _, m := range llm.requests[1].Messages {
+		replayed = replayed || m.Reasoning == "I should look first."
+	}
+	if !replayed {
+		t.Fatal("expected tool-call reasoning in the follow-up request")
+	}
+	for _, m := range sess.Messages {
+		if m.Reasoning != "" {
+			t.Fatalf("expected reasoning dropped after the turn, got %q", m.Reasoning)
+		}
+	}
+
+	sess, _, _ = runLimitedTurn(t, &reasoningLLM{}, TurnRunnerConfig{KeepReasoning: true})
+	if last := sess.Messages[len(sess.Messages)-1]; last.Reasoning != "Empty dir." {
+		t.Fatalf("expected reasoning kept with KeepReasoning, got %+v", last)
+	}
+}
@@ -0,0 +1,322 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"AgentEngine/pkg/logger"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Resilient LLM
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// LLMProvider is one entry in a ResilientLLM's failover order.
+type LLMProvider struct {
+	Name string // the model name: shown in notices, and usage after a failover is priced under it
+	LLM  LLM
+}
+
+// RetryPolicy configures ResilientLLM. Zero values use the defaults.
+type RetryPolicy struct {
+	MaxAttempts      int           // attempts per provider, including the first (default 3)
+	BaseDelay        time.Duration // first backoff, doubled on each retry (default 1s)
+	MaxDelay         time.Duration // backoff cap; a longer Retry-After fails over instead (default 30s)
+	BreakerThreshold int           // consecutive failures that open a provider's circuit (default 3)
+	BreakerCooldown  time.Duration // how long an open circuit skips the provider (default 1m)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = time.Second
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 30 * time.Second
+	}
+	if p.BreakerThreshold <= 0 {
+		p.BreakerThreshold = 3
+	}
+	if p.BreakerCooldown <= 0 {
+		p.BreakerCooldown = time.Minute
+	}
+	return p
+}
+
+// backoff returns the wait before retry number attempt (1-based): the server's Retry-After
+// when given, otherwise exponential backoff with jitter. ok is false when Retry-After
+// exceeds MaxDelay, meaning the provider should be given up on.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) (d time.Duration, ok bool) {
+	if retryAfter > 0 {
+		return retryAfter, retryAfter <= p.MaxDelay
+	}
+	d = p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	// Wait between half and all of d so concurrent clients do not retry in lockstep.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
+
+// ResilientLLM retries transient failures (429, 5xx, network errors, dropped streams) with
+// backoff and fails over to the next provider when one keeps failing. Each provider has a
+// circuit breaker so later requests skip it for a while. A stream is only retried before it
+// has produced output; a failure after that is returned as is.
+//
+// Retries and failovers are reported through the notice callback on the request context
+// (see withLLMNotice).
+type ResilientLLM struct {
+	providers []*llmProviderState
+	policy    RetryPolicy
+}
+
+type llmProviderState struct {
+	LLMProvider
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewResilientLLM wraps providers, tried in order.
+func NewResilientLLM(policy RetryPolicy, providers ...LLMProvider) *ResilientLLM {
+	l := &ResilientLLM{policy: policy.withDefaults()}
+	for _, p := range providers {
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("provider %d", len(l.providers)+1)
+		}
+		l.providers = append(l.providers, &llmProviderState{LLMProvider: p})
+	}
+	return l
+}
+
+func (l *ResilientLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	if len(l.providers) == 0 {
+		return nil, fmt.Errorf("no LLM providers configured")
+	}
+	a := &llmAttempt{policy: l.policy, req: req, order: l.order(time.Now())}
+	stream, err := a.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &resilientStream{attempt: a, cur: stream}, nil
+}
+
+// order lists providers with a closed circuit first; open ones stay at the end as a last resort.
+func (l *ResilientLLM) order(now time.Time) []*llmProviderState {
+	var closed, open []*llmProviderState
+	for _, p := range l.providers {
+		if p.isOpen(now) {
+			open = append(open, p)
+		} else {
+			closed = append(closed, p)
+		}
+	}
+	return append(closed, open...)
+}
+
+func (p *llmProviderState) isOpen(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return now.Before(p.openUntil)
+}
+
+// failure counts a failed attempt and opens the circuit at the threshold. After the cooldown
+// the provider gets one more try; another failure re-opens it straight away.
+func (p *llmProviderState) failure(policy RetryPolicy, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures++
+	if p.failures >= policy.BreakerThreshold {
+		p.openUntil = now.Add(policy.BreakerCooldown)
+		logger.Warn("LLM", "Circuit opened for provider", map[string]interface{}{
+			"provider": p.Name,
+			"failures": p.failures,
+			"cooldown": policy.BreakerCooldown.String(),
+		})
+	}
+}
+
+func (p *llmProviderState) success() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = 0
+	p.openUntil = time.Time{}
+}
+
+// llmAttempt tracks retries and failovers for one request.
+type llmAttempt struct {
+	policy RetryPolicy
+	req    LLMRequest
+	order  []*llmProviderState
+	idx    int // current provider
+	tries  int // failed attempts on the current provider
+}
+
+func (a *llmAttempt) provider() *llmProviderState { return a.order[a.idx] }
+
+// open starts a stream, retrying and failing over until one opens or nothing is left to try.
+func (a *llmAttempt) open(ctx context.Context) (LLMStream, error) {
+	for {
+		stream, err := a.provider().LLM.Stream(ctx, a.req)
+		if err == nil {
+			return stream, nil
+		}
+		if err := a.next(ctx, err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// next handles a failed attempt: it backs off before retrying the same provider or moves on
+// to the next one. It returns an error when the request should fail instead.
+func (a *llmAttempt) next(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	action := classifyLLMError(err)
+	if action == llmErrFatal {
+		return err
+	}
+	p := a.provider()
+	p.failure(a.policy, time.Now())
+	a.tries++
+
+	if action == llmErrRetry && a.tries < a.policy.MaxAttempts {
+		var retryAfter time.Duration
+		var se *LLMStatusError
+		if errors.As(err, &se) {
+			retryAfter = se.RetryAfter
+		}
+		if delay, ok := a.policy.backoff(a.tries, retryAfter); ok {
+			llmNotice(ctx, fmt.Sprintf("⏳ %s failed (%s); retrying in %s (attempt %d/%d)",
+				p.Name, llmErrSummary(err), delay.Round(time.Millisecond), a.tries+1, a.policy.MaxAttempts))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			return nil
+		}
+	}
+
+	if a.idx+1 >= len(a.order) {
+		return err
+	}
+	a.idx++
+	a.tries = 0
+	llmNotice(ctx, fmt.Sprintf("🔀 %s unavailable (%s); switching to %s", p.Name, llmErrSummary(err), a.provider().Name))
+	return nil
+}
+
+type llmErrAction int
+
+const (
+	llmErrRetry    llmErrAction = iota // transient: retry, then fail over
+	llmErrFailover                     // provider-specific (auth, unknown model): fail over at once
+	llmErrFatal                        // the request itself is bad: no other attempt will help
+)
+
+func classifyLLMError(err error) llmErrAction {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return llmErrFatal
+	}
+	var se *LLMStatusError
+	if !errors.As(err, &se) {
+		return llmErrRetry // network errors, dropped streams, in-stream API errors
+	}
+	switch {
+	case se.StatusCode == http.StatusTooManyRequests, se.StatusCode == http.StatusRequestTimeout, se.StatusCode >= 500:
+		return llmErrRetry
+	case se.StatusCode == http.StatusUnauthorized, se.StatusCode == http.StatusForbidden, se.StatusCode == http.StatusNotFound:
+		return llmErrFailover
+	}
+	return llmErrFatal
+}
+
+// llmErrSummary shortens err for notices; status errors carry the whole response body.
+func llmErrSummary(err error) string {
+	var se *LLMStatusError
+	if errors.As(err, &se) {
+		return fmt.Sprintf("status %d", se.StatusCode)
+	}
+	return truncateForLog(err.Error(), 120)
+}
+
+// resilientStream re-opens the request when the stream fails before producing output.
+type resilientStream struct {
+	attempt *llmAttempt
+	cur     LLMStream
+	started bool // output has been delivered; the stream can no longer be retried
+}
+
+func (s *resilientStream) Recv(ctx context.Context) (LLMChunk, error) {
+	for {
+		ch, err := s.cur.Recv(ctx)
+		if err == nil {
+			if ch.Delta != "" || ch.Reasoning != "" || ch.ToolArgDelta != "" || ch.ToolCall != nil {
+				s.started = true
+			}
+			if ch.FinishReason != "" {
+				s.attempt.provider().success()
+			}
+			return ch, nil
+		}
+		if err == io.EOF {
+			s.attempt.provider().success()
+			return ch, err
+		}
+		if s.started {
+			s.attempt.provider().failure(s.attempt.policy, time.Now())
+			return ch, err
+		}
+
+		s.cur.Close()
+		if nerr := s.attempt.next(ctx, err); nerr != nil {
+			return LLMChunk{}, nerr
+		}
+		stream, oerr := s.attempt.open(ctx)
+		if oerr != nil {
+			return LLMChunk{}, oerr
+		}
+		s.cur = stream
+	}
+}
+
+func (s *resilientStream) Close() error {
+	return s.cur.Close()
+}
+
+// ServedBy returns the name of the provider that produced the stream, which differs
+// from the first one after a failover.
+func (s *resilientStream) ServedBy() string {
+	return s.attempt.provider().Name
+}
+
+// servedStream is a stream that knows which provider served it (see ResilientLLM).
+type servedStream interface {
+	ServedBy() string
+}
+
+// llmNoticeKey carries a callback for retry/failover notices on the request context.
+type llmNoticeKey struct{}
+
+// withLLMNotice makes ResilientLLM report retries and failovers for requests made with ctx.
+func withLLMNotice(ctx context.Context, fn func(message string)) context.Context {
+	return context.WithValue(ctx, llmNoticeKey{}, fn)
+}
+
+func llmNotice(ctx context.Context, message string) {
+	logger.Warn("LLM", message)
+	if fn, ok := ctx.Value(llmNoticeKey{}).(func(string)); ok && fn != nil {
+		fn(message)
+	}
+}
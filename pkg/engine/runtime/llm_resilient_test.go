@@ -0,0 +1,208 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/policy"
+	"AgentEngine/pkg/engine/tools"
+)
+
+// flakyServer serves an SSE reply, letting fail decide how request n (1-based) misbehaves
+// first. fail returns false to serve the reply normally.
+func flakyServer(t *testing.T, reply string, fail func(n int, w http.ResponseWriter) bool) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail(int(atomic.AddInt32(&calls, 1)), w) {
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", reply)
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// dropConnection sends the SSE headers and then cuts the connection.
+func dropConnection(w http.ResponseWriter, partial string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprint(w, partial)
+	w.(http.Flusher).Flush()
+	panic(http.ErrAbortHandler)
+}
+
+func streamText(t *testing.T, llm LLM) (string, []string, error) {
+	t.Helper()
+	var notices []string
+	ctx := withLLMNotice(context.Background(), func(msg string) { notices = append(notices, msg) })
+	stream, err := llm.Stream(ctx, LLMRequest{Messages: []api.LLMMessage{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		return "", notices, err
+	}
+	defer stream.Close()
+	var text string
+	for {
+		ch, err := stream.Recv(ctx)
+		if err == io.EOF {
+			return text, notices, nil
+		}
+		if err != nil {
+			return text, notices, err
+		}
+		text += ch.Delta
+	}
+}
+
+var fastRetry = RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func TestResilientLLM_RetriesTransientErrors(t *testing.T) {
+	srv, calls := flakyServer(t, "ok", func(n int, w http.ResponseWriter) bool {
+		switch n {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			dropConnection(w, "data: {\"choi")
+		default:
+			return false
+		}
+		return true
+	})
+
+	policy := fastRetry
+	policy.MaxAttempts = 4
+	llm := NewResilientLLM(policy, LLMProvider{Name: "primary", LLM: NewOpenAILLM(srv.URL, "k", "m")})
+	text, notices, err := streamText(t, llm)
+	if err != nil || text != "ok" {
+		t.Fatalf("expected ok after retries, got %q err=%v", text, err)
+	}
+	if *calls != 4 || len(notices) != 3 || !strings.Contains(notices[0], "status 429") {
+		t.Fatalf("expected 4 calls and 3 retry notices, got %d %q", *calls, notices)
+	}
+}
+
+func TestResilientLLM_NoRetryAfterOutput(t *testing.T) {
+	srv, calls := flakyServer(t, "ok", func(n int, w http.ResponseWriter) bool {
+		dropConnection(w, "data: {\"choices\":[{\"delta\":{\"content\":\"par\"}}]}\n\n")
+		return true
+	})
+	llm := NewResilientLLM(fastRetry, LLMProvider{LLM: NewOpenAILLM(srv.URL, "k", "m")})
+	text, _, err := streamText(t, llm)
+	if err == nil || text != "par" || *calls != 1 {
+		t.Fatalf("expected a single failed attempt after output, got %q err=%v calls=%d", text, err, *calls)
+	}
+}
+
+func TestResilientLLM_BadRequestIsNotRetried(t *testing.T) {
+	srv, calls := flakyServer(t, "ok", func(n int, w http.ResponseWriter) bool {
+		w.WriteHeader(http.StatusBadRequest)
+		return true
+	})
+	llm := NewResilientLLM(fastRetry, LLMProvider{LLM: NewOpenAILLM(srv.URL, "k", "m")})
+	if _, _, err := streamText(t, llm); err == nil || *calls != 1 {
+		t.Fatalf("expected one failed call, got err=%v calls=%d", err, *calls)
+	}
+}
+
+func TestResilientLLM_FailsOverAndOpensCircuit(t *testing.T) {
+	primary, primaryCalls := flakyServer(t, "primary", func(n int, w http.ResponseWriter) bool {
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	})
+	fallback, _ := flakyServer(t, "fallback", func(int, http.ResponseWriter) bool { return false })
+
+	policy := fastRetry
+	policy.MaxAttempts = 2
+	policy.BreakerThreshold = 2
+	llm := NewResilientLLM(policy,
+		LLMProvider{Name: "primary", LLM: NewOpenAILLM(primary.URL, "k", "m")},
+		LLMProvider{Name: "fallback", LLM: NewOpenAILLM(fallback.URL, "k", "m")},
+	)
+
+	text, notices, err := streamText(t, llm)
+	if err != nil || text != "fallback" || *primaryCalls != 2 {
+		t.Fatalf("expected failover after 2 attempts, got %q err=%v primary calls=%d", text, err, *primaryCalls)
+	}
+	if last := notices[len(notices)-1]; !strings.Contains(last, "switching to fallback") {
+		t.Fatalf("expected failover notice, got %q", notices)
+	}
+
+	// The primary's circuit is open now, so the next request goes straight to the fallback.
+	text, notices, err = streamText(t, llm)
+	if err != nil || text != "fallback" || *primaryCalls != 2 || len(notices) != 0 {
+		t.Fatalf("expected open circuit to skip primary, got %q err=%v primary calls=%d notices=%q", text, err, *primaryCalls, notices)
+	}
+}
+
+func TestRetryPolicy_HonorsRetryAfter(t *testing.T) {
+	p := RetryPolicy{}.withDefaults()
+	if d, ok := p.backoff(1, 5*time.Second); d != 5*time.Second || !ok {
+		t.Fatalf("expected Retry-After delay, got %v %v", d, ok)
+	}
+	if _, ok := p.backoff(1, time.Hour); ok {
+		t.Fatal("expected a Retry-After beyond MaxDelay to give up on the provider")
+	}
+	if d, _ := p.backoff(3, 0); d < 2*time.Second || d > 4*time.Second {
+		t.Fatalf("expected jittered 2s-4s backoff, got %v", d)
+	}
+	if got := parseRetryAfter("7", time.Now()); got != 7*time.Second {
+		t.Fatalf("expected 7s, got %v", got)
+	}
+}
+
+// downLLM fails every request with a server error.
+type downLLM struct{}
+
+func (downLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	return nil, &LLMStatusError{StatusCode: http.StatusInternalServerError}
+}
+
+func TestResilientLLM_UsageRecordedUnderServingModel(t *testing.T) {
+	llm := NewResilientLLM(RetryPolicy{MaxAttempts: 1},
+		LLMProvider{Name: "primary-model", LLM: downLLM{}},
+		LLMProvider{Name: "fallback-model", LLM: staticLLM{out: "hi"}},
+	)
+	eng, err := NewEngine(EngineConfig{
+		LLM:           llm,
+		Model:         "primary-model",
+		Tools:         tools.NewRegistry(),
+		Policy:        policy.NewDefaultPolicy(),
+		WorkspaceRoot: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	ctx := context.Background()
+	sid, err := eng.StartSession(ctx, api.StartOptions{})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	stream, err := eng.Send(ctx, sid, "hello")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	collectEvents(t, stream)
+
+	records, err := eng.UsageRecords(ctx, sid)
+	if err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	calls := map[string]int{}
+	for _, rec := range records {
+		calls[rec.Model] += rec.Usage.LLMCalls
+	}
+	if calls["fallback-model"] != 1 || calls["primary-model"] != 0 {
+		t.Fatalf("expected the call recorded under the fallback model, got %+v", records)
+	}
+}
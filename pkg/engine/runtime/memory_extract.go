@@ -0,0 +1,129 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/prompts"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Memory Extraction
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// LLMMemoryExtractor asks the LLM to propose memories from a turn transcript.
+// It implements middleware.MemoryExtractor.
+type LLMMemoryExtractor struct {
+	llm LLM
+}
+
+// NewLLMMemoryExtractor creates an extractor backed by the given LLM.
+func NewLLMMemoryExtractor(llm LLM) *LLMMemoryExtractor {
+	return &LLMMemoryExtractor{llm: llm}
+}
+
+// ExtractMemories returns candidate memories (without IDs) proposed for the transcript.
+func (x *LLMMemoryExtractor) ExtractMemories(ctx context.Context, transcript []api.LLMMessage, existing []api.MemoryEntry) ([]api.MemoryCandidate, error) {
+	if x == nil || x.llm == nil || len(transcript) == 0 {
+		return nil, nil
+	}
+
+	var sb strings.Builder
+	promptTemplate := prompts.DefaultLoader.Get(prompts.MemoryExtract)
+	if promptTemplate == "" {
+		promptTemplate = `Propose durable memories from this turn as JSON: {"memories":[{"type","content","source","tags"}]}`
+	}
+	sb.WriteString(promptTemplate)
+	sb.WriteString("\n\n## Existing Memories\n")
+	if len(existing) == 0 {
+		sb.WriteString("(none)\n")
+	}
+	for _, e := range existing {
+		sb.WriteString(fmt.Sprintf("- [%s] %s\n", e.Type, truncateContent(e.Content, 200)))
+	}
+
+	sb.WriteString("\n## Turn\n")
+	for _, m := range transcript {
+		switch m.Role {
+		case "user":
+			sb.WriteString(fmt.Sprintf("**User**: %s\n\n", truncateContent(m.Content, 1000)))
+		case "assistant":
+			if m.Content != "" {
+				sb.WriteString(fmt.Sprintf("**Assistant**: %s\n\n", truncateContent(m.Content, 1000)))
+			}
+			if len(m.ToolCalls) > 0 {
+				var tools []string
+				for _, tc := range m.ToolCalls {
+					tools = append(tools, tc.Name)
+				}
+				sb.WriteString(fmt.Sprintf("_[Used tools: %s]_\n", strings.Join(tools, ", ")))
+			}
+		case "tool":
+			if m.Content != "" {
+				sb.WriteString(fmt.Sprintf("_Tool result: %s_\n", truncateContent(m.Content, 200)))
+			}
+		}
+	}
+
+	stream, err := x.llm.Stream(ctx, LLMRequest{
+		Messages:  []api.LLMMessage{{Role: "user", Content: sb.String()}},
+		MaxTokens: 600,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var result strings.Builder
+	for {
+		chunk, err := stream.Recv(ctx)
+		if err != nil {
+			break // EOF or error
+		}
+		if chunk.Delta != "" {
+			result.WriteString(chunk.Delta)
+		}
+	}
+
+	return parseMemoryCandidates(result.String())
+}
+
+// parseMemoryCandidates decodes the extractor's JSON reply, tolerating code fences
+// and surrounding prose.
+func parseMemoryCandidates(raw string) ([]api.MemoryCandidate, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("memory extraction: no JSON object in response")
+	}
+
+	var out struct {
+		Memories []struct {
+			Type    string   `json:"type"`
+			Content string   `json:"content"`
+			Source  string   `json:"source"`
+			Tags    []string `json:"tags"`
+		} `json:"memories"`
+	}
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &out); err != nil {
+		return nil, fmt.Errorf("memory extraction: %v", err)
+	}
+
+	candidates := make([]api.MemoryCandidate, 0, len(out.Memories))
+	for _, m := range out.Memories {
+		candidates = append(candidates, api.MemoryCandidate{
+			Type:    api.MemoryType(strings.ToLower(strings.TrimSpace(m.Type))),
+			Content: strings.TrimSpace(m.Content),
+			Source:  api.MemorySource(strings.ToLower(strings.TrimSpace(m.Source))),
+			Tags:    m.Tags,
+		})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,229 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"AgentEngine/pkg/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Model Roles
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Model roles. Roles without a configured model use the main model.
+const (
+	RoleMain            = "main"             // agent turns
+	RoleSummarizer      = "summarizer"       // history compression
+	RoleMemoryExtractor = "memory-extractor" // memory proposals after a turn
+)
+
+var modelRoles = map[string]bool{
+	RoleMain: true, RoleSummarizer: true, RoleMemoryExtractor: true,
+}
+
+// Provider types: the wire protocol a provider speaks.
+const (
+	ProviderOpenAI   = "openai"   // OpenAI-compatible /chat/completions (default)
+	ProviderOllama   = "ollama"   // Ollama native /api/chat
+	ProviderLlamaCpp = "llamacpp" // llama.cpp server /completion
+)
+
+// ProviderSpec is an endpoint in the models config.
+type ProviderSpec struct {
+	Type      string `yaml:"type"` // openai (default), ollama or llamacpp
+	BaseURL   string `yaml:"base_url"`
+	APIKeyEnv string `yaml:"api_key_env"` // environment variable holding the key (default: LLM_API_KEY)
+	// PromptTools describes tools in the prompt and parses JSON tool calls from the reply,
+	// for models without native tool calling (see PromptToolLLM).
+	PromptTools bool `yaml:"prompt_tools"`
+}
+
+// ModelSpec configures the model for one role. Zero values keep the client defaults.
+type ModelSpec struct {
+	Provider    string   `yaml:"provider"` // key in ModelsConfig.Providers ("" = LLM_BASE_URL / LLM_API_KEY)
+	Model       string   `yaml:"model"`
+	Temperature *float64 `yaml:"temperature"`
+	TopP        *float64 `yaml:"top_p"`
+	MaxTokens   int      `yaml:"max_tokens"`
+}
+
+// ModelsConfig is the models config file: named providers and a model per role.
+//
+//	providers:
+//	  deepseek: {base_url: https://api.deepseek.com/v1, api_key_env: DEEPSEEK_API_KEY}
+//	roles:
+//	  main: {model: gpt-4o, temperature: 0.2}
+//	  summarizer: {provider: deepseek, model: deepseek-chat, max_tokens: 1000}
+type ModelsConfig struct {
+	Providers map[string]ProviderSpec `yaml:"providers"`
+	Roles     map[string]ModelSpec    `yaml:"roles"`
+}
+
+// LoadModelsConfig reads a models config file (YAML or JSON).
+func LoadModelsConfig(path string) (*ModelsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg ModelsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid models config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid models config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (c *ModelsConfig) validate() error {
+	for name, p := range c.Providers {
+		switch p.Type {
+		case "", ProviderOpenAI, ProviderOllama, ProviderLlamaCpp:
+		default:
+			return fmt.Errorf("provider %q: unknown type %q (want openai, ollama or llamacpp)", name, p.Type)
+		}
+	}
+	roles := make([]string, 0, len(c.Roles))
+	for role := range c.Roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		spec := c.Roles[role]
+		if !modelRoles[role] {
+			return fmt.Errorf("unknown role %q (want main, summarizer or memory-extractor)", role)
+		}
+		if strings.TrimSpace(spec.Model) == "" {
+			return fmt.Errorf("role %q: model is required", role)
+		}
+		if spec.Provider != "" {
+			if _, ok := c.Providers[spec.Provider]; !ok {
+				return fmt.Errorf("role %q: unknown provider %q", role, spec.Provider)
+			}
+		}
+	}
+	return nil
+}
+
+// RoleModel is a ready-to-use model.
+type RoleModel struct {
+	LLM   LLM
+	Model string // model name, for pricing and usage records
+}
+
+// ModelSet resolves the model for a role or for a skill's frontmatter "model".
+type ModelSet struct {
+	roles map[string]RoleModel
+	// build creates a model a skill asks for by name; nil disables such requests.
+	build func(model string) LLM
+
+	mu    sync.Mutex
+	built map[string]RoleModel
+}
+
+// NewModelSet creates a set from configured roles. build may be nil.
+func NewModelSet(roles map[string]RoleModel, build func(model string) LLM) *ModelSet {
+	return &ModelSet{roles: roles, build: build, built: make(map[string]RoleModel)}
+}
+
+// Role returns the model configured for role. ok is false when the role has none,
+// in which case callers use the main model.
+func (s *ModelSet) Role(role string) (RoleModel, bool) {
+	if s == nil {
+		return RoleModel{}, false
+	}
+	m, ok := s.roles[role]
+	return m, ok
+}
+
+// ForSkill resolves a skill's "model" request: a role name, or else a model name built
+// with the main model's provider settings.
+func (s *ModelSet) ForSkill(want string) (RoleModel, bool) {
+	if s == nil || want == "" {
+		return RoleModel{}, false
+	}
+	if modelRoles[want] {
+		return s.Role(want)
+	}
+	if s.build == nil {
+		return RoleModel{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.built[want]; ok {
+		return m, true
+	}
+	logger.Info("Models", "Building model requested by skill", map[string]interface{}{
+		"model": want,
+	})
+	m := RoleModel{LLM: s.build(want), Model: want}
+	s.built[want] = m
+	return m, true
+}
+
+// Known reports whether name is a configured role or the model of one, or a model
+// a skill has already asked for. ForSkill builds any name; this tells a typo apart.
+func (s *ModelSet) Known(name string) bool {
+	if s == nil || name == "" {
+		return false
+	}
+	if _, ok := s.roles[name]; ok {
+		return true
+	}
+	for _, m := range s.roles {
+		if m.Model == name {
+			return true
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.built[name]
+	return ok
+}
+
+// roleLLM returns the LLM and model name for role, falling back to the main model.
+func (r *TurnRunner) roleLLM(role string) (LLM, string) {
+	if m, ok := r.cfg.Models.Role(role); ok {
+		return m.LLM, m.Model
+	}
+	return r.cfg.LLM, r.cfg.Model
+}
+
+// turnLLM picks the model for the next agent request: the model chosen for the session
+// (see Engine.UpdateSession), else the active skill's frontmatter "model" when it
+// resolves, otherwise the main model.
+func (r *TurnRunner) turnLLM() (LLM, string) {
+	if r.session != nil && r.session.Metadata["model"] != "" {
+		if m, ok := r.cfg.Models.ForSkill(r.session.Metadata["model"]); ok {
+			return m.LLM, m.Model
+		}
+	}
+	if r.session != nil && r.session.ActiveSkill != "" && r.cfg.SkillIndex != nil {
+		for _, meta := range r.cfg.SkillIndex.List() {
+			if meta.Name != r.session.ActiveSkill {
+				continue
+			}
+			if m, ok := r.cfg.Models.ForSkill(meta.Model); ok {
+				return m.LLM, m.Model
+			}
+			break
+		}
+	}
+	return r.roleLLM(RoleMain)
+}
+
+// ModelFor returns the LLM and model name configured for role, falling back to the main
+// model.
+func (e *Engine) ModelFor(role string) (LLM, string) {
+	if m, ok := e.cfg.Models.Role(role); ok {
+		return m.LLM, m.Model
+	}
+	return e.cfg.LLM, e.cfg.Model
+}
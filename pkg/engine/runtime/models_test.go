@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// metaSkillIndex lists fixed skill metadata.
+type metaSkillIndex []api.SkillMeta
+
+func (s metaSkillIndex) List() []api.SkillMeta { return s }
+func (s metaSkillIndex) Load(name string) (*api.Skill, error) {
+	return nil, os.ErrNotExist
+}
+
+func TestLoadModelsConfig_Validates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "models.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return path
+	}
+
+	cfg, err := LoadModelsConfig(write(`
+providers:
+  local: {base_url: "http://localhost:11434/v1"}
+roles:
+  main: {model: gpt-4o, temperature: 0}
+  summarizer: {provider: local, model: qwen2, max_tokens: 800}
+`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	main := cfg.Roles[RoleMain]
+	if main.Temperature == nil || *main.Temperature != 0 || cfg.Roles[RoleSummarizer].MaxTokens != 800 {
+		t.Fatalf("unexpected config: %+v", cfg.Roles)
+	}
+
+	for content, want := range map[string]string{
+		"roles:\n  planner: {model: x}\n":                  "unknown role",
+		"roles:\n  summarizer: {provider: nope, model: x}": "unknown provider",
+		"roles:\n  summarizer: {temperature: 1}\n":         "model is required",
+	} {
+		if _, err := LoadModelsConfig(write(content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q error, got %v", want, err)
+		}
+	}
+}
+
+func TestTurnRunner_PicksModelForRoleAndSkill(t *testing.T) {
+	built := 0
+	models := NewModelSet(map[string]RoleModel{
+		RoleSummarizer: {LLM: &MockLLM{}, Model: "small"},
+	}, func(model string) LLM {
+		built++
+		return &MockLLM{}
+	})
+	r := NewTurnRunner(TurnRunnerConfig{
+		LLM:    &MockLLM{},
+		Model:  "big",
+		Models: models,
+		SkillIndex: metaSkillIndex{
+			{Name: "notes", Model: RoleSummarizer},
+			{Name: "code", Model: "code-model"},
+			{Name: "plain"},
+		},
+	})
+	r.session = &api.Session{SessionID: "s1"}
+
+	for skill, want := range map[string]string{"": "big", "plain": "big", "notes": "small", "code": "code-model"} {
+		r.session.ActiveSkill = skill
+		if _, got := r.turnLLM(); got != want {
+			t.Fatalf("skill %q: expected model %q, got %q", skill, want, got)
+		}
+	}
+	r.session.ActiveSkill = "code"
+	r.turnLLM()
+	if built != 1 {
+		t.Fatalf("expected skill model to be built once, got %d", built)
+	}
+	if _, got := r.roleLLM(RoleMemoryExtractor); got != "big" {
+		t.Fatalf("expected unconfigured role to use main model, got %q", got)
+	}
+}
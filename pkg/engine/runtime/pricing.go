@@ -0,0 +1,92 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Pricing
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// ModelPrice is the USD price per million tokens.
+type ModelPrice struct {
+	InputPerMTok       float64 `json:"input_per_mtok"`
+	OutputPerMTok      float64 `json:"output_per_mtok"`
+	CachedInputPerMTok float64 `json:"cached_input_per_mtok,omitempty"` // 0 = same as input
+}
+
+// PriceTable maps model names (or name prefixes) to prices.
+type PriceTable map[string]ModelPrice
+
+// DefaultPriceTable returns list prices for common models. Unknown models cost 0.
+func DefaultPriceTable() PriceTable {
+	return PriceTable{
+		"gpt-4o":            {InputPerMTok: 2.50, OutputPerMTok: 10.00, CachedInputPerMTok: 1.25},
+		"gpt-4o-mini":       {InputPerMTok: 0.15, OutputPerMTok: 0.60, CachedInputPerMTok: 0.075},
+		"gpt-4.1":           {InputPerMTok: 2.00, OutputPerMTok: 8.00, CachedInputPerMTok: 0.50},
+		"gpt-4.1-mini":      {InputPerMTok: 0.40, OutputPerMTok: 1.60, CachedInputPerMTok: 0.10},
+		"gpt-4.1-nano":      {InputPerMTok: 0.10, OutputPerMTok: 0.40, CachedInputPerMTok: 0.025},
+		"gpt-4-turbo":       {InputPerMTok: 10.00, OutputPerMTok: 30.00},
+		"o3-mini":           {InputPerMTok: 1.10, OutputPerMTok: 4.40},
+		"claude-3-5-sonnet": {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-3-5-haiku":  {InputPerMTok: 0.80, OutputPerMTok: 4.00},
+		"claude-3-opus":     {InputPerMTok: 15.00, OutputPerMTok: 75.00},
+		"deepseek-chat":     {InputPerMTok: 0.27, OutputPerMTok: 1.10, CachedInputPerMTok: 0.07},
+		"deepseek-reasoner": {InputPerMTok: 0.55, OutputPerMTok: 2.19},
+	}
+}
+
+// Lookup finds the price for model: exact match first, then the longest matching prefix
+// (so "gpt-4o-2024-08-06" uses "gpt-4o").
+func (t PriceTable) Lookup(model string) (ModelPrice, bool) {
+	if p, ok := t[model]; ok {
+		return p, true
+	}
+	best := ""
+	for name := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return t[best], true
+}
+
+// Cost estimates the USD cost of the tokens in u. Cached prompt tokens use the cached rate.
+func (t PriceTable) Cost(model string, u api.Usage) float64 {
+	p, ok := t.Lookup(model)
+	if !ok {
+		return 0
+	}
+	cachedRate := p.CachedInputPerMTok
+	if cachedRate == 0 {
+		cachedRate = p.InputPerMTok
+	}
+	uncached := u.PromptTokens - u.CachedTokens
+	return (float64(uncached)*p.InputPerMTok + float64(u.CachedTokens)*cachedRate + float64(u.CompletionTokens)*p.OutputPerMTok) / 1e6
+}
+
+// LoadPriceTable reads a JSON price table ({"model": {"input_per_mtok": 2.5, ...}})
+// and merges it over DefaultPriceTable.
+func LoadPriceTable(path string) (PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var custom PriceTable
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("invalid price table %s: %w", path, err)
+	}
+	t := DefaultPriceTable()
+	for model, p := range custom {
+		t[model] = p
+	}
+	return t, nil
+}
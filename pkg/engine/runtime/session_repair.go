@@ -0,0 +1,177 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/store"
+	"AgentEngine/pkg/logger"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Session Recovery
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// A process that dies mid-turn leaves an assistant message whose tool calls have no
+// results, which chat APIs reject. Sessions are repaired when a turn loads them, and
+// `sea sessions doctor` checks all of them.
+
+// interruptedToolResult is recorded for tool calls whose process died before a result.
+const interruptedToolResult = "interrupted (the process stopped before the tool call finished)"
+
+// repairToolResults gives every tool call a result, right after the results it has,
+// and returns how many it added. With keepLast, the last assistant message's calls are
+// left open (they wait on a pending approval).
+func repairToolResults(messages []api.LLMMessage, keepLast bool) ([]api.LLMMessage, int) {
+	added := 0
+	out := make([]api.LLMMessage, 0, len(messages))
+	var open []api.LLMToolCall
+	answered := make(map[string]bool)
+	closeOpen := func() {
+		for _, tc := range open {
+			if !answered[tc.ID] {
+				out = append(out, api.LLMMessage{Role: "tool", Content: "Skipped: " + interruptedToolResult, ToolCallID: tc.ID})
+				added++
+			}
+		}
+		open = nil
+		answered = make(map[string]bool)
+	}
+	for _, m := range messages {
+		if m.Role == "tool" {
+			answered[m.ToolCallID] = true
+			out = append(out, m)
+			continue
+		}
+		closeOpen()
+		out = append(out, m)
+		if m.Role == "assistant" {
+			open = m.ToolCalls
+		}
+	}
+	if !keepLast {
+		closeOpen()
+	}
+	return out, added
+}
+
+// repairSession fixes the session's tool results in place and saves it when needed.
+func (e *Engine) repairSession(ctx context.Context, session *api.Session) error {
+	messages, added := repairToolResults(session.Messages, session.Pending != nil)
+	if added == 0 {
+		return nil
+	}
+	session.Messages = messages
+	session.UpdatedAt = time.Now()
+	logger.Warn("Engine", "Repaired interrupted tool calls", map[string]interface{}{
+		"session_id":  session.SessionID,
+		"interrupted": added,
+	})
+	return e.sessionStore.Put(ctx, session.SessionID, session)
+}
+
+// lockSession leases the session to this process when the store is shared between
+// processes; the lease is nil otherwise.
+func (e *Engine) lockSession(sessionID string) (store.Lease, error) {
+	locker, ok := e.sessionStore.(store.SessionLocker)
+	if !ok {
+		return nil, nil
+	}
+	lease, err := locker.Lock(sessionID)
+	if err != nil {
+		if errors.Is(err, store.ErrSessionLocked) {
+			return nil, fmt.Errorf("%s: %v", api.ErrTurnInProgress, err)
+		}
+		return nil, err
+	}
+	return lease, nil
+}
+
+func releaseLease(lease store.Lease) {
+	if lease == nil {
+		return
+	}
+	if err := lease.Release(); err != nil {
+		logger.Warn("Engine", "Failed to release session lock", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// SessionDiagnosis reports what DoctorSessions found in one session.
+type SessionDiagnosis struct {
+	SessionID string   `json:"session_id"`
+	Problems  []string `json:"problems"`
+	Fixed     bool     `json:"fixed"`
+}
+
+// DoctorSessions checks every stored session for unreadable data, tool calls without
+// results and stale locks. With fix, repairable sessions are repaired; sessions locked
+// by a live process are skipped. Only sessions with problems are returned.
+func (e *Engine) DoctorSessions(ctx context.Context, fix bool) ([]SessionDiagnosis, error) {
+	ids, err := e.sessionStore.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locker, _ := e.sessionStore.(store.SessionLocker)
+
+	var out []SessionDiagnosis
+	for _, id := range ids {
+		diag := SessionDiagnosis{SessionID: id}
+		if locker != nil {
+			holder, stale, err := locker.InspectLock(id)
+			switch {
+			case err != nil:
+				diag.Problems = append(diag.Problems, fmt.Sprintf("lock unreadable: %v", err))
+			case holder != nil && !stale:
+				// In use; its turn repairs the session if needed.
+				continue
+			case holder != nil:
+				diag.Problems = append(diag.Problems, fmt.Sprintf("stale lock held by %s", holder))
+			}
+		}
+
+		session, err := e.sessionStore.Get(ctx, id)
+		if err != nil {
+			diag.Problems = append(diag.Problems, fmt.Sprintf("unreadable: %v", err))
+			out = append(out, diag)
+			continue
+		}
+		if _, added := repairToolResults(session.Messages, session.Pending != nil); added > 0 {
+			diag.Problems = append(diag.Problems, fmt.Sprintf("%d tool call(s) without results", added))
+		}
+		if len(diag.Problems) == 0 {
+			continue
+		}
+		if fix {
+			if err := e.fixSession(ctx, id); err != nil {
+				diag.Problems = append(diag.Problems, fmt.Sprintf("fix failed: %v", err))
+			} else {
+				diag.Fixed = true
+			}
+		}
+		out = append(out, diag)
+	}
+	return out, nil
+}
+
+// fixSession takes the session's lock (breaking a stale one) and repairs it.
+func (e *Engine) fixSession(ctx context.Context, sessionID string) error {
+	e.turnsMu.Lock()
+	defer e.turnsMu.Unlock()
+	if _, exists := e.activeTurns[sessionID]; exists {
+		return fmt.Errorf("%s: %s", api.ErrTurnInProgress, sessionID)
+	}
+	lease, err := e.lockSession(sessionID)
+	if err != nil {
+		return err
+	}
+	defer releaseLease(lease)
+
+	session, err := e.sessionStore.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	return e.repairSession(ctx, session)
+}
@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestRepairToolResults_ClosesInterruptedCalls(t *testing.T) {
+	calls := []api.LLMToolCall{{ID: "c1", Name: "ls"}, {ID: "c2", Name: "ls"}}
+	msgs := []api.LLMMessage{
+		{Role: "user", Content: "go"},
+		{Role: "assistant", ToolCalls: calls},
+		{Role: "tool", ToolCallID: "c1", Content: "a.txt"},
+		{Role: "user", Content: "again"},
+		{Role: "assistant", ToolCalls: calls[:1]},
+	}
+
+	out, added := repairToolResults(msgs, false)
+	if added != 2 || len(out) != 7 {
+		t.Fatalf("expected 2 results added, got %d: %+v", added, out)
+	}
+	if out[3].Role != "tool" || out[3].ToolCallID != "c2" || !strings.Contains(out[3].Content, "interrupted") {
+		t.Fatalf("expected c2 closed right after c1's result, got %+v", out[3])
+	}
+	if last := out[6]; last.Role != "tool" || last.ToolCallID != "c1" {
+		t.Fatalf("expected the last call closed, got %+v", last)
+	}
+
+	// Calls waiting on a pending approval stay open.
+	if _, added := repairToolResults(msgs, true); added != 1 {
+		t.Fatalf("expected only the earlier call closed, got %d", added)
+	}
+}
+
+func TestEngine_RepairsCrashedSessionAndDoctorFixesStaleLock(t *testing.T) {
+	llm := &controlLLM{}
+	eng, sid := newControlEngine(t, llm)
+	ctx := context.Background()
+
+	// Simulate a crash mid-tool-call that also left its lock behind.
+	sess, _ := eng.sessionStore.Get(ctx, sid)
+	sess.Messages = append(sess.Messages,
+		api.LLMMessage{Role: "user", Content: "list"},
+		api.LLMMessage{Role: "assistant", ToolCalls: []api.LLMToolCall{{ID: "c1", Name: "ls", Args: `{}`}}},
+	)
+	if err := eng.sessionStore.Put(ctx, sid, sess); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	lockPath := filepath.Join(eng.cfg.WorkspaceRoot, "sessions", sid+".json.lock")
+	if err := os.WriteFile(lockPath, []byte(`{"pid":999999999,"host":"`+hostname()+`","token":"x"}`), 0644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	diags, err := eng.DoctorSessions(ctx, false)
+	if err != nil || len(diags) != 1 || len(diags[0].Problems) != 2 || diags[0].Fixed {
+		t.Fatalf("expected stale lock and open tool call reported, got %+v (%v)", diags, err)
+	}
+	if diags, _ := eng.DoctorSessions(ctx, true); len(diags) != 1 || !diags[0].Fixed {
+		t.Fatalf("expected the session fixed, got %+v", diags)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("expected stale lock removed, got %v", err)
+	}
+	if diags, _ := eng.DoctorSessions(ctx, false); len(diags) != 0 {
+		t.Fatalf("expected a healthy session after fixing, got %+v", diags)
+	}
+
+	sess, _ = eng.sessionStore.Get(ctx, sid)
+	if last := sess.Messages[len(sess.Messages)-1]; last.Role != "tool" || last.ToolCallID != "c1" {
+		t.Fatalf("expected the interrupted call answered, got %+v", last)
+	}
+	stream, err := eng.Send(ctx, sid, "continue")
+	if err != nil {
+		t.Fatalf("expected the repaired session to accept a turn: %v", err)
+	}
+	collectEvents(t, stream)
+}
+
+func TestEngine_SendFailsWhileAnotherProcessHoldsTheSession(t *testing.T) {
+	eng, sid := newControlEngine(t, &controlLLM{})
+	lockPath := filepath.Join(eng.cfg.WorkspaceRoot, "sessions", sid+".json.lock")
+	// PID 1 is always alive.
+	if err := os.WriteFile(lockPath, []byte(`{"pid":1,"host":"`+hostname()+`","token":"x"}`), 0644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	if _, err := eng.Send(context.Background(), sid, "hi"); err == nil || !strings.Contains(err.Error(), api.ErrTurnInProgress) {
+		t.Fatalf("expected the locked session refused, got %v", err)
+	}
+}
+
+func hostname() string {
+	h, _ := os.Hostname()
+	return h
+}
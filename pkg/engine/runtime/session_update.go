@@ -0,0 +1,242 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/store"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Session Settings
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// UpdateSession changes a session's approval mode, skill or model between turns, so
+// frontends can switch them without starting a new session. It fails with
+// turn_in_progress while a turn runs.
+func (e *Engine) UpdateSession(ctx context.Context, sessionID string, u api.SessionUpdate) (api.SessionInfo, error) {
+	e.turnsMu.Lock()
+	if _, exists := e.activeTurns[sessionID]; exists {
+		e.turnsMu.Unlock()
+		return api.SessionInfo{}, fmt.Errorf("%s: %s", api.ErrTurnInProgress, sessionID)
+	}
+	lease, err := e.lockSession(sessionID)
+	e.turnsMu.Unlock()
+	if err != nil {
+		return api.SessionInfo{}, err
+	}
+	defer releaseLease(lease)
+
+	session, err := e.sessionStore.Get(ctx, sessionID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return api.SessionInfo{}, fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
+		}
+		return api.SessionInfo{}, err
+	}
+	if session.Metadata == nil {
+		session.Metadata = make(map[string]string)
+	}
+
+	if u.ApprovalMode != nil {
+		switch *u.ApprovalMode {
+		case api.ModeSuggest, api.ModeAuto, api.ModeFullAuto:
+			session.Metadata["approval_mode"] = string(*u.ApprovalMode)
+		default:
+			return api.SessionInfo{}, fmt.Errorf("unknown approval mode %q (want suggest, auto or full-auto)", *u.ApprovalMode)
+		}
+	}
+
+	if u.ActiveSkill != nil {
+		name := *u.ActiveSkill
+		if name != "" && !e.hasSkill(name) {
+			return api.SessionInfo{}, fmt.Errorf("unknown skill %q", name)
+		}
+		session.ActiveSkill = name
+		session.Metadata["skill_last_reason"] = "session_update"
+		if name != "" {
+			session.Metadata["skill_locked"] = "true"
+			session.Metadata["skill_source"] = "user"
+		} else {
+			session.Metadata["skill_locked"] = "false"
+			session.Metadata["skill_source"] = "none"
+		}
+	}
+	if u.AutoSkill != nil {
+		if *u.AutoSkill {
+			delete(session.Metadata, "auto_skill")
+			session.Metadata["skill_locked"] = "false"
+		} else {
+			session.Metadata["auto_skill"] = "off"
+		}
+	}
+
+	if u.Model != nil {
+		switch model := strings.TrimSpace(*u.Model); model {
+		case "", RoleMain:
+			delete(session.Metadata, "model")
+		default:
+			if !e.knownModel(model) {
+				return api.SessionInfo{}, fmt.Errorf("no model configured for %q", model)
+			}
+			session.Metadata["model"] = model
+		}
+	}
+
+	session.UpdatedAt = time.Now()
+	if err := e.sessionStore.Put(ctx, sessionID, session); err != nil {
+		return api.SessionInfo{}, fmt.Errorf("failed to save session: %w", err)
+	}
+	return sessionInfo(session), nil
+}
+
+func (e *Engine) hasSkill(name string) bool {
+	if e.cfg.SkillIndex == nil {
+		return false
+	}
+	for _, meta := range e.cfg.SkillIndex.List() {
+		if meta.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// knownModel reports whether a session may switch to model: a configured role, the
+// main model or a role's model, or a model a skill asks for in its frontmatter.
+func (e *Engine) knownModel(model string) bool {
+	if model == e.cfg.Model || e.cfg.Models.Known(model) {
+		return true
+	}
+	if e.cfg.SkillIndex != nil {
+		for _, meta := range e.cfg.SkillIndex.List() {
+			if meta.Model == model {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ModelRoles returns the model name used for each role, including the main model.
+func (e *Engine) ModelRoles() map[string]string {
+	out := make(map[string]string, len(modelRoles))
+	for role := range modelRoles {
+		_, out[role] = e.ModelFor(role)
+	}
+	return out
+}
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Session Views
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// SessionPlan returns the session's plan, or nil when it has none.
+func (e *Engine) SessionPlan(ctx context.Context, sessionID string) (*api.PlanPayload, error) {
+	p, err := e.planStore.Get(ctx, "plan_"+sessionID)
+	if err == store.ErrNotFound {
+		return nil, nil
+	}
+	return p, err
+}
+
+// fileWriteTools are the tools whose successful calls change the file at "path".
+var fileWriteTools = map[string]bool{"write_file": true, "edit_file": true}
+
+// ChangedFiles lists the files changed by the session's successful write_file and
+// edit_file calls, from its event log, sorted by path.
+func (e *Engine) ChangedFiles(ctx context.Context, sessionID string) ([]api.FileChange, error) {
+	stream, err := e.eventLog.Stream(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	paths := map[string]string{} // tool call ID -> path
+	edits := map[string]int{}
+	for {
+		ev, err := stream.Recv(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case ev.Type == api.EventToolCall && ev.ToolCall != nil && fileWriteTools[ev.ToolCall.ToolName]:
+			if p, _ := ev.ToolCall.Args["path"].(string); p != "" {
+				paths[ev.ToolCall.ToolCallID] = p
+			}
+		case ev.Type == api.EventToolResult && ev.ToolResult != nil && ev.ToolResult.Result.Status == "success":
+			if p, ok := paths[ev.ToolResult.ToolCallID]; ok {
+				edits[p]++
+			}
+		}
+	}
+
+	out := make([]api.FileChange, 0, len(edits))
+	for p, n := range edits {
+		out = append(out, api.FileChange{Path: p, Edits: n})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// ExportSession writes the session's conversation to w as "markdown" (a readable
+// transcript) or "json" (the session record).
+func (e *Engine) ExportSession(ctx context.Context, sessionID, format string, w io.Writer) error {
+	session, err := e.sessionStore.Get(ctx, sessionID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
+		}
+		return err
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(session)
+	case "markdown", "md", "":
+	default:
+		return fmt.Errorf("unknown export format %q (want markdown or json)", format)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Session %s\n\n", session.SessionID)
+	fmt.Fprintf(bw, "Started %s", session.CreatedAt.Format("2006-01-02 15:04"))
+	if session.ActiveSkill != "" {
+		fmt.Fprintf(bw, " · skill %s", session.ActiveSkill)
+	}
+	fmt.Fprint(bw, "\n")
+	if session.Summary != "" {
+		fmt.Fprintf(bw, "\n## Summary of earlier messages\n\n%s\n", session.Summary)
+	}
+	for _, m := range session.Messages {
+		switch m.Role {
+		case "system":
+			continue
+		case "user":
+			fmt.Fprintf(bw, "\n## User\n\n%s\n", messageText(m))
+		case "assistant":
+			fmt.Fprint(bw, "\n## Assistant\n")
+			if m.Content != "" {
+				fmt.Fprintf(bw, "\n%s\n", m.Content)
+			}
+			for _, tc := range m.ToolCalls {
+				fmt.Fprintf(bw, "\n**Tool call** `%s`\n\n```json\n%s\n```\n", tc.Name, tc.Args)
+			}
+		case "tool":
+			fmt.Fprintf(bw, "\n**Tool result**\n\n```\n%s\n```\n", strings.TrimRight(messageText(m), "\n"))
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestEngine_UpdateSessionSwitchesModeAndModel(t *testing.T) {
+	ctx := context.Background()
+	mainLLM, roleLLM := &batchLLM{}, &batchLLM{}
+	eng := newBatchEngine(t, t.TempDir(), mainLLM)
+	eng.cfg.Models = NewModelSet(map[string]RoleModel{RoleSummarizer: {LLM: roleLLM, Model: "small"}}, func(string) LLM { return roleLLM })
+	sid, err := eng.StartSession(ctx, api.StartOptions{ApprovalMode: api.ModeSuggest})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	mode, model, skill := api.ModeFullAuto, RoleSummarizer, "missing"
+	if _, err := eng.UpdateSession(ctx, sid, api.SessionUpdate{ActiveSkill: &skill}); err == nil {
+		t.Fatalf("expected an unknown skill refused")
+	}
+	// Any name could be built; only configured ones are accepted.
+	unknown, byName := "smal", "small"
+	if _, err := eng.UpdateSession(ctx, sid, api.SessionUpdate{Model: &unknown}); err == nil {
+		t.Fatalf("expected an unknown model refused")
+	}
+	if info, err := eng.UpdateSession(ctx, sid, api.SessionUpdate{Model: &byName}); err != nil || info.Model != "small" {
+		t.Fatalf("expected a role's model accepted by name, got %+v err=%v", info, err)
+	}
+	info, err := eng.UpdateSession(ctx, sid, api.SessionUpdate{ApprovalMode: &mode, Model: &model})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if info.ApprovalMode != api.ModeFullAuto || info.Model != RoleSummarizer {
+		t.Fatalf("expected the new settings, got %+v", info)
+	}
+
+	// Full-auto runs both writes without asking, on the session's model.
+	stream, err := eng.Send(ctx, sid, "write both")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	for _, e := range collectEvents(t, stream) {
+		if e.Type == api.EventApproval {
+			t.Fatalf("expected no approval in full-auto")
+		}
+	}
+	if len(mainLLM.requests) != 0 || len(roleLLM.requests) != 2 {
+		t.Fatalf("expected the turn on the session model, got main=%d role=%d", len(mainLLM.requests), len(roleLLM.requests))
+	}
+
+	changes, err := eng.ChangedFiles(ctx, sid)
+	if err != nil {
+		t.Fatalf("changed files: %v", err)
+	}
+	if len(changes) != 2 || changes[0] != (api.FileChange{Path: "a.txt", Edits: 1}) || changes[1].Path != "b.txt" {
+		t.Fatalf("expected a.txt and b.txt changed, got %+v", changes)
+	}
+
+	var out strings.Builder
+	if err := eng.ExportSession(ctx, sid, "markdown", &out); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	for _, want := range []string{"# Session " + sid, "## User\n\nwrite both", "**Tool call** `write_file`", "## Assistant\n\ndone"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in the export:\n%s", want, out.String())
+		}
+	}
+}
@@ -291,6 +291,7 @@ func (r *TurnRunner) proposeAndMaybeExecuteTool(ctx context.Context, state *api.
 			Preview:   preview,
 			CreatedAt: time.Now(),
 			StopAfter: stopAfter,
+			Seq:       r.seq,
 		}
 		if err := r.saveSession(ctx); err != nil {
 			return loopOutcomeCompleted, true, err
@@ -298,10 +299,7 @@ func (r *TurnRunner) proposeAndMaybeExecuteTool(ctx context.Context, state *api.
 		return loopOutcomeSuspended, true, nil
 	}
 
-	result, err := tool.Execute(ctx, execArgs)
-	if err != nil {
-		result = api.ToolResult{Status: "error", Error: err.Error()}
-	}
+	result := r.executeTool(ctx, toolName, tool, execArgs)
 	r.emit(ctx, api.Event{
 		Type: api.EventToolResult,
 		ToolResult: &api.ToolResultPayload{
@@ -0,0 +1,195 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/logger"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Skill Hooks
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// SkillHookRunner executes a skill's lifecycle hook script.
+// tools.RunSkillScriptTool implements it (same path validation and sandboxing as run_skill_script).
+type SkillHookRunner interface {
+	RunHook(ctx context.Context, skillName string, hook api.SkillHook, env map[string]string) (api.ToolResult, error)
+}
+
+const (
+	// Session metadata keys used by skill hooks.
+	metaSkillHooksActivated = "skill_hooks_activated"
+	metaSkillHookNotes      = "skill_hook_notes"
+
+	maxHookNoteChars = 2000
+)
+
+// activeSkillHooks returns the active skill's hooks for an event (filtered by tool name for tool events).
+func (r *TurnRunner) activeSkillHooks(event api.SkillHookEvent, toolName string) []api.SkillHook {
+	if r.cfg.HookRunner == nil || r.cfg.SkillIndex == nil || r.session == nil || r.session.ActiveSkill == "" {
+		return nil
+	}
+	sk, err := r.cfg.SkillIndex.Load(r.session.ActiveSkill)
+	if err != nil || sk == nil {
+		return nil
+	}
+	var out []api.SkillHook
+	for _, h := range sk.Hooks {
+		if h.Event != event {
+			continue
+		}
+		if toolName != "" && !h.MatchesTool(toolName) {
+			continue
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+// runSkillHooks runs all matching hooks for an event. Hook output is queued as a
+// note for the model. A failing blocking pre-tool hook returns an error.
+func (r *TurnRunner) runSkillHooks(ctx context.Context, event api.SkillHookEvent, toolName string, args api.Args, result *api.ToolResult) error {
+	hooks := r.activeSkillHooks(event, toolName)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	env := map[string]string{
+		"HOOK_EVENT":      string(event),
+		"HOOK_SESSION_ID": r.session.SessionID,
+	}
+	if toolName != "" {
+		env["HOOK_TOOL_NAME"] = toolName
+		if b, err := json.Marshal(args); err == nil {
+			env["HOOK_TOOL_ARGS"] = string(b)
+		}
+		if p, ok := args["path"].(string); ok {
+			env["HOOK_TOOL_PATH"] = p
+		}
+	}
+	if result != nil {
+		env["HOOK_TOOL_STATUS"] = result.Status
+	}
+
+	skillName := r.session.ActiveSkill
+	for _, h := range hooks {
+		res, err := r.cfg.HookRunner.RunHook(ctx, skillName, h, env)
+		if err != nil {
+			res = api.ToolResult{Status: "error", Error: err.Error()}
+		}
+		failed := res.Status != "success"
+
+		logger.Info("SkillHook", "Hook executed", map[string]interface{}{
+			"skill":  skillName,
+			"event":  string(event),
+			"script": h.Script,
+			"tool":   toolName,
+			"status": res.Status,
+		})
+		r.emit(ctx, api.Event{
+			Type:     api.EventThinking,
+			Thinking: &api.ThinkingPayload{Message: fmt.Sprintf("🪝 Skill hook %s %s: %s", event, h.Script, res.Status)},
+		})
+
+		r.addHookNote(event, h, toolName, res)
+
+		if failed && h.Blocking && event == api.HookPreTool {
+			msg := strings.TrimSpace(res.Content)
+			if msg == "" {
+				msg = res.Error
+			}
+			return fmt.Errorf("blocked by skill hook %s: %s", h.Script, truncateForLog(msg, maxHookNoteChars))
+		}
+	}
+	return nil
+}
+
+// addHookNote queues hook output for the next LLM request.
+func (r *TurnRunner) addHookNote(event api.SkillHookEvent, h api.SkillHook, toolName string, res api.ToolResult) {
+	body := strings.TrimSpace(res.Content)
+	if body == "<script completed with no output>" {
+		body = ""
+	}
+	if res.Status == "success" && body == "" {
+		return
+	}
+	if body == "" {
+		body = res.Error
+	}
+
+	header := fmt.Sprintf("[%s %s", event, h.Script)
+	if toolName != "" {
+		header += " → " + toolName
+	}
+	header += fmt.Sprintf("] (%s)", res.Status)
+
+	note := header + "\n" + truncateForLog(body, maxHookNoteChars)
+	if r.session.Metadata == nil {
+		r.session.Metadata = make(map[string]string)
+	}
+	if prev := r.session.Metadata[metaSkillHookNotes]; prev != "" {
+		note = prev + "\n\n" + note
+	}
+	r.session.Metadata[metaSkillHookNotes] = note
+}
+
+// takeSkillHookNotes returns queued hook notes as a system prompt block and clears them.
+func (r *TurnRunner) takeSkillHookNotes() string {
+	if r.session == nil || r.session.Metadata == nil {
+		return ""
+	}
+	notes := strings.TrimSpace(r.session.Metadata[metaSkillHookNotes])
+	if notes == "" {
+		return ""
+	}
+	delete(r.session.Metadata, metaSkillHookNotes)
+	return "\n\n--- SKILL HOOK NOTES ---\n" + notes + "\n--- END SKILL HOOK NOTES ---\n"
+}
+
+// maybeRunActivateHooks runs on-activate hooks once each time the active skill changes.
+func (r *TurnRunner) maybeRunActivateHooks(ctx context.Context) {
+	if r.session == nil {
+		return
+	}
+	active := r.session.ActiveSkill
+	if r.session.Metadata == nil {
+		r.session.Metadata = make(map[string]string)
+	}
+	if r.session.Metadata[metaSkillHooksActivated] == active {
+		return
+	}
+	if active == "" {
+		delete(r.session.Metadata, metaSkillHooksActivated)
+		return
+	}
+	r.session.Metadata[metaSkillHooksActivated] = active
+	_ = r.runSkillHooks(ctx, api.HookOnActivate, "", nil, nil)
+}
+
+// runTurnEndHooks runs on-turn-end hooks; their notes carry over to the next turn.
+func (r *TurnRunner) runTurnEndHooks(ctx context.Context) {
+	if len(r.activeSkillHooks(api.HookOnTurnEnd, "")) == 0 {
+		return
+	}
+	_ = r.runSkillHooks(ctx, api.HookOnTurnEnd, "", nil, nil)
+	_ = r.saveSession(ctx)
+}
+
+// executeTool runs a tool wrapped in the active skill's pre-tool and post-tool hooks.
+func (r *TurnRunner) executeTool(ctx context.Context, toolName string, tool Tool, execArgs api.Args) api.ToolResult {
+	if err := r.runSkillHooks(ctx, api.HookPreTool, toolName, execArgs, nil); err != nil {
+		return api.ToolResult{Status: "error", Content: err.Error(), Error: err.Error()}
+	}
+
+	result, err := tool.Execute(ctx, execArgs)
+	if err != nil {
+		result = api.ToolResult{Status: "error", Error: err.Error()}
+	}
+
+	_ = r.runSkillHooks(ctx, api.HookPostTool, toolName, execArgs, &result)
+	return result
+}
@@ -0,0 +1,147 @@
+package runtime
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/policy"
+	"AgentEngine/pkg/engine/store"
+	"AgentEngine/pkg/engine/tools"
+)
+
+// scriptedLLM returns one tool call on the first request and plain text afterwards.
+type scriptedLLM struct {
+	toolCall *api.LLMToolCall
+	requests []LLMRequest
+}
+
+func (s *scriptedLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	s.requests = append(s.requests, req)
+	if len(s.requests) == 1 && s.toolCall != nil {
+		return &toolCallStream{tc: s.toolCall}, nil
+	}
+	return &staticStream{content: "done"}, nil
+}
+
+type toolCallStream struct {
+	tc   *api.LLMToolCall
+	sent bool
+}
+
+func (s *toolCallStream) Recv(ctx context.Context) (LLMChunk, error) {
+	if s.sent {
+		return LLMChunk{}, io.EOF
+	}
+	s.sent = true
+	return LLMChunk{ToolCall: s.tc, FinishReason: "tool_calls"}, nil
+}
+
+func (s *toolCallStream) Close() error { return nil }
+
+type fakeHookRunner struct {
+	calls []api.SkillHookEvent
+}
+
+func (f *fakeHookRunner) RunHook(ctx context.Context, skillName string, hook api.SkillHook, env map[string]string) (api.ToolResult, error) {
+	f.calls = append(f.calls, hook.Event)
+	if hook.Event == api.HookPreTool {
+		return api.ToolResult{Status: "error", Content: "path is read-only: " + env["HOOK_TOOL_PATH"], Error: "exit status 1"}, nil
+	}
+	return api.ToolResult{Status: "success", Content: "hook ran"}, nil
+}
+
+func TestTurnRunner_SkillHooks_BlockingPreToolAndNotes(t *testing.T) {
+	ws := t.TempDir()
+
+	reg := tools.NewRegistry()
+	reg.MustRegister(tools.NewWriteFileTool(ws))
+
+	sessionStore, err := store.NewFileSessionStore(ws)
+	if err != nil {
+		t.Fatalf("session store: %v", err)
+	}
+	planStore, err := store.NewFilePlanStore(ws)
+	if err != nil {
+		t.Fatalf("plan store: %v", err)
+	}
+
+	llm := &scriptedLLM{toolCall: &api.LLMToolCall{
+		ID:   "call_1",
+		Name: "write_file",
+		Args: `{"path":"out.txt","content":"hello"}`,
+	}}
+	hooks := &fakeHookRunner{}
+
+	runner := NewTurnRunner(TurnRunnerConfig{
+		LLM:           llm,
+		Tools:         reg,
+		Policy:        policy.NewDefaultPolicy(),
+		SessionStore:  sessionStore,
+		PlanStore:     planStore,
+		WorkspaceRoot: ws,
+		SkillIndex: stubSkillIndex{sk: &api.Skill{
+			SkillMeta: api.SkillMeta{Name: "guarded"},
+			Hooks: []api.SkillHook{
+				{Event: api.HookOnActivate, Script: "scripts/init.sh"},
+				{Event: api.HookPreTool, Script: "scripts/guard.sh", Tools: []string{"write_file"}, Blocking: true},
+				{Event: api.HookPreTool, Script: "scripts/never.sh", Tools: []string{"shell"}},
+			},
+		}},
+		ApprovalMode: api.ModeFullAuto,
+		HookRunner:   hooks,
+	})
+
+	sess := &api.Session{SessionID: "s1", ActiveSkill: "guarded", Metadata: map[string]string{}}
+	stream, err := runner.Run(context.Background(), sess, "write a file")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	drainEvents(t, stream)
+
+	if _, err := os.Stat(filepath.Join(ws, "out.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected write_file to be blocked, stat err=%v", err)
+	}
+
+	want := []api.SkillHookEvent{api.HookOnActivate, api.HookPreTool}
+	if len(hooks.calls) != len(want) {
+		t.Fatalf("expected hook calls %v, got %v", want, hooks.calls)
+	}
+	for i := range want {
+		if hooks.calls[i] != want[i] {
+			t.Fatalf("expected hook calls %v, got %v", want, hooks.calls)
+		}
+	}
+
+	var toolMsg string
+	for _, m := range sess.Messages {
+		if m.Role == "tool" && m.ToolCallID == "call_1" {
+			toolMsg = m.Content
+		}
+	}
+	if !strings.Contains(toolMsg, "blocked by skill hook scripts/guard.sh") {
+		t.Fatalf("expected blocked tool result, got %q", toolMsg)
+	}
+
+	if len(llm.requests) != 2 {
+		t.Fatalf("expected 2 LLM requests, got %d", len(llm.requests))
+	}
+	first := llm.requests[0].Messages[0]
+	if first.Role != "system" || !strings.Contains(first.Content, "--- SKILL HOOK NOTES ---") || !strings.Contains(first.Content, "hook ran") {
+		t.Fatalf("expected on-activate note in first request, got %q", first.Content)
+	}
+	second := llm.requests[1].Messages[0]
+	if !strings.Contains(second.Content, "path is read-only: out.txt") {
+		t.Fatalf("expected pre-tool note in second request, got %q", second.Content)
+	}
+	if strings.Contains(second.Content, "hook ran") {
+		t.Fatalf("expected consumed notes not to repeat")
+	}
+	if sess.Metadata[metaSkillHooksActivated] != "guarded" {
+		t.Fatalf("expected activation to be recorded")
+	}
+}
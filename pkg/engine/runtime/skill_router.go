@@ -1,24 +1,62 @@
 package runtime
 
 import (
+	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strings"
 
 	"AgentEngine/pkg/engine/api"
+	planpkg "AgentEngine/pkg/engine/plan"
 )
 
 type routeSkillInput struct {
 	UserMessage string
 	PlanHint    string
+
+	// Semantic holds optional cosine similarities per skill name (see SemanticSkillRouter).
+	Semantic map[string]float64
 }
 
 type routeSkillDecision struct {
-	Skill  string
-	Source string // user | auto
-	Locked bool
-	Reason string
-	Score  int
+	Skill     string
+	Source    string // user | auto
+	Locked    bool
+	Reason    string // why the skill was picked, or why nothing was (when ok=false)
+	Score     int
+	Rationale string // Human-readable scoring breakdown for logs
+
+	// Candidates holds every skill's score breakdown (best first) when scoring ran.
+	Candidates []SkillRouteCandidate
+}
+
+// SkillRouteCandidate is one skill's routing score breakdown.
+type SkillRouteCandidate struct {
+	Skill      string   `json:"skill"`
+	Score      int      `json:"score"`
+	Keyword    int      `json:"keyword"`
+	Semantic   int      `json:"semantic"`             // points contributed by embedding similarity
+	Similarity float64  `json:"similarity,omitempty"` // raw cosine similarity (0 when semantic routing is off)
+	Matched    []string `json:"matched,omitempty"`    // triggers/keywords that matched
+}
+
+const (
+	// Auto routing needs at least this score and this lead over the runner-up.
+	routeMinScore  = 8
+	routeMinMargin = 2
+
+	// Similarities below this floor add nothing; above it they scale linearly up to semanticWeight.
+	semanticMinSimilarity = 0.35
+	semanticWeight        = 30
+)
+
+// semanticBonus converts a cosine similarity into keyword-score points.
+func semanticBonus(sim float64) int {
+	if sim <= semanticMinSimilarity {
+		return 0
+	}
+	return int(math.Round((sim - semanticMinSimilarity) / (1 - semanticMinSimilarity) * semanticWeight))
 }
 
 func routeSkill(skills []api.SkillMeta, in routeSkillInput) (routeSkillDecision, bool) {
@@ -49,46 +87,71 @@ func routeSkill(skills []api.SkillMeta, in routeSkillInput) (routeSkillDecision,
 
 	ctx := normalizeForMatch(userMsg + " " + planHint)
 	if ctx == "" {
-		return routeSkillDecision{}, false
+		return routeSkillDecision{Reason: "empty_context"}, false
 	}
 
-	type scored struct {
-		name  string
-		score int
-	}
-	scoredList := make([]scored, 0, len(skills))
-	for _, sk := range skills {
-		scoredList = append(scoredList, scored{name: sk.Name, score: scoreSkill(sk, ctx)})
+	candidates := rankSkillCandidates(skills, ctx, in.Semantic)
+	if len(candidates) == 0 {
+		return routeSkillDecision{Reason: "no_skills"}, false
 	}
 
-	sort.Slice(scoredList, func(i, j int) bool {
-		if scoredList[i].score == scoredList[j].score {
-			return scoredList[i].name < scoredList[j].name
-		}
-		return scoredList[i].score > scoredList[j].score
-	})
-
-	if len(scoredList) == 0 {
-		return routeSkillDecision{}, false
+	best := candidates[0]
+	if best.Score < routeMinScore {
+		return routeSkillDecision{Reason: "below_threshold", Score: best.Score, Candidates: candidates}, false
+	}
+	if len(candidates) > 1 && best.Score-candidates[1].Score < routeMinMargin {
+		return routeSkillDecision{Reason: "ambiguous", Score: best.Score, Candidates: candidates}, false
 	}
 
-	best := scoredList[0]
-	if best.score < 8 {
-		return routeSkillDecision{}, false
+	reason := "scored_match"
+	switch {
+	case best.Semantic > 0 && best.Keyword == 0:
+		reason = "semantic_match"
+	case best.Semantic > 0:
+		reason = "hybrid_match"
+	}
+	rationale := fmt.Sprintf("keyword=%d", best.Keyword)
+	if in.Semantic != nil {
+		rationale += fmt.Sprintf(" semantic=%.3f(+%d)", best.Similarity, best.Semantic)
 	}
-	if len(scoredList) > 1 && best.score-scoredList[1].score < 2 {
-		return routeSkillDecision{}, false
+	if len(candidates) > 1 {
+		rationale += fmt.Sprintf(" runner_up=%s(%d)", candidates[1].Skill, candidates[1].Score)
 	}
 
 	return routeSkillDecision{
-		Skill:  best.name,
-		Source: "auto",
-		Locked: false,
-		Reason: "scored_match",
-		Score:  best.score,
+		Skill:      best.Skill,
+		Source:     "auto",
+		Locked:     false,
+		Reason:     reason,
+		Score:      best.Score,
+		Rationale:  rationale,
+		Candidates: candidates,
 	}, true
 }
 
+// rankSkillCandidates scores every skill against the normalized context, best first.
+func rankSkillCandidates(skills []api.SkillMeta, normalizedContext string, semantic map[string]float64) []SkillRouteCandidate {
+	out := make([]SkillRouteCandidate, 0, len(skills))
+	for _, sk := range skills {
+		kw, matched := scoreSkillMatches(sk, normalizedContext)
+		c := SkillRouteCandidate{Skill: sk.Name, Keyword: kw, Matched: matched}
+		if sim, ok := semantic[sk.Name]; ok {
+			c.Similarity = sim
+			c.Semantic = semanticBonus(sim)
+		}
+		c.Score = c.Keyword + c.Semantic
+		out = append(out, c)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score == out[j].Score {
+			return out[i].Skill < out[j].Skill
+		}
+		return out[i].Score > out[j].Score
+	})
+	return out
+}
+
 func skillExists(skills []api.SkillMeta, name string) bool {
 	for _, sk := range skills {
 		if sk.Name == name {
@@ -144,30 +207,31 @@ func planHintFromPlan(plan *api.PlanPayload) string {
 	if plan == nil || len(plan.Items) == 0 {
 		return ""
 	}
-	for _, it := range plan.Items {
-		if it.Status == api.PlanRunning {
-			return strings.TrimSpace(it.Text)
-		}
-	}
-	for _, it := range plan.Items {
-		if it.Status == api.PlanPending {
-			return strings.TrimSpace(it.Text)
-		}
+	if it := planpkg.Next(plan); it != nil {
+		return strings.TrimSpace(it.Text)
 	}
 	return ""
 }
 
 func scoreSkill(sk api.SkillMeta, normalizedContext string) int {
+	score, _ := scoreSkillMatches(sk, normalizedContext)
+	return score
+}
+
+// scoreSkillMatches returns the keyword score and what matched (for explanations).
+func scoreSkillMatches(sk api.SkillMeta, normalizedContext string) (int, []string) {
 	if sk.Name == "" {
-		return 0
+		return 0, nil
 	}
 
 	name := strings.ToLower(strings.TrimSpace(sk.Name))
 	score := 0
+	var matched []string
 
 	// Strong match on skill name.
 	if strings.Contains(normalizedContext, name) {
 		score += 12
+		matched = append(matched, "name:"+name)
 	}
 
 	// Skill name tokens.
@@ -178,6 +242,7 @@ func scoreSkill(sk api.SkillMeta, normalizedContext string) int {
 		}
 		if strings.Contains(normalizedContext, tok) {
 			score += 2
+			matched = append(matched, "token:"+tok)
 		}
 	}
 
@@ -188,11 +253,13 @@ func scoreSkill(sk api.SkillMeta, normalizedContext string) int {
 		}
 		if triggerMatches(trig, normalizedContext) {
 			score += 15
+			matched = append(matched, `"`+trig+`"`)
 			continue
 		}
 		trigNorm := normalizeForMatch(trig)
 		if trigNorm != "" && strings.Contains(normalizedContext, trigNorm) {
 			score += 15
+			matched = append(matched, `"`+trig+`"`)
 			continue
 		}
 	}
@@ -204,10 +271,11 @@ func scoreSkill(sk api.SkillMeta, normalizedContext string) int {
 		}
 		if strings.Contains(normalizedContext, w) {
 			score++
+			matched = append(matched, "word:"+w)
 		}
 	}
 
-	return score
+	return score, matched
 }
 
 func triggerMatches(trigger string, normalizedContext string) bool {
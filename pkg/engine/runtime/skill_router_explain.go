@@ -0,0 +1,157 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Routing Explanation
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// SkillRouteInput is the routing query for ExplainSkillRoute.
+type SkillRouteInput struct {
+	UserMessage string
+	PlanHint    string
+	ActiveSkill string
+	Locked      bool               // session skill is locked (user-selected)
+	Semantic    map[string]float64 // optional cosine similarities per skill
+}
+
+// SkillRouteExplanation describes what the skill router does with a message and why.
+type SkillRouteExplanation struct {
+	ActiveSkill string                `json:"active_skill,omitempty"` // skill before routing
+	Locked      bool                  `json:"locked"`                 // lock state before routing
+	Unlocked    bool                  `json:"unlocked,omitempty"`     // message asked to unlock auto routing
+	Candidates  []SkillRouteCandidate `json:"candidates,omitempty"`
+
+	Skill     string `json:"skill,omitempty"`  // skill after routing
+	Changed   bool   `json:"changed"`          // Skill differs from ActiveSkill
+	Source    string `json:"source,omitempty"` // user | auto | "" (no decision)
+	Reason    string `json:"reason"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// ExplainSkillRoute runs the skill router offline and returns the full decision trace.
+// It applies the same rules as a real turn: unlock phrases, explicit user override,
+// lock state, plan skill tags and keyword/semantic scoring.
+func ExplainSkillRoute(skills []api.SkillMeta, in SkillRouteInput) SkillRouteExplanation {
+	exp := SkillRouteExplanation{
+		ActiveSkill: in.ActiveSkill,
+		Locked:      in.Locked,
+		Unlocked:    isUnlockSkillMessage(in.UserMessage),
+		Skill:       in.ActiveSkill,
+	}
+
+	decision, ok := routeSkill(skills, routeSkillInput{
+		UserMessage: in.UserMessage,
+		PlanHint:    in.PlanHint,
+		Semantic:    in.Semantic,
+	})
+	exp.Candidates = decision.Candidates
+	if len(exp.Candidates) == 0 {
+		// Overrides and plan tags short-circuit scoring; score anyway for display.
+		if ctx := normalizeForMatch(in.UserMessage + " " + in.PlanHint); ctx != "" {
+			exp.Candidates = rankSkillCandidates(skills, ctx, in.Semantic)
+		}
+	}
+
+	// Explicit user override always wins, even if locked.
+	if ok && decision.Source == "user" {
+		exp.Skill = decision.Skill
+		exp.Source = "user"
+		exp.Reason = decision.Reason
+		exp.Changed = decision.Skill != in.ActiveSkill
+		return exp
+	}
+
+	if in.Locked && !exp.Unlocked {
+		exp.Reason = "locked"
+		return exp
+	}
+
+	if !ok {
+		exp.Reason = decision.Reason
+		return exp
+	}
+
+	exp.Source = decision.Source
+	exp.Reason = decision.Reason
+	exp.Rationale = decision.Rationale
+	if decision.Skill == in.ActiveSkill {
+		exp.Reason = "already_active"
+		return exp
+	}
+	exp.Skill = decision.Skill
+	exp.Changed = true
+	return exp
+}
+
+// Summary returns a one-line description of the decision.
+func (e SkillRouteExplanation) Summary() string {
+	from := e.ActiveSkill
+	if from == "" {
+		from = "(none)"
+	}
+	switch {
+	case e.Changed:
+		return fmt.Sprintf("switch %s → %s (%s, %s)", from, e.Skill, e.Source, e.Reason)
+	case e.Reason == "locked":
+		return fmt.Sprintf("keep %s (locked by user)", from)
+	case e.Reason == "already_active":
+		return fmt.Sprintf("keep %s (best match)", from)
+	case e.Reason == "below_threshold":
+		return fmt.Sprintf("keep %s (no candidate reached score %d)", from, routeMinScore)
+	case e.Reason == "ambiguous":
+		return fmt.Sprintf("keep %s (top candidates within %d points)", from, routeMinMargin)
+	default:
+		return fmt.Sprintf("keep %s (%s)", from, e.Reason)
+	}
+}
+
+// String renders the decision and the top candidates for a thinking/notice event.
+func (e SkillRouteExplanation) String() string {
+	var b strings.Builder
+	b.WriteString("Skill routing: " + e.Summary())
+	if e.Rationale != "" {
+		b.WriteString("\n  " + e.Rationale)
+	}
+	for i, c := range e.Candidates {
+		if i >= 3 || c.Score == 0 {
+			break
+		}
+		b.WriteString("\n  " + c.String())
+	}
+	return b.String()
+}
+
+// String renders one candidate's score breakdown.
+func (c SkillRouteCandidate) String() string {
+	s := fmt.Sprintf("%s score=%d keyword=%d", c.Skill, c.Score, c.Keyword)
+	if c.Similarity != 0 {
+		s += fmt.Sprintf(" semantic=%.3f(+%d)", c.Similarity, c.Semantic)
+	}
+	if len(c.Matched) > 0 {
+		s += " matched: " + strings.Join(c.Matched, ", ")
+	}
+	return s
+}
+
+// noteworthy reports whether a turn should surface the explanation to the user:
+// the skill changed, or a different skill scored well but was held back.
+func (e SkillRouteExplanation) noteworthy() bool {
+	if e.Changed {
+		return true
+	}
+	if e.Reason != "locked" && e.Reason != "ambiguous" {
+		return false
+	}
+	return len(e.Candidates) > 0 && e.Candidates[0].Skill != e.ActiveSkill && e.Candidates[0].Score >= routeMinScore
+}
+
+// PlanHint returns the plan item text the router considers (the next actionable item).
+func PlanHint(plan *api.PlanPayload) string {
+	return planHintFromPlan(plan)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"AgentEngine/pkg/engine/api"
 	"AgentEngine/pkg/engine/store"
 	"AgentEngine/pkg/logger"
 )
@@ -33,47 +34,62 @@ func (r *TurnRunner) maybeRouteSkill(ctx context.Context, userMessage string) {
 
 	skills := r.cfg.SkillIndex.List()
 	planHint := r.readPlanHint(ctx)
-
-	// Explicit user override always wins, even if locked.
-	if name, ok := parseUserSkillOverride(skills, userMessage); ok {
-		r.session.ActiveSkill = name
-		r.session.Metadata["skill_locked"] = "true"
-		r.session.Metadata["skill_source"] = "user"
-		r.session.Metadata["skill_last_reason"] = "explicit_user_override"
-		logger.Info("SkillRouter", "Skill locked by user", map[string]interface{}{
-			"skill": name,
-		})
-		return
-	}
-
 	locked := strings.EqualFold(r.session.Metadata["skill_locked"], "true")
-	if locked && !unlocked {
-		return
+
+	// Semantic scoring is only worth its cost when auto routing may apply.
+	var semantic map[string]float64
+	if r.cfg.SemanticRouter != nil && (!locked || unlocked) {
+		sims, err := r.cfg.SemanticRouter.Similarities(ctx, skills, userMessage, planHint)
+		if err != nil {
+			logger.Warn("SkillRouter", "Semantic scoring failed, using keywords only", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			semantic = sims
+		}
 	}
 
-	decision, ok := routeSkill(skills, routeSkillInput{
+	exp := ExplainSkillRoute(skills, SkillRouteInput{
 		UserMessage: userMessage,
 		PlanHint:    planHint,
+		ActiveSkill: r.session.ActiveSkill,
+		Locked:      locked && !unlocked,
+		Semantic:    semantic,
 	})
-	if !ok {
-		return
+	if exp.noteworthy() {
+		r.emit(ctx, api.Event{
+			Type:     api.EventThinking,
+			Thinking: &api.ThinkingPayload{Message: "🧭 " + exp.String()},
+			Display:  &api.DisplayHint{Level: "info", Style: "collapsible"},
+		})
 	}
 
-	// Apply auto decision (non-locking).
-	if decision.Source == "auto" {
-		if decision.Skill != "" && decision.Skill != r.session.ActiveSkill {
-			prev := r.session.ActiveSkill
-			r.session.ActiveSkill = decision.Skill
-			r.session.Metadata["skill_source"] = "auto"
-			r.session.Metadata["skill_last_reason"] = decision.Reason
-			r.session.Metadata["skill_locked"] = "false"
-			logger.Info("SkillRouter", "Auto-selected skill", map[string]interface{}{
-				"from":     prev,
-				"to":       decision.Skill,
-				"score":    decision.Score,
-				"planHint": truncateForLog(planHint, 120),
-			})
+	switch exp.Source {
+	case "user":
+		// Explicit user override always wins, even if locked.
+		r.session.ActiveSkill = exp.Skill
+		r.session.Metadata["skill_locked"] = "true"
+		r.session.Metadata["skill_source"] = "user"
+		r.session.Metadata["skill_last_reason"] = exp.Reason
+		logger.Info("SkillRouter", "Skill locked by user", map[string]interface{}{
+			"skill": exp.Skill,
+		})
+	case "auto":
+		// Apply auto decision (non-locking).
+		if !exp.Changed || exp.Skill == "" {
+			return
 		}
+		r.session.ActiveSkill = exp.Skill
+		r.session.Metadata["skill_source"] = "auto"
+		r.session.Metadata["skill_last_reason"] = exp.Reason
+		r.session.Metadata["skill_locked"] = "false"
+		logger.Info("SkillRouter", "Auto-selected skill", map[string]interface{}{
+			"from":      exp.ActiveSkill,
+			"to":        exp.Skill,
+			"reason":    exp.Reason,
+			"rationale": exp.Rationale,
+			"planHint":  truncateForLog(planHint, 120),
+		})
 	}
 }
 
@@ -0,0 +1,59 @@
+package runtime
+
+import (
+	"context"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/embed"
+)
+
+// SemanticSkillRouter scores skills by embedding similarity between the skill
+// description and the user message plus plan hint. Skill vectors are cached on
+// disk keyed by content hash, so only changed skills are re-embedded.
+type SemanticSkillRouter struct {
+	provider embed.Provider
+	cache    *embed.Cache
+}
+
+// NewSemanticSkillRouter creates a router; cacheDir may be empty for an in-memory cache.
+func NewSemanticSkillRouter(provider embed.Provider, cacheDir string) *SemanticSkillRouter {
+	return &SemanticSkillRouter{provider: provider, cache: embed.NewCache(cacheDir)}
+}
+
+// Similarities returns the cosine similarity of each skill to the routing context.
+func (s *SemanticSkillRouter) Similarities(ctx context.Context, skills []api.SkillMeta, userMessage, planHint string) (map[string]float64, error) {
+	query := strings.TrimSpace(strings.TrimSpace(userMessage) + "\n" + strings.TrimSpace(planHint))
+	if s == nil || s.provider == nil || query == "" || len(skills) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]string, 0, len(skills))
+	for _, sk := range skills {
+		texts = append(texts, skillEmbeddingText(sk))
+	}
+	skillVecs, err := s.cache.Embed(ctx, s.provider, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The query changes every turn, so it bypasses the disk cache.
+	queryVecs, err := s.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(queryVecs) == 0 {
+		return nil, nil
+	}
+
+	out := make(map[string]float64, len(skills))
+	for i, sk := range skills {
+		out[sk.Name] = embed.Cosine(queryVecs[0], skillVecs[i])
+	}
+	return out, nil
+}
+
+func skillEmbeddingText(sk api.SkillMeta) string {
+	name := strings.ReplaceAll(sk.Name, "-", " ")
+	return strings.TrimSpace(name + ": " + sk.Description)
+}
@@ -1,6 +1,8 @@
 package runtime
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"AgentEngine/pkg/engine/api"
@@ -149,3 +151,104 @@ func TestPlanHintFromPlan_PendingWhenNoRunning(t *testing.T) {
 		t.Fatalf("unexpected hint: %q", got)
 	}
 }
+
+// fakeEmbedder maps texts to fixed axes by keyword so similarities are predictable.
+type fakeEmbedder struct {
+	axes  map[string]int
+	calls int
+}
+
+func (f *fakeEmbedder) Model() string { return "fake" }
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		v := make([]float32, len(f.axes)+1)
+		v[len(f.axes)] = 0.1 // shared noise axis
+		for kw, axis := range f.axes {
+			if strings.Contains(t, kw) {
+				v[axis] += 1
+			}
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func TestRouteSkill_SemanticPicksParaphrase(t *testing.T) {
+	skills := []api.SkillMeta{
+		{Name: "chapter-write", Description: `Write novel chapters. Triggers on "write chapter"`},
+		{Name: "world-build", Description: `Design settings, factions and maps`},
+	}
+	fake := &fakeEmbedder{axes: map[string]int{"chapter": 0, "续写": 0, "settings": 1}}
+	router := NewSemanticSkillRouter(fake, t.TempDir())
+
+	msg := "帮我续写下一段故事"
+	sims, err := router.Similarities(context.Background(), skills, msg, "")
+	if err != nil {
+		t.Fatalf("similarities: %v", err)
+	}
+
+	// Keyword scoring alone cannot match a paraphrase in another language.
+	if _, ok := routeSkill(skills, routeSkillInput{UserMessage: msg}); ok {
+		t.Fatalf("expected keyword-only routing to abstain")
+	}
+
+	got, ok := routeSkill(skills, routeSkillInput{UserMessage: msg, Semantic: sims})
+	if !ok {
+		t.Fatalf("expected semantic routing to pick a skill (sims=%v)", sims)
+	}
+	if got.Skill != "chapter-write" || got.Reason != "semantic_match" {
+		t.Fatalf("unexpected decision: %+v", got)
+	}
+	if !strings.Contains(got.Rationale, "semantic=") {
+		t.Fatalf("expected rationale to include semantic score, got %q", got.Rationale)
+	}
+
+	// Skill vectors come from the disk cache on the second call (only the query is embedded).
+	before := fake.calls
+	if _, err := router.Similarities(context.Background(), skills, msg, ""); err != nil {
+		t.Fatalf("similarities: %v", err)
+	}
+	if fake.calls-before != 1 {
+		t.Fatalf("expected cached skill vectors, got %d provider calls", fake.calls-before)
+	}
+}
+
+func TestExplainSkillRoute_LockedHoldsBackStrongCandidate(t *testing.T) {
+	skills := []api.SkillMeta{
+		{Name: "chapter-plan", Description: `Triggers on "规划10章"`},
+		{Name: "chapter-write", Description: `Triggers on "写第X章", "write chapter".`},
+	}
+
+	got := ExplainSkillRoute(skills, SkillRouteInput{
+		UserMessage: "先写第3章",
+		ActiveSkill: "chapter-plan",
+		Locked:      true,
+	})
+	if got.Changed || got.Skill != "chapter-plan" || got.Reason != "locked" {
+		t.Fatalf("unexpected explanation: %+v", got)
+	}
+	if len(got.Candidates) == 0 || got.Candidates[0].Skill != "chapter-write" {
+		t.Fatalf("expected chapter-write as top candidate, got %+v", got.Candidates)
+	}
+	if len(got.Candidates[0].Matched) == 0 || got.Candidates[0].Matched[0] != `"写第X章"` {
+		t.Fatalf("expected matched trigger, got %v", got.Candidates[0].Matched)
+	}
+	if !got.noteworthy() {
+		t.Fatalf("expected held-back candidate to be noteworthy")
+	}
+
+	got = ExplainSkillRoute(skills, SkillRouteInput{
+		UserMessage: "解锁技能，先写第3章",
+		ActiveSkill: "chapter-plan",
+		Locked:      true,
+	})
+	if !got.Changed || got.Skill != "chapter-write" || got.Source != "auto" {
+		t.Fatalf("expected unlock to allow switch, got %+v", got)
+	}
+	if !strings.Contains(got.String(), "switch chapter-plan → chapter-write") {
+		t.Fatalf("unexpected summary: %s", got.String())
+	}
+}
@@ -0,0 +1,207 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/logger"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Cancel & Steer
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// cancelWait bounds how long Engine.Cancel waits for the turn to wind down.
+const cancelWait = 10 * time.Second
+
+// canceledToolResult is recorded for tool calls that never ran because of a cancel.
+const canceledToolResult = "canceled by user"
+
+// startControl prepares cancel and steering for a new run. Callers hold r.mu.
+func (r *TurnRunner) startControl(ctx context.Context) context.Context {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.finished = make(chan struct{})
+	r.steering = nil
+	r.steerClosed = false
+	return ctx
+}
+
+// Cancel stops the running turn; it finishes with reason "canceled".
+func (r *TurnRunner) Cancel() {
+	r.mu.Lock()
+	cancel := r.cancel
+	r.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// Done is closed when the turn goroutine has returned (finished or suspended).
+func (r *TurnRunner) Done() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finished == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return r.finished
+}
+
+// Steer queues a user message for the running turn. It is added to the conversation
+// before the next LLM call; a turn that is about to finish keeps going to answer it.
+func (r *TurnRunner) Steer(message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	running := r.state == StateRunning || r.state == StateExecutingTool
+	if running && r.finished != nil {
+		select {
+		case <-r.finished: // suspended on an approval
+			running = false
+		default:
+		}
+	}
+	if !running || r.steerClosed {
+		return fmt.Errorf("%s: turn is not running", api.ErrNoActiveTurn)
+	}
+	r.steering = append(r.steering, message)
+	return nil
+}
+
+// hasSteering reports whether steering messages are queued. When none are, the turn
+// stops accepting them, so none can arrive after the final answer.
+func (r *TurnRunner) hasSteering() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.steering) == 0 {
+		r.steerClosed = true
+		return false
+	}
+	return true
+}
+
+// injectSteering appends queued steering messages to the session.
+func (r *TurnRunner) injectSteering(ctx context.Context) error {
+	r.mu.Lock()
+	msgs := r.steering
+	r.steering = nil
+	r.mu.Unlock()
+	if len(msgs) == 0 {
+		return nil
+	}
+	for _, msg := range msgs {
+		r.session.Messages = append(r.session.Messages, api.LLMMessage{Role: "user", Content: msg})
+		r.emit(ctx, api.Event{
+			Type:     api.EventThinking,
+			Thinking: &api.ThinkingPayload{Message: "↪️ Steering: " + msg},
+		})
+	}
+	return r.saveSession(ctx)
+}
+
+// emitCanceled records a consistent history for a canceled turn (tool calls that did
+// not run get a result) and finishes it.
+func (r *TurnRunner) emitCanceled(ctx context.Context) {
+	closeDanglingToolCalls(r.session, canceledToolResult)
+	if !r.cfg.KeepReasoning {
+		dropTurnReasoning(r.session.Messages)
+	}
+	r.emitDone(ctx, "canceled")
+}
+
+// closeDanglingToolCalls adds a tool result for every call of the last assistant
+// message that has none, so the history stays valid for chat APIs.
+func closeDanglingToolCalls(session *api.Session, reason string) {
+	last := -1
+	for i := len(session.Messages) - 1; i >= 0; i-- {
+		if m := session.Messages[i]; m.Role == "assistant" && len(m.ToolCalls) > 0 {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return
+	}
+	answered := make(map[string]bool)
+	for _, m := range session.Messages[last+1:] {
+		if m.Role == "tool" {
+			answered[m.ToolCallID] = true
+		}
+	}
+	for _, tc := range session.Messages[last].ToolCalls {
+		if !answered[tc.ID] {
+			session.Messages = append(session.Messages, api.LLMMessage{
+				Role:       "tool",
+				Content:    "Skipped: " + reason,
+				ToolCallID: tc.ID,
+			})
+		}
+	}
+}
+
+// Cancel stops the session's running turn, waits for it to record its partial output
+// and finish with reason "canceled", and releases the session for the next turn. A turn
+// suspended on an approval is canceled by dropping the approval.
+func (e *Engine) Cancel(sessionID string) error {
+	e.turnsMu.Lock()
+	runner := e.activeTurns[sessionID]
+	e.turnsMu.Unlock()
+
+	if runner != nil {
+		runner.Cancel()
+		select {
+		case <-runner.Done():
+		case <-time.After(cancelWait):
+			logger.Warn("Engine", "Canceled turn did not finish in time", map[string]interface{}{
+				"session_id": sessionID,
+			})
+		}
+		e.releaseTurn(sessionID, runner)
+	}
+
+	ctx := context.Background()
+	lease, err := e.lockSession(sessionID)
+	if err != nil {
+		return err
+	}
+	defer releaseLease(lease)
+	session, err := e.sessionStore.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("%s: %s", api.ErrInvalidSession, sessionID)
+	}
+	if session.Pending == nil {
+		if runner == nil {
+			return fmt.Errorf("%s: %s", api.ErrNoActiveTurn, sessionID)
+		}
+		return nil
+	}
+	closeDanglingToolCalls(session, canceledToolResult)
+	session.Pending = nil
+	session.UpdatedAt = time.Now()
+	return e.sessionStore.Put(ctx, sessionID, session)
+}
+
+// Steer adds a user message to the session's running turn (see TurnRunner.Steer).
+func (e *Engine) Steer(sessionID, message string) error {
+	e.turnsMu.Lock()
+	runner := e.activeTurns[sessionID]
+	e.turnsMu.Unlock()
+	if runner == nil {
+		return fmt.Errorf("%s: %s", api.ErrNoActiveTurn, sessionID)
+	}
+	return runner.Steer(message)
+}
+
+// releaseTurn removes runner from the active turns, and releases the session lock,
+// unless a newer turn replaced it.
+func (e *Engine) releaseTurn(sessionID string, runner *TurnRunner) {
+	e.turnsMu.Lock()
+	defer e.turnsMu.Unlock()
+	if e.activeTurns[sessionID] == runner {
+		delete(e.activeTurns, sessionID)
+		releaseLease(e.leases[sessionID])
+		delete(e.leases, sessionID)
+	}
+}
@@ -0,0 +1,135 @@
+package runtime
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/policy"
+	"AgentEngine/pkg/engine/tools"
+)
+
+// blockingStream sends partial text, then blocks until the turn is canceled.
+type blockingStream struct {
+	sent    bool
+	started chan struct{}
+}
+
+func (s *blockingStream) Recv(ctx context.Context) (LLMChunk, error) {
+	if !s.sent {
+		s.sent = true
+		return LLMChunk{Delta: "Half an ans"}, nil
+	}
+	close(s.started)
+	<-ctx.Done()
+	return LLMChunk{}, ctx.Err()
+}
+
+func (s *blockingStream) Close() error { return nil }
+
+// controlLLM blocks on its first request; onFirst runs while the first request is open.
+type controlLLM struct {
+	mu       sync.Mutex
+	requests []LLMRequest
+	block    bool
+	started  chan struct{}
+	onFirst  func()
+}
+
+func (l *controlLLM) Stream(ctx context.Context, req LLMRequest) (LLMStream, error) {
+	l.mu.Lock()
+	l.requests = append(l.requests, req)
+	n := len(l.requests)
+	l.mu.Unlock()
+	if n == 1 {
+		if l.block {
+			return &blockingStream{started: l.started}, nil
+		}
+		if l.onFirst != nil {
+			l.onFirst()
+		}
+	}
+	return &staticStream{content: "ok"}, nil
+}
+
+func newControlEngine(t *testing.T, llm LLM) (*Engine, string) {
+	t.Helper()
+	eng, err := NewEngine(EngineConfig{
+		LLM:           llm,
+		Tools:         tools.NewRegistry(),
+		Policy:        policy.NewDefaultPolicy(),
+		WorkspaceRoot: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	sid, err := eng.StartSession(context.Background(), api.StartOptions{ApprovalMode: api.ModeFullAuto})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	return eng, sid
+}
+
+func TestEngine_CancelKeepsPartialOutputAndReleasesTurn(t *testing.T) {
+	llm := &controlLLM{block: true, started: make(chan struct{})}
+	eng, sid := newControlEngine(t, llm)
+	ctx := context.Background()
+
+	stream, err := eng.Send(ctx, sid, "explain")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	done := make(chan []api.Event)
+	go func() { done <- collectEvents(t, stream) }()
+
+	<-llm.started
+	if err := eng.Cancel(sid); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	events := <-done
+	if last := events[len(events)-1]; last.Type != api.EventDone || last.Done.Reason != "canceled" {
+		t.Fatalf("expected canceled done event, got %+v", last)
+	}
+
+	sess, _ := eng.sessionStore.Get(ctx, sid)
+	if last := sess.Messages[len(sess.Messages)-1]; last.Role != "assistant" || last.Content != "Half an ans" {
+		t.Fatalf("expected partial answer saved, got %+v", last)
+	}
+	if err := eng.Cancel(sid); err == nil {
+		t.Fatal("expected no active turn after cancel")
+	}
+	stream, err = eng.Send(ctx, sid, "again")
+	if err != nil {
+		t.Fatalf("expected the session to accept a new turn: %v", err)
+	}
+	collectEvents(t, stream)
+}
+
+func TestEngine_SteerInjectsMessageBeforeNextLLMCall(t *testing.T) {
+	llm := &controlLLM{}
+	eng, sid := newControlEngine(t, llm)
+	var steerErr error
+	llm.onFirst = func() { steerErr = eng.Steer(sid, "also mention tests") }
+
+	stream, err := eng.Send(context.Background(), sid, "summarize")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	collectEvents(t, stream)
+	if steerErr != nil {
+		t.Fatalf("steer: %v", steerErr)
+	}
+
+	// The first answer would have ended the turn; the steering message gets a second call.
+	if len(llm.requests) != 2 {
+		t.Fatalf("expected 2 LLM calls, got %d", len(llm.requests))
+	}
+	msgs := llm.requests[1].Messages
+	if last := msgs[len(msgs)-1]; last.Role != "user" || last.Content != "also mention tests" {
+		t.Fatalf("expected steering message last, got %+v", last)
+	}
+	if err := eng.Steer(sid, "late"); err == nil {
+		t.Fatal("expected steering a finished turn to fail")
+	}
+}
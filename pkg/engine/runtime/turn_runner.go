@@ -61,9 +61,18 @@ type LLMStream interface {
 // LLMChunk is a chunk of streaming LLM response.
 type LLMChunk struct {
 	Delta        string           // Text content delta
+	Reasoning    string           // Reasoning ("thinking") delta from reasoning models; precedes Delta
 	ToolArgDelta string           // Tool argument delta (for streaming display)
 	ToolCall     *api.LLMToolCall // Complete tool call (when finish_reason=tool_calls)
 	FinishReason string
+	Usage        *LLMUsage // Provider-reported usage, usually on the finish chunk (optional)
+}
+
+// LLMUsage is the token usage reported by the provider for one request.
+type LLMUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	CachedTokens     int // prompt tokens served from the provider's cache
 }
 
 // Tool is the unified executable tool interface used by the runtime.
@@ -109,6 +118,31 @@ type TurnRunnerConfig struct {
 	// Message filtering: if true, filter out historical tool_calls/tool messages
 	// before sending to LLM (keep only current turn's tool interactions)
 	FilterHistoryTools bool
+
+	// KeepReasoning persists model reasoning on assistant messages. Otherwise it is kept
+	// only until the turn completes, for providers that need it back during tool loops.
+	KeepReasoning bool
+
+	// HookRunner executes active-skill lifecycle hooks (optional).
+	HookRunner SkillHookRunner
+
+	// SemanticRouter adds embedding similarity to auto skill routing (optional).
+	SemanticRouter *SemanticSkillRouter
+
+	// Models resolves per-role models and skill model requests (optional);
+	// LLM and Model are the main model.
+	Models *ModelSet
+
+	// Guards against runaway turns. Tokens come from provider usage when reported and
+	// are estimated otherwise; cost uses Prices for Model (unknown models cost 0).
+	Model         string
+	Prices        PriceTable
+	Limits        Limits // per turn, including work after approvals
+	SessionLimits Limits // cumulative across the session's turns
+
+	// LoopThreshold ends the turn when the same tool is called with identical
+	// arguments this many times in a row (0 = disabled).
+	LoopThreshold int
 }
 
 // TurnRunner executes a single turn of conversation.
@@ -120,7 +154,7 @@ type TurnRunner struct {
 	session   *api.Session
 	turnID    string
 	seq       int64
-	events    *store.ChannelEventStream
+	events    *store.EventHub
 	startedAt time.Time
 
 	// Tracking
@@ -131,6 +165,21 @@ type TurnRunner struct {
 	turnError     *api.ErrorPayload
 	hookState     *api.State
 
+	// model is the model of the latest LLM request, for pricing and usage records.
+	model string
+
+	// Cancel and steering (see steer.go)
+	cancel      context.CancelFunc
+	finished    chan struct{} // closed when the turn goroutine returns
+	steering    []string      // user messages to inject before the next LLM call
+	steerClosed bool          // the turn is finishing; no more steering
+
+	// Limits
+	usage       api.Usage
+	lastTick    time.Time
+	lastToolSig string
+	toolRepeats int
+
 	mu sync.Mutex
 }
 
@@ -139,7 +188,8 @@ func NewTurnRunner(cfg TurnRunnerConfig) *TurnRunner {
 	return &TurnRunner{
 		cfg:    cfg,
 		state:  StateIdle,
-		events: store.NewChannelEventStream(100),
+		model:  cfg.Model,
+		events: store.NewEventHub(),
 	}
 }
 
@@ -147,8 +197,8 @@ func NewTurnRunner(cfg TurnRunnerConfig) *TurnRunner {
 // Public API
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 
-// Run starts a new turn with a user message.
-func (r *TurnRunner) Run(ctx context.Context, session *api.Session, message string) (api.EventStream, error) {
+// Run starts a new turn with a user message and optional attachments.
+func (r *TurnRunner) Run(ctx context.Context, session *api.Session, message string, parts ...api.ContentPart) (api.EventStream, error) {
 	r.mu.Lock()
 	if r.state != StateIdle {
 		r.mu.Unlock()
@@ -159,12 +209,19 @@ func (r *TurnRunner) Run(ctx context.Context, session *api.Session, message stri
 	r.turnID = generateTurnID()
 	r.seq = 0
 	r.startedAt = time.Now()
+	r.usage = api.Usage{}
+	r.lastTick = r.startedAt
+	ctx = r.startControl(ctx)
+	stream := r.events.Subscribe(0)
 	r.mu.Unlock()
 
 	// Run the turn in background
-	go r.runTurn(ctx, message)
+	go func() {
+		defer close(r.finished)
+		r.runTurn(ctx, message, parts)
+	}()
 
-	return r.events, nil
+	return stream, nil
 }
 
 // Resume continues a turn from pending approval.
@@ -176,34 +233,49 @@ func (r *TurnRunner) Resume(ctx context.Context, session *api.Session, decision
 	}
 
 	// Validate decision matches pending
-	if decision.RequestID != session.Pending.RequestID {
-		r.mu.Unlock()
-		return nil, fmt.Errorf("%s: request ID mismatch", api.ErrApprovalMismatch)
-	}
-	if decision.ToolCallID != "" && decision.ToolCallID != session.Pending.ToolCall.ToolCallID {
+	if err := checkDecision(decision, session.Pending); err != nil {
 		r.mu.Unlock()
-		return nil, fmt.Errorf("%s: tool call ID mismatch", api.ErrApprovalMismatch)
+		return nil, err
 	}
 
 	r.state = StateExecutingTool
 	r.session = session
 	r.turnID = session.Pending.TurnID // Continue the same turn
-	r.mu.Unlock()
+	r.usage = api.Usage{}
+	if session.Pending.Usage != nil {
+		r.usage = *session.Pending.Usage
+	}
+	r.seq = session.Pending.Seq // Keep event sequence numbers increasing within the turn
+	r.lastTick = time.Now()
+	ctx = r.startControl(ctx)
 
 	// Reset event stream for resume
-	r.events = store.NewChannelEventStream(100)
+	r.events = store.NewEventHub()
+	stream := r.events.Subscribe(0)
+	r.mu.Unlock()
 
 	// Run resume in background
-	go r.resumeTurn(ctx, decision)
+	go func() {
+		defer close(r.finished)
+		r.resumeTurn(ctx, decision)
+	}()
+
+	return stream, nil
+}
 
-	return r.events, nil
+// Subscribe adds an event subscriber to the running turn and returns it with the
+// turn's ID. It sees events emitted from now on.
+func (r *TurnRunner) Subscribe(buffer int) (api.EventStream, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events.Subscribe(buffer), r.turnID
 }
 
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // Internal Execution
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 
-func (r *TurnRunner) runTurn(ctx context.Context, message string) {
+func (r *TurnRunner) runTurn(ctx context.Context, message string, parts []api.ContentPart) {
 	defer r.events.Close()
 	defer r.finalize(ctx)
 
@@ -222,7 +294,7 @@ func (r *TurnRunner) runTurn(ctx context.Context, message string) {
 	}
 
 	// Append user message
-	userMsg := api.LLMMessage{Role: "user", Content: message}
+	userMsg := api.LLMMessage{Role: "user", Content: message, Parts: parts}
 	r.session.Messages = append(r.session.Messages, userMsg)
 
 	// Auto-compress if threshold exceeded
@@ -240,7 +312,8 @@ func (r *TurnRunner) runTurn(ctx context.Context, message string) {
 			Type:     api.EventThinking,
 			Thinking: &api.ThinkingPayload{Message: "🔄 Auto-compressing conversation history..."},
 		})
-		if err := CompressHistory(ctx, r.cfg.LLM, r.session, CompressConfig{KeepTurns: keepTurns}); err != nil {
+		summarizer, _ := r.roleLLM(RoleSummarizer)
+		if err := CompressHistory(ctx, summarizer, r.session, CompressConfig{KeepTurns: keepTurns}); err != nil {
 			logger.Warn("Compress", "Auto-compression failed", map[string]interface{}{
 				"error": err.Error(),
 			})
@@ -262,6 +335,7 @@ func (r *TurnRunner) runTurn(ctx context.Context, message string) {
 		ActiveSkill: r.session.ActiveSkill,
 		Messages:    append([]api.LLMMessage(nil), r.session.Messages...),
 		Metadata:    make(map[string]any),
+		Emit:        r.emit,
 	}
 	// Inject session summary for middleware to use
 	if r.session.Summary != "" {
@@ -273,7 +347,12 @@ func (r *TurnRunner) runTurn(ctx context.Context, message string) {
 	outcome, err := r.agentLoop(ctx, state)
 	if err != nil {
 		if errorsIsContextCanceled(err) {
-			r.emitDone(ctx, "canceled")
+			r.emitCanceled(ctx)
+			return
+		}
+		var le *limitError
+		if errors.As(err, &le) {
+			r.emitLimit(ctx, le)
 			return
 		}
 		r.emitError(ctx, api.ErrToolExecuteFailed, err.Error())
@@ -283,6 +362,7 @@ func (r *TurnRunner) runTurn(ctx context.Context, message string) {
 	if outcome == loopOutcomeSuspended {
 		return
 	}
+	r.runTurnEndHooks(ctx)
 	r.emitDone(ctx, "completed")
 }
 
@@ -298,8 +378,9 @@ func (r *TurnRunner) resumeTurn(ctx context.Context, decision api.Decision) {
 
 	pending := r.session.Pending
 
-	if decision.Kind == api.DecisionReject {
+	if rejectsAll(decision, pending) {
 		// Clear pending and emit done
+		closeDanglingToolCalls(r.session, rejectedToolResult)
 		r.session.Pending = nil
 		if err := r.saveSession(ctx); err != nil {
 			r.emitError(ctx, api.ErrStoreError, err.Error())
@@ -309,13 +390,6 @@ func (r *TurnRunner) resumeTurn(ctx context.Context, decision api.Decision) {
 		return
 	}
 
-	// Get tool and args
-	args := pending.ToolCall.Args
-	if decision.Kind == api.DecisionModify && decision.ModifiedArgs != nil {
-		args = decision.ModifiedArgs
-	}
-	execArgs := r.prepareExecArgs(pending.ToolCall.ToolName, args)
-
 	// Build state and run middlewares (to enforce allowed-tools and inject system prompt).
 	state := &api.State{
 		SessionID:   r.session.SessionID,
@@ -323,6 +397,7 @@ func (r *TurnRunner) resumeTurn(ctx context.Context, decision api.Decision) {
 		ActiveSkill: r.session.ActiveSkill,
 		Messages:    append([]api.LLMMessage(nil), r.session.Messages...),
 		Metadata:    make(map[string]any),
+		Emit:        r.emit,
 	}
 	r.hookState = state
 	if err := r.refreshState(ctx, state); err != nil {
@@ -330,14 +405,6 @@ func (r *TurnRunner) resumeTurn(ctx context.Context, decision api.Decision) {
 		return
 	}
 
-	// Execute tool
-	tool, ok := r.cfg.Tools.Get(pending.ToolCall.ToolName)
-	if !ok {
-		r.emitError(ctx, api.ErrToolNotFound, pending.ToolCall.ToolName)
-		return
-	}
-
-	// Validate before execution (modified args may be denied or require re-approval).
 	pctx := api.PolicyContext{
 		SessionID:      r.session.SessionID,
 		TurnID:         r.turnID,
@@ -347,54 +414,65 @@ func (r *TurnRunner) resumeTurn(ctx context.Context, decision api.Decision) {
 		ToolCallOrigin: api.OriginModel,
 	}
 
-	if err := r.cfg.Policy.Validate(ctx, pctx, tool, execArgs); err != nil {
-		r.emit(ctx, api.Event{
-			Type: api.EventToolResult,
-			ToolResult: &api.ToolResultPayload{
-				ToolCallID: pending.ToolCall.ToolCallID,
-				ToolName:   pending.ToolCall.ToolName,
-				Result:     api.ToolResult{Status: "error", Error: err.Error()},
-			},
-		})
-		r.session.Pending = nil
-		_ = r.saveSession(ctx)
-		r.emitDone(ctx, "completed")
-		return
-	}
-
-	// Note: We don't re-check NeedApproval here because the user has already
-	// approved this tool call. Re-checking would cause an infinite loop since
-	// tools like 'shell' always require approval in auto mode.
+	// Run the queued calls in order; rejected ones are recorded as skipped.
+	for _, call := range pending.Calls() {
+		if ctx.Err() != nil {
+			r.emitCanceled(ctx)
+			return
+		}
+		args := call.Args
+		var item api.ItemDecision
+		if call.NeedApproval {
+			item = decisionFor(decision, pending, call.ToolCallID)
+			if item.Kind == api.DecisionReject {
+				r.skipToolCall(ctx, call, rejectedToolResult)
+				continue
+			}
+			if item.Kind == api.DecisionModify && item.ModifiedArgs != nil {
+				args = item.ModifiedArgs
+			}
+		}
+		modified := item.Kind == api.DecisionModify && item.ModifiedArgs != nil
+		execArgs := r.prepareExecArgs(call.ToolName, args)
 
-	result, err := tool.Execute(ctx, execArgs)
-	if err != nil {
-		result = api.ToolResult{Status: "error", Error: err.Error()}
-	}
+		tool, ok := r.cfg.Tools.Get(call.ToolName)
+		if !ok {
+			r.skipToolCall(ctx, call, "tool not found")
+			continue
+		}
+		if modified {
+			if err := tools.ValidateArgs(tool.Schema(), args); err != nil {
+				r.skipToolCall(ctx, call, fmt.Sprintf("%s: %v", api.ErrToolArgsInvalid, err))
+				continue
+			}
+		}
 
-	// Apply engine-side effects for certain system tools.
-	if pending.ToolCall.ToolName == "activate_skill" && result.Status == "success" {
-		if name, ok := args["name"].(string); ok && name != "" {
-			r.session.ActiveSkill = name
+		// Validate before execution (modified args may be denied).
+		if err := r.cfg.Policy.Validate(ctx, pctx, tool, execArgs); err != nil {
+			r.skipToolCall(ctx, call, err.Error())
+			continue
 		}
-	}
 
-	// Emit tool result
-	r.emit(ctx, api.Event{
-		Type: api.EventToolResult,
-		ToolResult: &api.ToolResultPayload{
-			ToolCallID: pending.ToolCall.ToolCallID,
-			ToolName:   pending.ToolCall.ToolName,
-			Result:     result,
-		},
-	})
+		// Note: We don't re-check NeedApproval here because the user has already
+		// approved this tool call. Re-checking would cause an infinite loop since
+		// tools like 'shell' always require approval in auto mode.
+		r.runToolCall(ctx, tool, call, args, execArgs)
+		if modified {
+			r.noteModifiedArgs(call, args, item.Note)
+		}
+		if err := r.saveSession(ctx); err != nil {
+			r.emitError(ctx, api.ErrStoreError, err.Error())
+			return
+		}
 
-	// Append tool message
-	toolMsg := api.LLMMessage{
-		Role:       "tool",
-		Content:    result.Content,
-		ToolCallID: pending.ToolCall.ToolCallID,
+		// Check for plan update
+		if call.ToolName == "write_todos" {
+			if err := r.emitPlanSnapshot(ctx, call.ToolCallID); err != nil {
+				r.emitError(ctx, api.ErrStoreError, err.Error())
+				return
+			}
+		}
 	}
-	r.session.Messages = append(r.session.Messages, toolMsg)
 
 	// Clear pending
 	stopAfter := pending.StopAfter
@@ -409,19 +487,16 @@ func (r *TurnRunner) resumeTurn(ctx context.Context, decision api.Decision) {
 		return
 	}
 
-	// Check for plan update
-	if pending.ToolCall.ToolName == "write_todos" {
-		if err := r.emitPlanSnapshot(ctx, pending.ToolCall.ToolCallID); err != nil {
-			r.emitError(ctx, api.ErrStoreError, err.Error())
-			return
-		}
-	}
-
 	// Continue agent loop
 	outcome, err := r.agentLoop(ctx, state)
 	if err != nil {
 		if errorsIsContextCanceled(err) {
-			r.emitDone(ctx, "canceled")
+			r.emitCanceled(ctx)
+			return
+		}
+		var le *limitError
+		if errors.As(err, &le) {
+			r.emitLimit(ctx, le)
 			return
 		}
 		r.emitError(ctx, api.ErrToolExecuteFailed, err.Error())
@@ -431,6 +506,7 @@ func (r *TurnRunner) resumeTurn(ctx context.Context, decision api.Decision) {
 	if outcome == loopOutcomeSuspended {
 		return
 	}
+	r.runTurnEndHooks(ctx)
 	r.emitDone(ctx, "completed")
 }
 
@@ -449,6 +525,18 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 		default:
 		}
 
+		if le := r.checkLimits(false); le != nil {
+			return loopOutcomeCompleted, le
+		}
+
+		// Messages typed while the agent was working go in before the next LLM call.
+		if err := r.injectSteering(ctx); err != nil {
+			return loopOutcomeCompleted, err
+		}
+
+		// Run on-activate hooks if the active skill changed since the last call.
+		r.maybeRunActivateHooks(ctx)
+
 		// Refresh turn state (skill/memory/plan injection, allowed-tools).
 		if err := r.refreshState(ctx, state); err != nil {
 			return loopOutcomeCompleted, err
@@ -486,29 +574,51 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 			messages = filterHistoryToolMessages(messages)
 		}
 		req := LLMRequest{
-			Messages: buildRequestMessages(state.SystemPrompt, messages),
+			Messages: buildRequestMessages(state.SystemPrompt+r.takeSkillHookNotes(), messages),
 			Tools:    toolSchemas,
 		}
 
-		// Stream LLM response
-		stream, err := r.cfg.LLM.Stream(ctx, req)
+		// Stream LLM response; retries and failovers (ResilientLLM) show up as thinking events.
+		llmCtx := withLLMNotice(ctx, func(msg string) {
+			r.emit(ctx, api.Event{Type: api.EventThinking, Thinking: &api.ThinkingPayload{Message: msg}})
+		})
+		var llm LLM
+		llm, r.model = r.turnLLM()
+		stream, err := llm.Stream(llmCtx, req)
 		if err != nil {
 			return loopOutcomeCompleted, fmt.Errorf("LLM stream error: %w", err)
 		}
 
-		var assistantContent string
+		var assistantContent, reasoning string
 		var toolCalls []api.LLMToolCall
+		var usage *LLMUsage
 
 		for {
-			chunk, err := stream.Recv(ctx)
+			chunk, err := stream.Recv(llmCtx)
 			if err != nil {
 				stream.Close()
 				if err == io.EOF {
 					break
 				}
+				if errorsIsContextCanceled(err) && assistantContent != "" {
+					// Keep what was streamed before the cancel.
+					r.session.Messages = append(r.session.Messages, api.LLMMessage{
+						Role:      "assistant",
+						Content:   assistantContent,
+						Reasoning: reasoning,
+					})
+				}
 				return loopOutcomeCompleted, fmt.Errorf("LLM recv error: %w", err)
 			}
 
+			if chunk.Reasoning != "" {
+				reasoning += chunk.Reasoning
+				r.emit(ctx, api.Event{
+					Type:  api.EventDelta,
+					Delta: &api.DeltaPayload{Text: chunk.Reasoning, Source: api.DeltaReasoning},
+				})
+			}
+
 			if chunk.Delta != "" {
 				assistantContent += chunk.Delta
 				r.emit(ctx, api.Event{
@@ -528,27 +638,47 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 			if chunk.ToolCall != nil {
 				toolCalls = append(toolCalls, *chunk.ToolCall)
 			}
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
 
 			if chunk.FinishReason != "" {
 				break
 			}
 		}
 		stream.Close()
+		if s, ok := stream.(servedStream); ok {
+			// Price the call under the model that answered, not the one asked first.
+			r.model = s.ServedBy()
+		}
+		r.recordLLMCall(req, reasoning+assistantContent, toolCalls, usage)
 
 		// No tool calls - turn complete
 		if len(toolCalls) == 0 {
 			// Save assistant message
-			if assistantContent != "" {
+			changed := assistantContent != ""
+			if changed {
 				r.session.Messages = append(r.session.Messages, api.LLMMessage{
-					Role:    "assistant",
-					Content: assistantContent,
+					Role:      "assistant",
+					Content:   assistantContent,
+					Reasoning: reasoning,
 				})
+			}
+			if !r.cfg.KeepReasoning && dropTurnReasoning(r.session.Messages) {
+				changed = true
+			}
+			if changed {
 				if err := r.saveSession(ctx); err != nil {
 					return loopOutcomeCompleted, err
 				}
 			}
 			r.assistantText = assistantContent
 
+			// A steering message arrived while the model answered: keep going.
+			if r.hasSteering() {
+				continue
+			}
+
 			// Skill-driven auto-persist (best-effort): if the active skill requires
 			// saving output and the model didn't call tools, propose a write.
 			if outcome, did, err := r.maybeAutoSaveSkillOutput(ctx, state, lastUserMessage(state.Messages), assistantContent); did {
@@ -562,6 +692,7 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 		assistantMsg := api.LLMMessage{
 			Role:      "assistant",
 			Content:   assistantContent,
+			Reasoning: reasoning,
 			ToolCalls: toolCalls,
 		}
 		r.session.Messages = append(r.session.Messages, assistantMsg)
@@ -570,7 +701,8 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 		}
 
 		// Process tool calls
-		for _, tc := range toolCalls {
+		var batch []api.ToolCallPayload
+		for i, tc := range toolCalls {
 			// Parse args (must be valid JSON).
 			var args api.Args
 			if strings.TrimSpace(tc.Args) != "" {
@@ -589,6 +721,22 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 				args = make(api.Args)
 			}
 
+			le := r.checkLimits(true)
+			if le == nil {
+				le = r.detectToolLoop(tc.Name, args)
+			}
+			if le != nil {
+				// Calls queued for approval come first and need a result too.
+				for _, call := range batch {
+					r.skipToolCall(ctx, call, le.message)
+				}
+				r.skipToolCalls(toolCalls[i:], le.message)
+				if err := r.saveSession(ctx); err != nil {
+					return loopOutcomeCompleted, err
+				}
+				return loopOutcomeCompleted, le
+			}
+
 			toolCall := api.ToolCallPayload{
 				ToolCallID: tc.ID,
 				ToolName:   tc.Name,
@@ -643,63 +791,15 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 				continue
 			}
 
-			// Check approval
-			if needApproval {
-				requestID := generateRequestID()
-				// Emit approval and suspend
-				r.emit(ctx, api.Event{
-					Type: api.EventApproval,
-					Approval: &api.ApprovalPayload{
-						RequestID:  requestID,
-						ToolCallID: tc.ID,
-						ToolCall:   toolCall,
-						Mode:       r.cfg.ApprovalMode,
-					},
-				})
-
-				// Save pending state
-				r.session.Pending = &api.PendingApproval{
-					TurnID:    r.turnID,
-					RequestID: requestID,
-					ToolCall:  toolCall,
-					Preview:   preview,
-					CreatedAt: time.Now(),
-				}
-				if err := r.saveSession(ctx); err != nil {
-					return loopOutcomeCompleted, err
-				}
-
-				return loopOutcomeSuspended, nil // Suspend - wait for Resume
+			// From the first call needing approval on, calls are queued for one
+			// batch approval so they still run in order.
+			if needApproval || batch != nil {
+				batch = append(batch, toolCall)
+				continue
 			}
 
 			// Execute tool
-			result, err := tool.Execute(ctx, execArgs)
-			if err != nil {
-				result = api.ToolResult{Status: "error", Error: err.Error()}
-			}
-
-			// Apply engine-side effects for certain system tools.
-			if tc.Name == "activate_skill" && result.Status == "success" {
-				if name, ok := args["name"].(string); ok && name != "" {
-					r.session.ActiveSkill = name
-				}
-			}
-
-			r.emit(ctx, api.Event{
-				Type: api.EventToolResult,
-				ToolResult: &api.ToolResultPayload{
-					ToolCallID: tc.ID,
-					ToolName:   tc.Name,
-					Result:     result,
-				},
-			})
-
-			// Add to messages
-			r.session.Messages = append(r.session.Messages, api.LLMMessage{
-				Role:       "tool",
-				Content:    result.Content,
-				ToolCallID: tc.ID,
-			})
+			r.runToolCall(ctx, tool, toolCall, args, execArgs)
 			if err := r.saveSession(ctx); err != nil {
 				return loopOutcomeCompleted, err
 			}
@@ -709,6 +809,13 @@ func (r *TurnRunner) agentLoop(ctx context.Context, state *api.State) (loopOutco
 				_ = r.emitPlanSnapshot(ctx, tc.ID)
 			}
 		}
+
+		if batch != nil {
+			if err := r.suspendForApproval(ctx, batch); err != nil {
+				return loopOutcomeCompleted, err
+			}
+			return loopOutcomeSuspended, nil // Suspend - wait for Resume
+		}
 	}
 }
 
@@ -726,7 +833,7 @@ func (r *TurnRunner) emit(ctx context.Context, e api.Event) {
 	e.Ts = time.Now()
 	r.mu.Unlock()
 
-	r.events.Send(e)
+	r.events.Publish(e)
 
 	// Log event
 	if r.cfg.EventLog != nil {
@@ -743,6 +850,21 @@ func (r *TurnRunner) emit(ctx context.Context, e api.Event) {
 		if e.Approval != nil {
 			r.approvals = append(r.approvals, api.ApprovalRef{RequestID: e.Approval.RequestID, ToolCallID: e.Approval.ToolCallID})
 		}
+	case api.EventToolResult:
+		if e.ToolResult != nil {
+			r.trackPlanEvidence(*e.ToolResult)
+		}
+	case api.EventMemoryProposal:
+		// Keep the latest proposal on the session so clients can review it later.
+		if e.MemoryProposal != nil {
+			r.session.PendingMemory = e.MemoryProposal
+			if err := r.saveSession(ctx); err != nil {
+				logger.Warn("Memory", "Failed to save memory proposal", map[string]interface{}{
+					"session_id": r.session.SessionID,
+					"error":      err.Error(),
+				})
+			}
+		}
 	}
 
 	// Middleware event hook (best-effort, must not block the main loop).
@@ -762,17 +884,23 @@ func (r *TurnRunner) emitError(ctx context.Context, code, message string) {
 }
 
 func (r *TurnRunner) emitDone(ctx context.Context, reason string) {
-	switch reason {
-	case "canceled":
+	switch {
+	case reason == "canceled":
 		r.turnOutcome = api.TurnCanceled
-	case "error":
+	case reason == "error" || api.IsLimitCode(reason):
 		r.turnOutcome = api.TurnError
 	default:
 		r.turnOutcome = api.TurnDone
 	}
+	// Persist usage recorded since the last save (best-effort).
+	r.tick()
+	_ = r.saveSession(ctx)
+	usage := r.usage
+	// Run AfterTurn before Done so middleware events (e.g. memory proposals) reach the stream.
+	r.finalize(ctx)
 	r.emit(ctx, api.Event{
 		Type: api.EventDone,
-		Done: &api.DonePayload{Reason: reason},
+		Done: &api.DonePayload{Reason: reason, Usage: &usage},
 	})
 	r.mu.Lock()
 	r.state = StateCompleted
@@ -921,11 +1049,16 @@ func (r *TurnRunner) prepareExecArgs(toolName string, args api.Args) api.Args {
 	// Keep args stable for UI/events by injecting into the execution args only.
 	switch toolName {
 	case "read_todos", "write_todos":
-		out := make(api.Args, len(args)+1)
+		out := make(api.Args, len(args)+2)
 		for k, v := range args {
 			out[k] = v
 		}
 		out["session_id"] = r.session.SessionID
+		if toolName == "write_todos" {
+			if ev := r.planEvidence(); len(ev) > 0 {
+				out["_evidence"] = ev
+			}
+		}
 		return out
 	case "run_skill_script":
 		// Inject active skill for validation and path resolution.
@@ -940,6 +1073,43 @@ func (r *TurnRunner) prepareExecArgs(toolName string, args api.Args) api.Args {
 	}
 }
 
+// planEvidenceKey holds tool call IDs executed since the last plan update (comma-separated).
+// write_todos links them to the items it marks done.
+const planEvidenceKey = "plan_evidence"
+
+func (r *TurnRunner) trackPlanEvidence(res api.ToolResultPayload) {
+	if res.Result.Status != "success" {
+		return
+	}
+	switch res.ToolName {
+	case "read_todos":
+		return
+	case "write_todos":
+		if r.session.Metadata != nil {
+			delete(r.session.Metadata, planEvidenceKey)
+		}
+		return
+	}
+	if res.ToolCallID == "" {
+		return
+	}
+	if r.session.Metadata == nil {
+		r.session.Metadata = make(map[string]string)
+	}
+	if prev := r.session.Metadata[planEvidenceKey]; prev != "" {
+		r.session.Metadata[planEvidenceKey] = prev + "," + res.ToolCallID
+	} else {
+		r.session.Metadata[planEvidenceKey] = res.ToolCallID
+	}
+}
+
+func (r *TurnRunner) planEvidence() []string {
+	if r.session.Metadata == nil || r.session.Metadata[planEvidenceKey] == "" {
+		return nil
+	}
+	return strings.Split(r.session.Metadata[planEvidenceKey], ",")
+}
+
 func (r *TurnRunner) refreshState(ctx context.Context, state *api.State) error {
 	if state == nil {
 		return nil
@@ -974,6 +1144,7 @@ func (r *TurnRunner) finalize(ctx context.Context) {
 		TurnID:        r.turnID,
 		Outcome:       r.turnOutcome,
 		AssistantText: r.assistantText,
+		Continuation:  isContinuation(ctx),
 		ToolCalls:     append([]api.ToolCallRef(nil), r.toolCalls...),
 		Approvals:     append([]api.ApprovalRef(nil), r.approvals...),
 		Error:         r.turnError,
@@ -981,6 +1152,10 @@ func (r *TurnRunner) finalize(ctx context.Context) {
 		FinishedAt:    time.Now(),
 	}
 
+	if r.hookState != nil {
+		r.hookState.Messages = append([]api.LLMMessage(nil), r.session.Messages...)
+	}
+
 	// AfterTurn runs in reverse order (as specified by mw.Chain), but the runtime stores middlewares as a slice.
 	for i := len(r.cfg.Middlewares) - 1; i >= 0; i-- {
 		_ = r.cfg.Middlewares[i].AfterTurn(ctx, r.hookState, summary)
@@ -0,0 +1,181 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"AgentEngine/pkg/engine/api"
+	"AgentEngine/pkg/engine/store"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Usage Accounting
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// Session metadata keys: cumulative api.Usage (JSON), and usage split by
+// day, skill and model (JSON map keyed "day|skill|model").
+const (
+	sessionUsageKey        = "usage"
+	sessionUsageBucketsKey = "usage_buckets"
+)
+
+// recordLLMCall adds one LLM request to turn and session usage. Provider-reported
+// usage wins; otherwise tokens are estimated from the request and response.
+func (r *TurnRunner) recordLLMCall(req LLMRequest, completion string, toolCalls []api.LLMToolCall, reported *LLMUsage) {
+	u := api.Usage{LLMCalls: 1}
+	if reported != nil {
+		u.PromptTokens = reported.PromptTokens
+		u.CompletionTokens = reported.CompletionTokens
+		u.CachedTokens = reported.CachedTokens
+	} else {
+		u.EstimatedCalls = 1
+		u.PromptTokens = estimateRequestTokens(req)
+		u.CompletionTokens = estimateTokens(completion)
+		for _, tc := range toolCalls {
+			u.CompletionTokens += estimateTokens(tc.Name) + estimateTokens(tc.Args)
+		}
+	}
+	u.Cost = r.cfg.Prices.Cost(r.model, u)
+	r.addUsage(u)
+}
+
+// recordToolCall adds one tool call to turn and session usage.
+func (r *TurnRunner) recordToolCall() {
+	r.addUsage(api.Usage{ToolCalls: 1})
+}
+
+// tick adds the time since the last tick to turn and session usage.
+func (r *TurnRunner) tick() {
+	now := time.Now()
+	if !r.lastTick.IsZero() {
+		r.addUsage(api.Usage{Duration: now.Sub(r.lastTick)})
+	}
+	r.lastTick = now
+}
+
+func (r *TurnRunner) addUsage(u api.Usage) {
+	r.usage = r.usage.Add(u)
+	if r.session == nil {
+		return
+	}
+	setSessionUsage(r.session, sessionUsage(r.session).Add(u))
+
+	buckets := usageBuckets(r.session)
+	key := strings.Join([]string{time.Now().Format("2006-01-02"), r.session.ActiveSkill, r.model}, "|")
+	buckets[key] = buckets[key].Add(u)
+	b, _ := json.Marshal(buckets)
+	r.session.Metadata[sessionUsageBucketsKey] = string(b)
+}
+
+// sessionUsage returns cumulative usage recorded in session metadata.
+func sessionUsage(s *api.Session) api.Usage {
+	var u api.Usage
+	if s != nil && s.Metadata != nil {
+		_ = json.Unmarshal([]byte(s.Metadata[sessionUsageKey]), &u)
+	}
+	return u
+}
+
+func setSessionUsage(s *api.Session, u api.Usage) {
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]string)
+	}
+	b, _ := json.Marshal(u)
+	s.Metadata[sessionUsageKey] = string(b)
+}
+
+func usageBuckets(s *api.Session) map[string]api.Usage {
+	out := make(map[string]api.Usage)
+	if s.Metadata != nil {
+		_ = json.Unmarshal([]byte(s.Metadata[sessionUsageBucketsKey]), &out)
+	}
+	return out
+}
+
+// SessionUsageRecords splits a session's recorded usage by day, skill and model,
+// sorted by day.
+func SessionUsageRecords(s *api.Session) []api.UsageRecord {
+	var out []api.UsageRecord
+	for key, u := range usageBuckets(s) {
+		parts := strings.SplitN(key, "|", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		out = append(out, api.UsageRecord{SessionID: s.SessionID, Day: parts[0], Skill: parts[1], Model: parts[2], Usage: u})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Day != out[j].Day {
+			return out[i].Day < out[j].Day
+		}
+		return out[i].Skill+out[i].Model < out[j].Skill+out[j].Model
+	})
+	return out
+}
+
+// UsageRecords returns usage records for every session (or only sessionID when set).
+func (e *Engine) UsageRecords(ctx context.Context, sessionID string) ([]api.UsageRecord, error) {
+	ids := []string{sessionID}
+	if sessionID == "" {
+		var err error
+		if ids, err = e.sessionStore.List(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var out []api.UsageRecord
+	for _, id := range ids {
+		s, err := e.sessionStore.Get(ctx, id)
+		if err != nil {
+			if sessionID != "" {
+				if err == store.ErrNotFound {
+					return nil, fmt.Errorf("%s: %s", api.ErrInvalidSession, id)
+				}
+				return nil, err
+			}
+			continue
+		}
+		out = append(out, SessionUsageRecords(s)...)
+	}
+	return out, nil
+}
+
+// estimateRequestTokens approximates the prompt size of req, including tool schemas.
+func estimateRequestTokens(req LLMRequest) int {
+	n := 0
+	for _, m := range req.Messages {
+		n += estimateTokens(m.Content) + estimateTokens(m.Reasoning)
+		for _, p := range m.Parts {
+			if p.Type == api.PartImage {
+				n += imageTokenEstimate
+			} else {
+				n += estimateTokens(partText(p))
+			}
+		}
+		for _, tc := range m.ToolCalls {
+			n += estimateTokens(tc.Name) + estimateTokens(tc.Args)
+		}
+	}
+	if len(req.Tools) > 0 {
+		b, _ := json.Marshal(req.Tools)
+		n += estimateTokens(string(b))
+	}
+	return n
+}
+
+// estimateTokens approximates token count: ~4 bytes per token for ASCII text,
+// one token per character for other scripts.
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -41,6 +42,8 @@ var (
 		"compatibility": {},
 		"metadata":      {},
 		"allowed-tools": {},
+		"hooks":         {},
+		"model":         {},
 	}
 
 	skillNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
@@ -151,10 +154,12 @@ func (idx *DirSkillIndex) Load(name string) (*api.Skill, error) {
 			License:       fm.License,
 			Compatibility: fm.Compatibility,
 			AllowedTools:  append([]string(nil), fm.AllowedTools...),
+			Model:         fm.Model,
 			Path:          meta.Path,
 		},
 		Content:  strings.TrimSpace(body),
 		Metadata: fm.Metadata,
+		Hooks:    append([]api.SkillHook(nil), fm.Hooks...),
 	}
 
 	// Optional directories for progressive disclosure.
@@ -201,6 +206,8 @@ type parsedFrontmatter struct {
 	Compatibility string
 	Metadata      map[string]string
 	AllowedTools  []string
+	Hooks         []api.SkillHook
+	Model         string
 }
 
 func parseSkillMeta(skillFile string) (api.SkillMeta, error) {
@@ -268,6 +275,7 @@ func parseSkillMarkdown(skillFile string, raw string) (api.SkillMeta, string, pa
 		License:       fm.License,
 		Compatibility: fm.Compatibility,
 		AllowedTools:  append([]string(nil), fm.AllowedTools...),
+		Model:         fm.Model,
 	}
 
 	return meta, bodyText, fm, nil
@@ -352,9 +360,148 @@ func decodeFrontmatter(raw map[string]any) (parsedFrontmatter, error) {
 		}
 	}
 
+	// model names a model role (e.g. "summarizer") or a model to use while the skill is active.
+	if v, ok := raw["model"]; ok && v != nil {
+		s, ok := v.(string)
+		if !ok {
+			return parsedFrontmatter{}, fmt.Errorf("invalid frontmatter: model must be a string")
+		}
+		fm.Model = strings.TrimSpace(s)
+	}
+
+	if v, ok := raw["hooks"]; ok && v != nil {
+		hooks, err := decodeHooks(v)
+		if err != nil {
+			return parsedFrontmatter{}, err
+		}
+		fm.Hooks = hooks
+	}
+
 	return fm, nil
 }
 
+// decodeHooks parses the "hooks" frontmatter mapping:
+//
+//	hooks:
+//	  post-tool:
+//	    - script: fmt.sh
+//	      tools: [write_file, edit_file]
+//	  on-turn-end: lint.sh
+//
+// A single script may be given as a scalar. Script paths are relative to the skill's
+// scripts/ directory.
+func decodeHooks(v any) ([]api.SkillHook, error) {
+	m, ok := toStringMap(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid frontmatter: hooks must be a mapping of event to list")
+	}
+
+	events := make([]string, 0, len(m))
+	for k := range m {
+		events = append(events, k)
+	}
+	sort.Strings(events)
+
+	var out []api.SkillHook
+	for _, event := range events {
+		ev := api.SkillHookEvent(event)
+		switch ev {
+		case api.HookOnActivate, api.HookPreTool, api.HookPostTool, api.HookOnTurnEnd:
+		default:
+			return nil, fmt.Errorf("invalid frontmatter: unknown hook event %q", event)
+		}
+
+		list, ok := m[event].([]any)
+		if s, isScalar := m[event].(string); isScalar {
+			list, ok = []any{s}, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid frontmatter: hooks[%q] must be a list", event)
+		}
+		for i, it := range list {
+			h := api.SkillHook{Event: ev}
+			switch entry := it.(type) {
+			case string:
+				h.Script = strings.TrimSpace(entry)
+			default:
+				em, ok := toStringMap(entry)
+				if !ok {
+					return nil, fmt.Errorf("invalid frontmatter: hooks[%q][%d] must be a string or mapping", event, i)
+				}
+				if s, ok := em["script"].(string); ok {
+					h.Script = strings.TrimSpace(s)
+				}
+				args, err := stringList(em["args"])
+				if err != nil {
+					return nil, fmt.Errorf("invalid frontmatter: hooks[%q][%d].args: %v", event, i, err)
+				}
+				h.Args = args
+				toolNames, err := stringList(em["tools"])
+				if err != nil {
+					return nil, fmt.Errorf("invalid frontmatter: hooks[%q][%d].tools: %v", event, i, err)
+				}
+				h.Tools = toolNames
+				if b, ok := em["blocking"].(bool); ok {
+					h.Blocking = b
+				}
+				if n, ok := em["timeout_sec"].(int); ok {
+					h.TimeoutSec = n
+				}
+			}
+			if h.Script == "" {
+				return nil, fmt.Errorf("invalid frontmatter: hooks[%q][%d] missing script", event, i)
+			}
+			if h.Blocking && ev != api.HookPreTool {
+				return nil, fmt.Errorf("invalid frontmatter: hooks[%q][%d]: blocking is only valid for pre-tool hooks", event, i)
+			}
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func toStringMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		out := make(map[string]any, len(m))
+		for k, vv := range m {
+			ks, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			out[ks] = vv
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+func stringList(v any) ([]string, error) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.Fields(vv), nil
+	case []any:
+		var out []string
+		for _, it := range vv {
+			s, ok := it.(string)
+			if !ok {
+				return nil, fmt.Errorf("entries must be strings")
+			}
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("must be a list of strings")
+	}
+}
+
 func validateFrontmatter(fm parsedFrontmatter) error {
 	if fm.Name == "" {
 		return fmt.Errorf("invalid frontmatter: missing required field 'name'")
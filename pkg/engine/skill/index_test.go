@@ -0,0 +1,43 @@
+package skill
+
+import (
+	"reflect"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// The hooks example from the README.
+const readmeHooksSkill = `---
+name: my-skill
+description: A custom workflow.
+hooks:
+  on-activate: setup.sh
+  pre-tool:
+    - script: guard.sh
+      tools: [write_file, edit_file]
+      blocking: true        # non-zero exit blocks the tool call
+  post-tool:
+    - script: fmt.sh
+      tools: [write_file]
+  on-turn-end: report.sh
+---
+
+# Instructions
+`
+
+func TestParseSkillMarkdown_ReadmeHooksExample(t *testing.T) {
+	_, _, fm, err := parseSkillMarkdown("SKILL.md", readmeHooksSkill)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []api.SkillHook{
+		{Event: api.HookOnActivate, Script: "setup.sh"},
+		{Event: api.HookOnTurnEnd, Script: "report.sh"},
+		{Event: api.HookPostTool, Script: "fmt.sh", Tools: []string{"write_file"}},
+		{Event: api.HookPreTool, Script: "guard.sh", Tools: []string{"write_file", "edit_file"}, Blocking: true},
+	}
+	if !reflect.DeepEqual(fm.Hooks, want) {
+		t.Fatalf("unexpected hooks:\n got %+v\nwant %+v", fm.Hooks, want)
+	}
+}
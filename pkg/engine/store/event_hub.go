@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Event Hub
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// DefaultSubscriberBuffer is the number of events a subscriber holds before the
+// overflow policy applies.
+const DefaultSubscriberBuffer = 256
+
+// EventHub fans out a turn's events to any number of subscribers. Publish never
+// blocks: each subscriber has its own queue, and once a queue is full a slow
+// subscriber gets deltas coalesced into the last queued delta and loses debug events.
+// Other events (approvals, results, done) are always delivered.
+type EventHub struct {
+	mu     sync.Mutex
+	subs   []*Subscription
+	closed bool
+}
+
+// NewEventHub creates an empty hub.
+func NewEventHub() *EventHub {
+	return &EventHub{}
+}
+
+// Publish delivers an event to every subscriber.
+func (h *EventHub) Publish(e api.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	for _, s := range h.subs {
+		s.push(e)
+	}
+}
+
+// Subscribe adds a subscriber that receives events published from now on. Subscribing
+// to a closed hub returns a stream that ends immediately.
+func (h *EventHub) Subscribe(buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+	s := &Subscription{hub: h, limit: buffer, notify: make(chan struct{}, 1)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		s.ended = true
+		return s
+	}
+	h.subs = append(h.subs, s)
+	return s
+}
+
+// Close ends every subscription once its queue is drained.
+func (h *EventHub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	for _, s := range h.subs {
+		s.end()
+	}
+	h.subs = nil
+	return nil
+}
+
+func (h *EventHub) remove(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, sub := range h.subs {
+		if sub == s {
+			h.subs = append(h.subs[:i], h.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Subscription is one subscriber's view of a hub; it implements api.EventStream.
+type Subscription struct {
+	hub    *EventHub
+	mu     sync.Mutex
+	queue  []api.Event
+	limit  int
+	notify chan struct{}
+	ended  bool // the hub closed
+	closed bool // the subscriber closed
+
+	// Dropped counts debug events dropped on overflow.
+	Dropped int
+}
+
+func (s *Subscription) push(e api.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if len(s.queue) >= s.limit {
+		if e.Display != nil && e.Display.Level == "debug" {
+			s.Dropped++
+			return
+		}
+		if last := &s.queue[len(s.queue)-1]; coalescible(*last, e) {
+			merged := *last.Delta
+			merged.Text += e.Delta.Text
+			last.Delta = &merged
+			last.Seq = e.Seq
+			last.Ts = e.Ts
+			return
+		}
+	}
+	s.queue = append(s.queue, e)
+	s.signal()
+}
+
+// coalescible reports whether delta e can be appended to the queued delta last.
+func coalescible(last, e api.Event) bool {
+	return last.Type == api.EventDelta && e.Type == api.EventDelta &&
+		last.Delta != nil && e.Delta != nil &&
+		last.Delta.Source == e.Delta.Source && last.TurnID == e.TurnID
+}
+
+func (s *Subscription) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+	s.signal()
+}
+
+func (s *Subscription) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Recv returns the next event; io.EOF once the hub closed and the queue is drained.
+func (s *Subscription) Recv(ctx context.Context) (api.Event, error) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			e := s.queue[0]
+			s.queue[0] = api.Event{}
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			return e, nil
+		}
+		done := s.ended || s.closed
+		s.mu.Unlock()
+		if done {
+			return api.Event{}, io.EOF
+		}
+
+		select {
+		case <-ctx.Done():
+			return api.Event{}, ctx.Err()
+		case <-s.notify:
+		}
+	}
+}
+
+// Close unsubscribes; other subscribers are unaffected.
+func (s *Subscription) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.queue = nil
+	s.signal()
+	s.mu.Unlock()
+	s.hub.remove(s)
+	return nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestEventHub_SlowSubscriberCoalescesDeltasAndKeepsApprovals(t *testing.T) {
+	hub := NewEventHub()
+	slow := hub.Subscribe(2)
+	fast := hub.Subscribe(0)
+
+	delta := func(seq int64, text string) api.Event {
+		return api.Event{Seq: seq, Type: api.EventDelta, Delta: &api.DeltaPayload{Text: text}}
+	}
+	hub.Publish(delta(1, "a"))
+	hub.Publish(delta(2, "b"))
+	hub.Publish(delta(3, "c")) // over the limit: merged into "b"
+	hub.Publish(api.Event{Seq: 4, Type: api.EventThinking, Display: &api.DisplayHint{Level: "debug"}})
+	hub.Publish(api.Event{Seq: 5, Type: api.EventApproval, Approval: &api.ApprovalPayload{RequestID: "r1"}})
+	hub.Close()
+
+	var got []api.Event
+	for {
+		e, err := slow.Recv(context.Background())
+		if err == io.EOF {
+			break
+		}
+		got = append(got, e)
+	}
+	if len(got) != 3 || got[1].Delta.Text != "bc" || got[1].Seq != 3 || got[2].Type != api.EventApproval {
+		t.Fatalf("unexpected slow subscriber events: %+v", got)
+	}
+	if slow.Dropped != 1 {
+		t.Fatalf("expected the debug event dropped, got %d", slow.Dropped)
+	}
+
+	n := 0
+	for {
+		e, err := fast.Recv(context.Background())
+		if err == io.EOF {
+			break
+		}
+		if e.Type == api.EventDelta && len(e.Delta.Text) != 1 {
+			t.Fatalf("expected the fast subscriber's deltas untouched, got %q", e.Delta.Text)
+		}
+		n++
+	}
+	if n != 5 {
+		t.Fatalf("expected every event for the fast subscriber, got %d", n)
+	}
+}
@@ -116,6 +116,42 @@ func (l *JSONLEventLog) Stream(ctx context.Context, sessionID string) (api.Event
 	}, nil
 }
 
+// ReadFrom implements EventFollower. A trailing line still being written is left for
+// the next read.
+func (l *JSONLEventLog) ReadFrom(ctx context.Context, sessionID string, offset int64) ([]api.Event, int64, error) {
+	p := l.path(sessionID)
+	if err := l.validatePath(p); err != nil {
+		return nil, offset, err
+	}
+
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, offset, nil
+	}
+	if err != nil {
+		return nil, offset, fmt.Errorf("failed to open events file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, fmt.Errorf("failed to seek events file: %w", err)
+	}
+
+	var events []api.Event
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// EOF: an incomplete line is read again next time.
+			return events, offset, nil
+		}
+		offset += int64(len(line))
+		var e api.Event
+		if json.Unmarshal(line, &e) == nil {
+			events = append(events, e)
+		}
+	}
+}
+
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // Event Stream Implementations
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
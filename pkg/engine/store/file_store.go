@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -85,6 +87,7 @@ func (s *FileSessionStore) Get(ctx context.Context, id string) (*api.Session, er
 		return nil, fmt.Errorf("session data is nil for id: %s", id)
 	}
 
+	s.loadBlobs(wrapper.Session)
 	return wrapper.Session, nil
 }
 
@@ -94,9 +97,16 @@ func (s *FileSessionStore) Put(ctx context.Context, id string, session *api.Sess
 		return err
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.storeBlobs(session)
+	if err != nil {
+		return err
+	}
 	wrapper := sessionWrapper{
 		Version: 1,
-		Session: session,
+		Session: stored,
 	}
 
 	data, err := json.MarshalIndent(wrapper, "", "  ")
@@ -104,9 +114,6 @@ func (s *FileSessionStore) Put(ctx context.Context, id string, session *api.Sess
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	// Atomic write: temp file + rename
 	tmpPath := p + ".tmp"
 	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
@@ -162,6 +169,98 @@ func (s *FileSessionStore) List(ctx context.Context) ([]string, error) {
 	return ids, nil
 }
 
+// Binary content parts (images) are kept in sessions/blobs/<sha256> rather than
+// base64-encoded in the session JSON; the JSON keeps the hash in BlobRef.
+
+func (s *FileSessionStore) blobPath(ref string) string {
+	return filepath.Join(s.baseDir, "blobs", ref)
+}
+
+// storeBlobs writes part data to blob files and returns a copy of session that refers
+// to them. The caller's session is not modified.
+func (s *FileSessionStore) storeBlobs(session *api.Session) (*api.Session, error) {
+	var out *api.Session
+	for i, m := range session.Messages {
+		if !hasInlineData(m.Parts) {
+			continue
+		}
+		if out == nil {
+			cp := *session
+			cp.Messages = append([]api.LLMMessage(nil), session.Messages...)
+			out = &cp
+		}
+		parts := append([]api.ContentPart(nil), m.Parts...)
+		for j := range parts {
+			if len(parts[j].Data) == 0 {
+				continue
+			}
+			ref, err := s.writeBlob(parts[j].Data)
+			if err != nil {
+				return nil, err
+			}
+			parts[j].Data = nil
+			parts[j].BlobRef = ref
+		}
+		out.Messages[i].Parts = parts
+	}
+	if out == nil {
+		return session, nil
+	}
+	return out, nil
+}
+
+// BlobRef returns the content hash under which data is stored as a blob.
+func BlobRef(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeBlob stores data under its content hash; existing blobs are reused.
+func (s *FileSessionStore) writeBlob(data []byte) (string, error) {
+	ref := BlobRef(data)
+	p := s.blobPath(ref)
+	if _, err := os.Stat(p); err == nil {
+		return ref, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", fmt.Errorf("failed to create blobs directory: %w", err)
+	}
+	if err := os.WriteFile(p+".tmp", data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(p+".tmp", p); err != nil {
+		os.Remove(p + ".tmp")
+		return "", fmt.Errorf("failed to rename blob: %w", err)
+	}
+	return ref, nil
+}
+
+func hasInlineData(parts []api.ContentPart) bool {
+	for _, p := range parts {
+		if len(p.Data) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// loadBlobs fills part data from blob files. A missing blob leaves Data empty; backends
+// render such parts as placeholders.
+func (s *FileSessionStore) loadBlobs(session *api.Session) {
+	for i := range session.Messages {
+		parts := session.Messages[i].Parts
+		for j := range parts {
+			ref := parts[j].BlobRef
+			if ref == "" || len(parts[j].Data) > 0 || strings.ContainsAny(ref, `/\.`) {
+				continue
+			}
+			if data, err := os.ReadFile(s.blobPath(ref)); err == nil {
+				parts[j].Data = data
+			}
+		}
+	}
+}
+
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // FilePlanStore
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestFileSessionStore_KeepsImagesOutsideSessionJSON(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileSessionStore(dir)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	image := bytes.Repeat([]byte{0xff, 0xd8}, 4096)
+	session := &api.Session{SessionID: "s1", Messages: []api.LLMMessage{
+		{Role: "user", Content: "look", Parts: []api.ContentPart{api.ImagePart("image/jpeg", image, "a.jpg")}},
+	}}
+	if err := s.Put(context.Background(), "s1", session); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if len(session.Messages[0].Parts[0].Data) == 0 {
+		t.Fatal("Put must not strip data from the caller's session")
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(dir, "sessions", "s1.json"))
+	if len(raw) > 2048 || !bytes.Contains(raw, []byte(`"blob_ref"`)) {
+		t.Fatalf("expected a blob reference instead of inline data, got %d bytes", len(raw))
+	}
+
+	got, err := s.Get(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if p := got.Messages[0].Parts[0]; !bytes.Equal(p.Data, image) || p.MIMEType != "image/jpeg" {
+		t.Fatalf("expected image data restored, got %d bytes", len(p.Data))
+	}
+	if ids, _ := s.List(context.Background()); len(ids) != 1 {
+		t.Fatalf("expected blobs to stay out of the session list, got %v", ids)
+	}
+}
+
+func TestFileSessionStore_LockExcludesOthersAndTakesOverStaleLocks(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileSessionStore(dir)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	lease, err := s.Lock("s1")
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if _, err := s.Lock("s1"); !errors.Is(err, ErrSessionLocked) {
+		t.Fatalf("expected ErrSessionLocked while held, got %v", err)
+	}
+	if err := lease.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	lease, err = s.Lock("s1")
+	if err != nil {
+		t.Fatalf("expected relock after release: %v", err)
+	}
+	lease.Release()
+
+	// A lock left by an earlier process with this PID is stale.
+	host, _ := os.Hostname()
+	stale := fmt.Sprintf(`{"pid":%d,"host":%q,"token":"old","acquired_at":"2026-01-01T00:00:00Z"}`, os.Getpid(), host)
+	if err := os.WriteFile(filepath.Join(dir, "sessions", "s1.json.lock"), []byte(stale), 0644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	if holder, isStale, _ := s.InspectLock("s1"); holder == nil || !isStale {
+		t.Fatalf("expected a stale holder, got %+v stale=%v", holder, isStale)
+	}
+	lease, err = s.Lock("s1")
+	if err != nil {
+		t.Fatalf("expected stale lock taken over: %v", err)
+	}
+	lease.Release()
+}
+
+func TestFileSessionStore_ConcurrentTakeoverGrantsOneLease(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileSessionStore(dir)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	lockFile := filepath.Join(dir, "sessions", "s1.json.lock")
+
+	for round := 0; round < 20; round++ {
+		// A lock nobody has refreshed for longer than LockTTL.
+		if err := os.WriteFile(lockFile, []byte(`{"pid":1,"host":"elsewhere","token":"old"}`), 0644); err != nil {
+			t.Fatalf("write lock: %v", err)
+		}
+		old := time.Now().Add(-2 * LockTTL)
+		if err := os.Chtimes(lockFile, old, old); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var leases []Lease
+		start := make(chan struct{})
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				lease, err := s.Lock("s1")
+				if err != nil {
+					if !errors.Is(err, ErrSessionLocked) {
+						t.Errorf("unexpected error: %v", err)
+					}
+					return
+				}
+				mu.Lock()
+				leases = append(leases, lease)
+				mu.Unlock()
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if len(leases) != 1 {
+			t.Fatalf("round %d: expected exactly one lease, got %d", round, len(leases))
+		}
+
+		// A process that saw the stale lock before it was taken over must not remove
+		// the new holder's lock.
+		if err := s.takeOver("s1"); !errors.Is(err, ErrSessionLocked) {
+			t.Fatalf("expected a late takeover refused, got %v", err)
+		}
+		if holder, _, _ := s.InspectLock("s1"); holder == nil || holder.Token != processToken {
+			t.Fatalf("expected the new holder's lock kept, got %+v", holder)
+		}
+		leases[0].Release()
+		if _, err := os.Stat(lockFile + ".takeover"); !os.IsNotExist(err) {
+			t.Fatalf("expected the takeover file removed, got %v", err)
+		}
+	}
+}
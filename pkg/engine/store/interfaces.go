@@ -30,6 +30,23 @@ type SessionStore = Store[*api.Session]
 // PlanStore stores Plan records.
 type PlanStore = Store[*api.PlanPayload]
 
+// SessionLocker is implemented by session stores shared between processes. A lease
+// keeps other processes from running turns on the same session.
+type SessionLocker interface {
+	// Lock leases the session to this process. It fails with ErrSessionLocked while a
+	// live holder has it; a lock left by a dead process is taken over.
+	Lock(id string) (Lease, error)
+
+	// InspectLock returns the session's lock holder (nil if unlocked) and whether the
+	// holder is gone.
+	InspectLock(id string) (*LockInfo, bool, error)
+}
+
+// Lease is a held session lock.
+type Lease interface {
+	Release() error
+}
+
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // EventLog Interface
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -43,6 +60,13 @@ type EventLog interface {
 	Stream(ctx context.Context, sessionID string) (api.EventStream, error)
 }
 
+// EventFollower is implemented by event logs that can be followed while another
+// process appends to them.
+type EventFollower interface {
+	// ReadFrom returns the complete events after byte offset and the offset after them.
+	ReadFrom(ctx context.Context, sessionID string, offset int64) ([]api.Event, int64, error)
+}
+
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // Standard Errors
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -51,4 +75,5 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrWorkspaceEscape = errors.New("path escapes workspace boundary")
 	ErrInvalidPath     = errors.New("invalid path")
+	ErrSessionLocked   = errors.New("session locked")
 )
@@ -0,0 +1,236 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	goruntime "runtime"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+// Session Locks
+// ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+
+// A session lock is a sessions/<id>.lock file created exclusively by the process that
+// runs a turn. The holder touches it every LockRefresh; a lock is stale when it has not
+// been touched for LockTTL, or when its holder on this host has exited. A stale lock is
+// removed under a <id>.lock.takeover file, also created exclusively, so that of several
+// processes that found it stale only one removes it.
+
+const (
+	LockRefresh = 15 * time.Second
+	LockTTL     = time.Minute
+)
+
+// LockInfo describes a session lock holder.
+type LockInfo struct {
+	PID        int       `json:"pid"`
+	Host       string    `json:"host"`
+	Token      string    `json:"token"`
+	AcquiredAt time.Time `json:"acquired_at"`
+
+	// RefreshedAt is the lock file's modification time.
+	RefreshedAt time.Time `json:"-"`
+}
+
+func (i *LockInfo) String() string {
+	return fmt.Sprintf("pid %d on %s since %s", i.PID, i.Host, i.AcquiredAt.Format(time.RFC3339))
+}
+
+// processToken tells this process apart from an earlier one that had the same PID
+// (common in containers).
+var processToken = newProcessToken()
+
+func newProcessToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *FileSessionStore) lockPath(id string) string {
+	return s.path(id) + ".lock"
+}
+
+// Lock implements SessionLocker.
+func (s *FileSessionStore) Lock(id string) (Lease, error) {
+	p := s.lockPath(id)
+	if err := s.validatePath(p); err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	info := LockInfo{PID: os.Getpid(), Host: host, Token: processToken, AcquiredAt: time.Now()}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lock: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, werr := f.Write(data)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				os.Remove(p)
+				return nil, fmt.Errorf("failed to write lock: %v", errors.Join(werr, cerr))
+			}
+			return newFileLease(p, info.Token, info.AcquiredAt), nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock: %w", err)
+		}
+
+		holder, stale, err := s.InspectLock(id)
+		if err != nil {
+			return nil, err
+		}
+		if holder != nil && !stale {
+			return nil, fmt.Errorf("%w: %s is held by %s", ErrSessionLocked, id, holder)
+		}
+		// Released meanwhile, or left behind by a dead process.
+		if holder != nil {
+			if err := s.takeOver(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s is contended", ErrSessionLocked, id)
+}
+
+// takeOver removes a stale lock. The check is repeated while holding the takeover
+// file: removing by path alone could delete a fresh lock that another process created
+// after taking over the same stale one.
+func (s *FileSessionStore) takeOver(id string) error {
+	guard := s.lockPath(id) + ".takeover"
+	f, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if !os.IsExist(err) {
+			return fmt.Errorf("failed to create takeover lock: %w", err)
+		}
+		// Takeovers take milliseconds; an old file was left by a process that died in one.
+		if st, serr := os.Stat(guard); serr == nil && time.Since(st.ModTime()) > LockTTL {
+			os.Remove(guard)
+		}
+		return fmt.Errorf("%w: %s is being taken over", ErrSessionLocked, id)
+	}
+	f.Close()
+	defer os.Remove(guard)
+
+	holder, stale, err := s.InspectLock(id)
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		return nil
+	}
+	if !stale {
+		return fmt.Errorf("%w: %s is held by %s", ErrSessionLocked, id, holder)
+	}
+	if err := os.Remove(s.lockPath(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale lock: %w", err)
+	}
+	return nil
+}
+
+// InspectLock implements SessionLocker.
+func (s *FileSessionStore) InspectLock(id string) (*LockInfo, bool, error) {
+	p := s.lockPath(id)
+	if err := s.validatePath(p); err != nil {
+		return nil, false, err
+	}
+	st, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to stat lock: %w", err)
+	}
+	info := &LockInfo{RefreshedAt: st.ModTime()}
+	if data, err := os.ReadFile(p); err == nil {
+		// A lock that is still being written parses as empty; its age decides.
+		_ = json.Unmarshal(data, info)
+	}
+	return info, lockStale(info), nil
+}
+
+func lockStale(info *LockInfo) bool {
+	if time.Since(info.RefreshedAt) > LockTTL {
+		return true
+	}
+	host, _ := os.Hostname()
+	if info.PID == 0 || info.Host != host {
+		return false
+	}
+	if info.PID == os.Getpid() {
+		return info.Token != processToken
+	}
+	return !processAlive(info.PID)
+}
+
+// processAlive reports whether a process with pid exists on this host.
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if goruntime.GOOS == "windows" {
+		// FindProcess opens the process there, so it only succeeds for live ones.
+		return true
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// fileLease keeps a lock file fresh until released.
+type fileLease struct {
+	path       string
+	token      string
+	acquiredAt time.Time
+	stop       chan struct{}
+	once       sync.Once
+}
+
+func newFileLease(path, token string, acquiredAt time.Time) *fileLease {
+	l := &fileLease{path: path, token: token, acquiredAt: acquiredAt, stop: make(chan struct{})}
+	go l.refresh()
+	return l
+}
+
+func (l *fileLease) refresh() {
+	ticker := time.NewTicker(LockRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			_ = os.Chtimes(l.path, now, now)
+		}
+	}
+}
+
+// Release removes the lock file unless another process has taken it over.
+func (l *fileLease) Release() error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		var info LockInfo
+		data, rerr := os.ReadFile(l.path)
+		if rerr != nil {
+			return
+		}
+		if json.Unmarshal(data, &info) == nil && (info.Token != l.token || !info.AcquiredAt.Equal(l.acquiredAt)) {
+			return
+		}
+		if rerr := os.Remove(l.path); rerr != nil && !os.IsNotExist(rerr) {
+			err = fmt.Errorf("failed to remove lock: %w", rerr)
+		}
+	})
+	return err
+}
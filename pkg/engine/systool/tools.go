@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"AgentEngine/pkg/engine/api"
+	planpkg "AgentEngine/pkg/engine/plan"
 	"AgentEngine/pkg/engine/skill"
 	"AgentEngine/pkg/engine/store"
 )
@@ -211,10 +212,26 @@ type WriteTodosTool struct {
 	PlanStore store.PlanStore
 }
 
-func (t *WriteTodosTool) Name() string        { return "write_todos" }
-func (t *WriteTodosTool) Description() string { return "Create or update the plan/todos" }
+func (t *WriteTodosTool) Name() string { return "write_todos" }
+func (t *WriteTodosTool) Description() string {
+	return "Create or update the plan/todos. Items may have sub-items (parent_id) and dependencies (depends_on); " +
+		"an item cannot start or finish before its dependencies are done, nor finish before its sub-items."
+}
 func (t *WriteTodosTool) Risk() api.RiskLevel { return api.RiskHigh }
 func (t *WriteTodosTool) Schema() api.ToolSchema {
+	itemProps := map[string]any{
+		"id":         map[string]any{"type": "integer"},
+		"text":       map[string]any{"type": "string"},
+		"status":     map[string]any{"type": "string", "description": "pending | running | done | blocked | errored"},
+		"parent_id":  map[string]any{"type": "integer", "description": "Parent item id for sub-items (0 = top level)"},
+		"depends_on": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "description": "Item ids that must be done first"},
+		"notes":      map[string]any{"type": "string"},
+		"evidence":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Tool call ids that completed this item"},
+	}
+	patchProps := map[string]any{"delete": map[string]any{"type": "boolean"}}
+	for k, v := range itemProps {
+		patchProps[k] = v
+	}
 	return api.ToolSchema{
 		Name:        t.Name(),
 		Description: t.Description(),
@@ -226,27 +243,12 @@ func (t *WriteTodosTool) Schema() api.ToolSchema {
 				"items": map[string]any{
 					"type":        "array",
 					"description": "Items for set/append",
-					"items": map[string]any{
-						"type": "object",
-						"properties": map[string]any{
-							"id":     map[string]any{"type": "integer"},
-							"text":   map[string]any{"type": "string"},
-							"status": map[string]any{"type": "string"},
-						},
-					},
+					"items":       map[string]any{"type": "object", "properties": itemProps},
 				},
 				"patches": map[string]any{
 					"type":        "array",
 					"description": "Patches for patch mode",
-					"items": map[string]any{
-						"type": "object",
-						"properties": map[string]any{
-							"id":     map[string]any{"type": "integer"},
-							"text":   map[string]any{"type": "string"},
-							"status": map[string]any{"type": "string"},
-							"delete": map[string]any{"type": "boolean"},
-						},
-					},
+					"items":       map[string]any{"type": "object", "properties": patchProps},
 				},
 			},
 		},
@@ -273,23 +275,23 @@ func (t *WriteTodosTool) Execute(ctx context.Context, args api.Args) (api.ToolRe
 	if itemsRaw, ok := args["items"].([]any); ok {
 		for _, item := range itemsRaw {
 			if itemMap, ok := item.(map[string]any); ok {
-				pi := api.PlanItem{}
-				if id, ok := itemMap["id"].(float64); ok {
-					pi.ID = int(id)
-				}
-				if text, ok := itemMap["text"].(string); ok {
-					pi.Text = text
-				}
-				if status, ok := itemMap["status"].(string); ok {
-					pi.Status = api.PlanItemStatus(status)
-				} else {
-					pi.Status = api.PlanPending
-				}
+				pi := api.PlanItem{Status: api.PlanPending}
+				applyPlanFields(&pi, itemMap)
 				newItems = append(newItems, pi)
 			}
 		}
 	}
 
+	// Snapshot of the stored plan, for transition checks.
+	existing, err := t.PlanStore.Get(ctx, planID)
+	if err != nil && err != store.ErrNotFound {
+		return api.ToolResult{Status: "error", Error: err.Error()}, nil
+	}
+	var prev *api.PlanPayload
+	if existing != nil {
+		prev = clonePlan(existing)
+	}
+
 	var plan *api.PlanPayload
 
 	switch mode {
@@ -298,36 +300,35 @@ func (t *WriteTodosTool) Execute(ctx context.Context, args api.Args) (api.ToolRe
 			PlanID: planID,
 			Items:  newItems,
 		}
+		// Auto-assign IDs for items without one.
+		next := planpkg.NextID(plan)
+		for i := range plan.Items {
+			if plan.Items[i].ID == 0 {
+				plan.Items[i].ID = next
+				next++
+			}
+		}
 
 	case "append":
-		existing, err := t.PlanStore.Get(ctx, planID)
-		if err != nil && err != store.ErrNotFound {
-			return api.ToolResult{Status: "error", Error: err.Error()}, nil
-		}
-		if existing == nil {
-			existing = &api.PlanPayload{PlanID: planID}
+		plan = clonePlan(existing)
+		if plan == nil {
+			plan = &api.PlanPayload{PlanID: planID}
 		}
 		// Auto-assign IDs for append
-		maxID := 0
-		for _, item := range existing.Items {
-			if item.ID > maxID {
-				maxID = item.ID
-			}
-		}
+		next := planpkg.NextID(plan)
 		for i := range newItems {
 			if newItems[i].ID == 0 {
-				maxID++
-				newItems[i].ID = maxID
+				newItems[i].ID = next
+				next++
 			}
 		}
-		existing.Items = append(existing.Items, newItems...)
-		plan = existing
+		plan.Items = append(plan.Items, newItems...)
 
 	case "patch":
-		existing, err := t.PlanStore.Get(ctx, planID)
-		if err != nil {
-			return api.ToolResult{Status: "error", Error: err.Error()}, nil
+		if existing == nil {
+			return api.ToolResult{Status: "error", Error: store.ErrNotFound.Error()}, nil
 		}
+		plan = clonePlan(existing)
 
 		// Apply patches
 		if patchesRaw, ok := args["patches"].([]any); ok {
@@ -341,36 +342,43 @@ func (t *WriteTodosTool) Execute(ctx context.Context, args api.Args) (api.ToolRe
 						continue
 					}
 
-					for i := range existing.Items {
-						if existing.Items[i].ID == id {
-							if text, ok := patchMap["text"].(string); ok {
-								existing.Items[i].Text = text
-							}
-							if status, ok := patchMap["status"].(string); ok {
-								existing.Items[i].Status = api.PlanItemStatus(status)
-							}
+					for i := range plan.Items {
+						if plan.Items[i].ID == id {
 							if del, ok := patchMap["delete"].(bool); ok && del {
-								existing.Items = append(existing.Items[:i], existing.Items[i+1:]...)
+								plan.Items = append(plan.Items[:i], plan.Items[i+1:]...)
+								break
 							}
+							applyPlanFields(&plan.Items[i], patchMap)
 							break
 						}
 					}
 				}
 			}
 		}
-		plan = existing
 
 	default:
 		return api.ToolResult{Status: "error", Error: "invalid mode: " + mode}, nil
 	}
 
-	// Validate unique IDs
-	idSeen := make(map[int]bool)
-	for _, item := range plan.Items {
-		if idSeen[item.ID] {
-			return api.ToolResult{Status: "error", Error: fmt.Sprintf("duplicate item ID: %d", item.ID)}, nil
+	if err := planpkg.Validate(plan); err != nil {
+		return api.ToolResult{Status: "error", Error: err.Error()}, nil
+	}
+	if err := planpkg.CheckTransitions(prev, plan); err != nil {
+		return api.ToolResult{Status: "error", Error: err.Error()}, nil
+	}
+
+	// Link the tool calls made since the last plan update to items completed now.
+	if evidence := stringList(args["_evidence"]); len(evidence) > 0 {
+		for i := range plan.Items {
+			it := &plan.Items[i]
+			if it.Status != api.PlanDone || len(it.Evidence) > 0 {
+				continue
+			}
+			if old := planpkg.Find(prev, it.ID); old != nil && old.Status == api.PlanDone {
+				continue
+			}
+			planpkg.AddEvidence(it, evidence...)
 		}
-		idSeen[item.ID] = true
 	}
 
 	// Save
@@ -382,6 +390,66 @@ func (t *WriteTodosTool) Execute(ctx context.Context, args api.Args) (api.ToolRe
 	return api.ToolResult{Content: string(content), Status: "success", Data: plan}, nil
 }
 
+// applyPlanFields copies the fields present in m onto it (used for items and patches).
+func applyPlanFields(it *api.PlanItem, m map[string]any) {
+	if id, ok := m["id"].(float64); ok && it.ID == 0 {
+		it.ID = int(id)
+	}
+	if text, ok := m["text"].(string); ok {
+		it.Text = text
+	}
+	if status, ok := m["status"].(string); ok && status != "" {
+		it.Status = api.PlanItemStatus(status)
+	}
+	if parent, ok := m["parent_id"].(float64); ok {
+		it.ParentID = int(parent)
+	}
+	if deps, ok := m["depends_on"].([]any); ok {
+		it.DependsOn = nil
+		for _, d := range deps {
+			if f, ok := d.(float64); ok {
+				it.DependsOn = append(it.DependsOn, int(f))
+			}
+		}
+	}
+	if notes, ok := m["notes"].(string); ok {
+		it.Notes = notes
+	}
+	if ev := stringList(m["evidence"]); len(ev) > 0 {
+		planpkg.AddEvidence(it, ev...)
+	}
+}
+
+func clonePlan(p *api.PlanPayload) *api.PlanPayload {
+	if p == nil {
+		return nil
+	}
+	out := *p
+	out.Items = make([]api.PlanItem, len(p.Items))
+	for i, it := range p.Items {
+		it.DependsOn = append([]int(nil), it.DependsOn...)
+		it.Evidence = append([]string(nil), it.Evidence...)
+		out.Items[i] = it
+	}
+	return &out
+}
+
+func stringList(v any) []string {
+	switch vv := v.(type) {
+	case []string:
+		return vv
+	case []any:
+		out := make([]string, 0, len(vv))
+		for _, x := range vv {
+			if s, ok := x.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 // Memory Tools
 // ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
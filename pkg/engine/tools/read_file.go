@@ -21,7 +21,7 @@ func NewReadFileTool(workspaceRoot string) *ReadFileTool {
 	return &ReadFileTool{
 		BaseTool: NewBaseTool(
 			"read_file",
-			"Read the contents of a file. Returns the file content as text. For large files, content may be truncated.",
+			"Read the contents of a file. Returns the file content as text; PNG, JPEG, GIF and WebP images are returned as images. For large files, content may be truncated.",
 			[]ParameterDef{
 				{Name: "path", Type: "string", Description: "Path to the file to read (relative to workspace)", Required: true},
 				{Name: "start_line", Type: "integer", Description: "Start line number (1-indexed, optional)", Required: false},
@@ -34,6 +34,9 @@ func NewReadFileTool(workspaceRoot string) *ReadFileTool {
 	}
 }
 
+// maxImageBytes limits images returned to the model.
+const maxImageBytes = 5 * 1024 * 1024
+
 func (t *ReadFileTool) Execute(ctx context.Context, args api.Args) (api.ToolResult, error) {
 	path := GetStringArg(args, "path", "")
 	if path == "" {
@@ -62,6 +65,10 @@ func (t *ReadFileTool) Execute(ctx context.Context, args api.Args) (api.ToolResu
 		return toolErrorf("path is a directory, not a file: %s", path), nil
 	}
 
+	if mimeType := api.ImageMIMEType(absPath); mimeType != "" {
+		return t.readImage(absPath, path, mimeType, info.Size()), nil
+	}
+
 	// Check file size
 	if info.Size() > t.maxBytes && startLine == 0 && endLine == 0 {
 		return toolErrorf("file is too large (%s). Use start_line and end_line to read specific portions.",
@@ -112,3 +119,17 @@ func (t *ReadFileTool) Execute(ctx context.Context, args api.Args) (api.ToolResu
 
 	return successText(contentStr), nil
 }
+
+// readImage returns an image as an image part, with a short text description.
+func (t *ReadFileTool) readImage(absPath, path, mimeType string, size int64) api.ToolResult {
+	if size > maxImageBytes {
+		return toolErrorf("image is too large (%s, limit %s)", formatSize(size), formatSize(maxImageBytes))
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return toolError(err)
+	}
+	res := successText(fmt.Sprintf("Image: %s (%s, %s)", path, mimeType, formatSize(size)))
+	res.Parts = []api.ContentPart{api.ImagePart(mimeType, data, path)}
+	return res
+}
@@ -162,6 +162,36 @@ func (t *RunSkillScriptTool) Execute(ctx context.Context, args api.Args) (api.To
 
 	// Get timeout
 	timeoutSecs := GetIntArg(args, "timeout_sec", 60)
+
+	return t.runScript(ctx, meta, scriptPath, scriptArgs, timeoutSecs, nil), nil
+}
+
+// RunHook executes a skill lifecycle hook script from the named skill's scripts/ directory.
+// The script path goes through the same validation as run_skill_script.
+func (t *RunSkillScriptTool) RunHook(ctx context.Context, skillName string, hook api.SkillHook, env map[string]string) (api.ToolResult, error) {
+	meta, ok := t.skillIndex.Get(skillName)
+	if !ok {
+		return api.ToolResult{}, fmt.Errorf("skill not found: %s", skillName)
+	}
+
+	scriptPath, err := t.ValidateScriptPath(meta.Path, hook.Script)
+	if err != nil {
+		return api.ToolResult{}, fmt.Errorf("script validation failed: %w", err)
+	}
+
+	timeoutSecs := hook.TimeoutSec
+	if timeoutSecs <= 0 {
+		timeoutSecs = 60
+	}
+
+	var extraEnv []string
+	for k, v := range env {
+		extraEnv = append(extraEnv, k+"="+v)
+	}
+	return t.runScript(ctx, meta, scriptPath, hook.Args, timeoutSecs, extraEnv), nil
+}
+
+func (t *RunSkillScriptTool) runScript(ctx context.Context, meta api.SkillMeta, scriptPath string, scriptArgs []string, timeoutSecs int, extraEnv []string) api.ToolResult {
 	if timeoutSecs > 300 {
 		timeoutSecs = 300
 	}
@@ -191,13 +221,14 @@ func (t *RunSkillScriptTool) Execute(ctx context.Context, args api.Args) (api.To
 		"SKILL_PATH="+meta.Path,
 		"SKILL_NAME="+meta.Name,
 	)
+	cmd.Env = append(cmd.Env, extraEnv...)
 
 	// Capture output
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err = cmd.Run()
+	err := cmd.Run()
 
 	// Build output
 	var output strings.Builder
@@ -226,7 +257,7 @@ func (t *RunSkillScriptTool) Execute(ctx context.Context, args api.Args) (api.To
 			Status:  "error",
 			Error:   "timeout",
 			Data:    map[string]any{"exit_code": -1},
-		}, nil
+		}
 	}
 
 	if err != nil {
@@ -239,14 +270,14 @@ func (t *RunSkillScriptTool) Execute(ctx context.Context, args api.Args) (api.To
 			Status:  "error",
 			Error:   fmt.Sprintf("exit code %d", exitCode),
 			Data:    map[string]any{"exit_code": exitCode},
-		}, nil
+		}
 	}
 
 	// Success
 	if output.Len() == 0 {
-		return successText("<script completed with no output>"), nil
+		return successText("<script completed with no output>")
 	}
-	return successText(output.String()), nil
+	return successText(output.String())
 }
 
 func (t *RunSkillScriptTool) Preview(ctx context.Context, args api.Args) (*api.Preview, error) {
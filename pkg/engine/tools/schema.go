@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+// ValidateArgs checks args against a tool schema's JSON-schema parameters: required
+// arguments are present, and declared ones have the declared type. Undeclared arguments
+// are refused only when the schema sets additionalProperties to false, as in JSON
+// Schema. Schemas without declared properties accept any arguments.
+func ValidateArgs(schema api.ToolSchema, args api.Args) error {
+	params, _ := schema.Parameters.(map[string]any)
+	properties, _ := params["properties"].(map[string]any)
+	if properties == nil {
+		return nil
+	}
+
+	var problems []string
+	for _, name := range requiredArgs(params["required"]) {
+		if _, ok := args[name]; !ok {
+			problems = append(problems, fmt.Sprintf("missing required argument %q", name))
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	closed := params["additionalProperties"] == false
+	for _, name := range names {
+		prop, ok := properties[name].(map[string]any)
+		if !ok {
+			if closed {
+				problems = append(problems, fmt.Sprintf("unknown argument %q", name))
+			}
+			continue
+		}
+		want, _ := prop["type"].(string)
+		if want != "" && !hasJSONType(args[name], want) {
+			problems = append(problems, fmt.Sprintf("argument %q must be %s, got %s", name, want, jsonTypeName(args[name])))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func requiredArgs(v any) []string {
+	switch req := v.(type) {
+	case []string:
+		return req
+	case []any:
+		names := make([]string, 0, len(req))
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// hasJSONType reports whether v, as decoded from JSON or YAML, has the JSON-schema type.
+func hasJSONType(v any, want string) bool {
+	switch want {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "integer":
+		switch n := v.(type) {
+		case int, int64:
+			return true
+		case float64:
+			return n == math.Trunc(n)
+		}
+		return false
+	case "number":
+		switch v.(type) {
+		case int, int64, float64:
+			return true
+		}
+		return false
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return true
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"AgentEngine/pkg/engine/api"
+)
+
+func TestValidateArgs_ChecksRequiredTypesAndUnknownArgs(t *testing.T) {
+	schema := NewShellTool(t.TempDir()).Schema()
+
+	if err := ValidateArgs(schema, api.Args{"command": "ls", "timeout": float64(30)}); err != nil {
+		t.Fatalf("expected valid args, got %v", err)
+	}
+	// YAML decodes integers as int.
+	if err := ValidateArgs(schema, api.Args{"command": "ls", "timeout": 30}); err != nil {
+		t.Fatalf("expected an int accepted as integer, got %v", err)
+	}
+
+	// Undeclared arguments are allowed unless the schema says otherwise.
+	if err := ValidateArgs(schema, api.Args{"command": "ls", "reason": "list files"}); err != nil {
+		t.Fatalf("expected an extra argument accepted, got %v", err)
+	}
+	params := map[string]any{}
+	for k, v := range schema.Parameters.(map[string]any) {
+		params[k] = v
+	}
+	params["additionalProperties"] = false
+	schema.Parameters = params
+
+	err := ValidateArgs(schema, api.Args{"timeout": 1.5, "comand": "ls"})
+	if err == nil {
+		t.Fatalf("expected invalid args refused")
+	}
+	for _, want := range []string{`missing required argument "command"`, `unknown argument "comand"`, `"timeout" must be integer`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+
+	if err := ValidateArgs(api.ToolSchema{Name: "free"}, api.Args{"anything": 1}); err != nil {
+		t.Fatalf("expected a schema without properties to accept any args, got %v", err)
+	}
+}